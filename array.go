@@ -2,6 +2,7 @@ package dbmsgo
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -56,10 +57,48 @@ func (a *Array) Replace(index int, value string) error {
 	return nil
 }
 
+// IncrBy прибавляет delta к целому элементу и возвращает новое значение
+func (a *Array) IncrBy(index int, delta int64) (int64, error) {
+	if index < 0 || index >= len(a.data) {
+		return 0, fmt.Errorf("index out of range")
+	}
+	current, err := parseInteger(a.data[index])
+	if err != nil {
+		return 0, err
+	}
+	result, err := addInteger(current, delta)
+	if err != nil {
+		return 0, err
+	}
+	a.data[index] = strconv.FormatInt(result, 10)
+	return result, nil
+}
+
+// CompareAndSwap заменяет элемент на value, только если он равен expected
+func (a *Array) CompareAndSwap(index int, expected, value string) (bool, error) {
+	if index < 0 || index >= len(a.data) {
+		return false, fmt.Errorf("index out of range")
+	}
+	if a.data[index] != expected {
+		return false, nil
+	}
+	a.data[index] = value
+	return true, nil
+}
+
 func (a *Array) Length() int {
 	return len(a.data)
 }
 
+// Range возвращает копию элементов с start по stop включительно;
+// отрицательные индексы отсчитываются от конца
+func (a *Array) Range(start, stop int) []string {
+	from, to := normalizeRange(start, stop, len(a.data))
+	result := make([]string, to-from)
+	copy(result, a.data[from:to])
+	return result
+}
+
 func (a *Array) IsEmpty() bool {
 	return len(a.data) == 0
 }
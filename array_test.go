@@ -111,4 +111,68 @@ func TestArray(t *testing.T) {
 		_, err := arr.Get(0)
 		assert.Error(t, err)
 	})
+
+	t.Run("IncrBy", func(t *testing.T) {
+		arr := NewArray("test_array")
+		arr.PushBack("10")
+		arr.PushBack("abc")
+		arr.PushBack("9223372036854775807")
+
+		value, err := arr.IncrBy(0, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(15), value)
+		value, err = arr.IncrBy(0, -20)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(-5), value)
+		stored, _ := arr.Get(0)
+		assert.Equal(t, "-5", stored)
+
+		_, err = arr.IncrBy(1, 1)
+		assert.ErrorContains(t, err, "not an integer")
+		_, err = arr.IncrBy(2, 1)
+		assert.ErrorContains(t, err, "overflow")
+		_, err = arr.IncrBy(3, 1)
+		assert.Error(t, err)
+		stored, _ = arr.Get(2)
+		assert.Equal(t, "9223372036854775807", stored)
+	})
+
+	t.Run("CompareAndSwap", func(t *testing.T) {
+		arr := NewArray("test_array")
+		arr.PushBack("old")
+
+		swapped, err := arr.CompareAndSwap(0, "other", "new")
+		assert.NoError(t, err)
+		assert.False(t, swapped)
+		swapped, err = arr.CompareAndSwap(0, "old", "new")
+		assert.NoError(t, err)
+		assert.True(t, swapped)
+		stored, _ := arr.Get(0)
+		assert.Equal(t, "new", stored)
+
+		_, err = arr.CompareAndSwap(1, "new", "x")
+		assert.Error(t, err)
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		arr := NewArray("test_array")
+		for _, value := range []string{"a", "b", "c", "d", "e"} {
+			arr.PushBack(value)
+		}
+
+		assert.Equal(t, []string{"b", "c", "d"}, arr.Range(1, 3))
+		assert.Equal(t, []string{"a", "b", "c", "d", "e"}, arr.Range(0, -1))
+		assert.Equal(t, []string{"d", "e"}, arr.Range(-2, -1))
+		assert.Equal(t, []string{"a", "b"}, arr.Range(-100, 1))
+		assert.Equal(t, []string{"e"}, arr.Range(4, 100))
+		assert.Empty(t, arr.Range(3, 1))
+		assert.Empty(t, arr.Range(5, 10))
+		assert.Empty(t, NewArray("empty").Range(0, -1))
+
+		// Результат - копия, а не срез внутреннего массива
+		values := arr.Range(0, 0)
+		values[0] = "changed"
+		value, _ := arr.Get(0)
+		assert.Equal(t, "a", value)
+	})
 }
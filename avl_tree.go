@@ -2,25 +2,286 @@ package dbmsgo
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"sort"
+	"strings"
+	"sync/atomic"
 )
 
+// TreeKeyMode определяет тип ключей AVL-дерева.
+type TreeKeyMode int
+
+const (
+	// INT_KEYS - обычные целые ключи (int64)
+	INT_KEYS TreeKeyMode = iota
+	// BIGINT_KEYS - целые ключи произвольной длины на основе math/big
+	BIGINT_KEYS
+	// STRING_KEYS - упорядоченный словарь: строковый ключ и значение,
+	// порядок задаётся компаратором
+	STRING_KEYS
+)
+
+// Comparator сравнивает два ключа: <0, 0 или >0
+type Comparator func(a, b string) int
+
+// TreeOrdering - встроенные порядки для деревьев со STRING_KEYS
+type TreeOrdering int
+
+const (
+	LEX_ORDER TreeOrdering = iota
+	NUMERIC_ORDER
+	// CUSTOM_ORDER - компаратор передан в NewOrderedMapWithComparator
+	CUSTOM_ORDER
+)
+
+func (m TreeKeyMode) String() string {
+	switch m {
+	case BIGINT_KEYS:
+		return "BIGINT"
+	case STRING_KEYS:
+		return "STRING"
+	}
+	return "INT"
+}
+
+func ParseTreeKeyMode(text string) (TreeKeyMode, error) {
+	switch text {
+	case "INT":
+		return INT_KEYS, nil
+	case "BIGINT":
+		return BIGINT_KEYS, nil
+	case "STRING":
+		return STRING_KEYS, nil
+	}
+	return INT_KEYS, fmt.Errorf("unknown key mode '%s'", text)
+}
+
+func (o TreeOrdering) String() string {
+	switch o {
+	case NUMERIC_ORDER:
+		return "NUM"
+	case CUSTOM_ORDER:
+		return "CUSTOM"
+	}
+	return "LEX"
+}
+
+func ParseTreeOrdering(text string) (TreeOrdering, error) {
+	switch text {
+	case "LEX":
+		return LEX_ORDER, nil
+	case "NUM":
+		return NUMERIC_ORDER, nil
+	}
+	return LEX_ORDER, fmt.Errorf("unknown ordering '%s'", text)
+}
+
+// LexicographicOrder сравнивает ключи побайтово
+func LexicographicOrder(a, b string) int {
+	return strings.Compare(a, b)
+}
+
+// NumericOrder сравнивает ключи как числа (целые, десятичные, 1e3, 1/3).
+// Нечисловые ключи сравниваются лексикографически после всех чисел.
+func NumericOrder(a, b string) int {
+	x, okX := new(big.Rat).SetString(a)
+	y, okY := new(big.Rat).SetString(b)
+	switch {
+	case okX && okY:
+		return x.Cmp(y)
+	case okX:
+		return -1
+	case okY:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// NumericKeyOrder - порядок ключей словаря с ORDER=NUM. Равные числа в
+// разной записи ("1" и "1.0") упорядочиваются лексикографически, чтобы
+// оставаться разными ключами и не перезаписывать друг друга.
+func NumericKeyOrder(a, b string) int {
+	if result := NumericOrder(a, b); result != 0 {
+		return result
+	}
+	return strings.Compare(a, b)
+}
+
+func comparatorFor(ordering TreeOrdering) Comparator {
+	if ordering == NUMERIC_ORDER {
+		return NumericKeyOrder
+	}
+	return LexicographicOrder
+}
+
 type AVLNode struct {
-	Data   int
+	Data   int64
+	Big    *big.Int
+	Key    string
+	Value  string
 	Left   *AVLNode
 	Right  *AVLNode
+	Count  int // кратность ключа, больше 1 только в мультимножестве
 	height int
+	size   int    // число ключей в поддереве с учётом кратности
+	gen    uint64 // поколение дерева, создавшего узел
 }
 
 type AVLTree struct {
-	name string
-	root *AVLNode
+	name       string
+	root       *AVLNode
+	mode       TreeKeyMode
+	ordering   TreeOrdering
+	comparator Comparator
+	multiset   bool
+	gen        uint64
+	snapshots  map[string]*AVLTree
+}
+
+// treeGeneration раздаёт поколения деревьям и их версиям. Узел чужого
+// поколения может быть общим с другой версией и перед изменением копируется.
+var treeGeneration uint64
+
+func nextGeneration() uint64 {
+	return atomic.AddUint64(&treeGeneration, 1)
 }
 
 func NewAVLTree(name string) *AVLTree {
+	return NewAVLTreeWithMode(name, INT_KEYS)
+}
+
+func NewAVLTreeWithMode(name string, mode TreeKeyMode) *AVLTree {
 	return &AVLTree{
-		name: name,
-		root: nil,
+		name:       name,
+		root:       nil,
+		mode:       mode,
+		ordering:   LEX_ORDER,
+		comparator: LexicographicOrder,
+		gen:        nextGeneration(),
+	}
+}
+
+// NewMultisetTree создаёт мультимножество: повторные вставки ключа
+// увеличивают его кратность вместо того, чтобы игнорироваться.
+func NewMultisetTree(name string, mode TreeKeyMode) *AVLTree {
+	tree := NewAVLTreeWithMode(name, mode)
+	tree.multiset = true
+	return tree
+}
+
+// NewOrderedMap создаёт дерево со строковыми ключами и значениями
+func NewOrderedMap(name string, ordering TreeOrdering) *AVLTree {
+	tree := NewAVLTreeWithMode(name, STRING_KEYS)
+	tree.ordering = ordering
+	tree.comparator = comparatorFor(ordering)
+	return tree
+}
+
+// NewOrderedMapWithComparator создаёт словарь с порядком, заданным
+// компаратором compare. Компаратор не сохраняется в файл: при загрузке
+// такой словарь восстанавливается с лексикографическим порядком.
+func NewOrderedMapWithComparator(name string, compare Comparator) *AVLTree {
+	tree := NewAVLTreeWithMode(name, STRING_KEYS)
+	tree.ordering = CUSTOM_ORDER
+	tree.comparator = compare
+	return tree
+}
+
+// newNode создаёт узел с ключом value в представлении, подходящем для дерева
+func (a *AVLTree) newNode(value int64) *AVLNode {
+	if a.mode == BIGINT_KEYS {
+		return &AVLNode{Big: big.NewInt(value), height: 1, size: 1, Count: 1}
+	}
+	return &AVLNode{Data: value, height: 1, size: 1, Count: 1}
+}
+
+func (a *AVLTree) newBigNode(value *big.Int) (*AVLNode, error) {
+	if value == nil {
+		return nil, fmt.Errorf("value is nil")
+	}
+	if a.mode == BIGINT_KEYS {
+		return &AVLNode{Big: new(big.Int).Set(value), height: 1, size: 1, Count: 1}, nil
+	}
+	if !value.IsInt64() {
+		return nil, fmt.Errorf("value %s is out of int64 range", value.String())
+	}
+	return &AVLNode{Data: value.Int64(), height: 1, size: 1, Count: 1}, nil
+}
+
+// compare сравнивает ключи двух узлов: <0, 0 или >0
+func (a *AVLTree) compare(x, y *AVLNode) int {
+	switch a.mode {
+	case BIGINT_KEYS:
+		return x.Big.Cmp(y.Big)
+	case STRING_KEYS:
+		return a.comparator(x.Key, y.Key)
+	}
+	if x.Data < y.Data {
+		return -1
+	}
+	if x.Data > y.Data {
+		return 1
 	}
+	return 0
+}
+
+// copyKey переносит ключ узла src в узел dst
+func (a *AVLTree) copyKey(dst, src *AVLNode) {
+	dst.Data = src.Data
+	dst.Big = src.Big
+	dst.Key = src.Key
+	dst.Value = src.Value
+	dst.Count = src.Count
+}
+
+// KeyString возвращает ключ узла в десятичной записи
+func (a *AVLTree) KeyString(node *AVLNode) string {
+	switch a.mode {
+	case BIGINT_KEYS:
+		return node.Big.String()
+	case STRING_KEYS:
+		return node.Key
+	}
+	return strconv.FormatInt(node.Data, 10)
+}
+
+// ParseKey разбирает ключ из строки в соответствии с режимом дерева
+func (a *AVLTree) ParseKey(text string) (*AVLNode, error) {
+	if a.mode == STRING_KEYS {
+		if a.ordering == NUMERIC_ORDER {
+			if _, ok := new(big.Rat).SetString(text); !ok {
+				return nil, fmt.Errorf("invalid number '%s'", text)
+			}
+		}
+		return &AVLNode{Key: text, height: 1, size: 1, Count: 1}, nil
+	}
+	if a.mode == BIGINT_KEYS {
+		value, ok := new(big.Int).SetString(text, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer '%s'", text)
+		}
+		return &AVLNode{Big: value, height: 1, size: 1, Count: 1}, nil
+	}
+	value, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &AVLNode{Data: value, height: 1, size: 1, Count: 1}, nil
+}
+
+// own возвращает узел, который можно менять на месте: собственный узел
+// дерева или копию общего узла. Так изменения копируют только путь от
+// корня, а остальные узлы остаются общими со снимками.
+func (a *AVLTree) own(node *AVLNode) *AVLNode {
+	if node == nil || node.gen == a.gen {
+		return node
+	}
+	clone := *node
+	clone.gen = a.gen
+	return &clone
 }
 
 func (a *AVLTree) height(node *AVLNode) int {
@@ -30,6 +291,19 @@ func (a *AVLTree) height(node *AVLNode) int {
 	return node.height
 }
 
+func (a *AVLTree) size(node *AVLNode) int {
+	if node == nil {
+		return 0
+	}
+	return node.size
+}
+
+// update пересчитывает высоту и размер поддерева по детям
+func (a *AVLTree) update(node *AVLNode) {
+	node.height = 1 + max(a.height(node.Left), a.height(node.Right))
+	node.size = node.Count + a.size(node.Left) + a.size(node.Right)
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -45,66 +319,79 @@ func (a *AVLTree) balanceFactor(node *AVLNode) int {
 }
 
 func (a *AVLTree) rotateRight(y *AVLNode) *AVLNode {
-	x := y.Left
+	y = a.own(y)
+	x := a.own(y.Left)
 	T2 := x.Right
 
 	x.Right = y
 	y.Left = T2
 
-	y.height = max(a.height(y.Left), a.height(y.Right)) + 1
-	x.height = max(a.height(x.Left), a.height(x.Right)) + 1
+	a.update(y)
+	a.update(x)
 
 	return x
 }
 
 func (a *AVLTree) rotateLeft(x *AVLNode) *AVLNode {
-	y := x.Right
+	x = a.own(x)
+	y := a.own(x.Right)
 	T2 := y.Left
 
 	y.Left = x
 	x.Right = T2
 
-	x.height = max(a.height(x.Left), a.height(x.Right)) + 1
-	y.height = max(a.height(y.Left), a.height(y.Right)) + 1
+	a.update(x)
+	a.update(y)
 
 	return y
 }
 
-func (a *AVLTree) insertHelper(node *AVLNode, value int) *AVLNode {
+func (a *AVLTree) insertHelper(node *AVLNode, probe *AVLNode) *AVLNode {
 	if node == nil {
-		return &AVLNode{Data: value, height: 1}
+		probe.gen = a.gen
+		a.update(probe)
+		return probe
 	}
+	node = a.own(node)
 
-	if value < node.Data {
-		node.Left = a.insertHelper(node.Left, value)
-	} else if value > node.Data {
-		node.Right = a.insertHelper(node.Right, value)
+	cmp := a.compare(probe, node)
+	if cmp < 0 {
+		node.Left = a.insertHelper(node.Left, probe)
+	} else if cmp > 0 {
+		node.Right = a.insertHelper(node.Right, probe)
 	} else {
-		return node // Дубликаты не разрешены
+		if a.multiset {
+			node.Count += probe.Count
+			a.update(node)
+			return node
+		}
+		// Дубликаты не разрешены, у словаря обновляется значение
+		node.Value = probe.Value
+		return node
 	}
 
-	node.height = 1 + max(a.height(node.Left), a.height(node.Right))
+	a.update(node)
 
 	balance := a.balanceFactor(node)
 
 	// Left Left Case
-	if balance > 1 && value < node.Left.Data {
+	if balance > 1 && a.compare(probe, node.Left) < 0 {
 		return a.rotateRight(node)
 	}
 
 	// Right Right Case
-	if balance < -1 && value > node.Right.Data {
+	if balance < -1 && a.compare(probe, node.Right) > 0 {
 		return a.rotateLeft(node)
 	}
 
 	// Left Right Case
-	if balance > 1 && value > node.Left.Data {
+	if balance > 1 && a.compare(probe, node.Left) > 0 {
 		node.Left = a.rotateLeft(node.Left)
 		return a.rotateRight(node)
 	}
 
 	// Right Left Case
-	if balance < -1 && value < node.Right.Data {
+	if balance < -1 && a.compare(probe, node.Right) < 0 {
 		node.Right = a.rotateRight(node.Right)
 		return a.rotateLeft(node)
 	}
@@ -112,8 +399,19 @@ func (a *AVLTree) insertHelper(node *AVLNode, value int) *AVLNode {
 	return node
 }
 
-func (a *AVLTree) Insert(value int) {
-	a.root = a.insertHelper(a.root, value)
+func (a *AVLTree) Insert(value int64) {
+	a.root = a.insertHelper(a.root, a.newNode(value))
+}
+
+// InsertBig вставляет ключ произвольной длины. Для дерева с INT_KEYS
+// ключ должен помещаться в int64.
+func (a *AVLTree) InsertBig(value *big.Int) error {
+	probe, err := a.newBigNode(value)
+	if err != nil {
+		return err
+	}
+	a.root = a.insertHelper(a.root, probe)
+	return nil
 }
 
 func (a *AVLTree) minValueNode(node *AVLNode) *AVLNode {
@@ -124,15 +422,23 @@ func (a *AVLTree) minValueNode(node *AVLNode) *AVLNode {
 	return current
 }
 
-func (a *AVLTree) deleteHelper(node *AVLNode, value int) *AVLNode {
+// deleteHelper удаляет ключ. В мультимножестве без all уменьшается
+// кратность, а узел удаляется только вместе с последним вхождением.
+func (a *AVLTree) deleteHelper(node *AVLNode, probe *AVLNode, all bool) *AVLNode {
 	if node == nil {
 		return node
 	}
+	node = a.own(node)
 
-	if value < node.Data {
-		node.Left = a.deleteHelper(node.Left, value)
-	} else if value > node.Data {
-		node.Right = a.deleteHelper(node.Right, value)
+	cmp := a.compare(probe, node)
+	if cmp < 0 {
+		node.Left = a.deleteHelper(node.Left, probe, all)
+	} else if cmp > 0 {
+		node.Right = a.deleteHelper(node.Right, probe, all)
+	} else if !all && node.Count > 1 {
+		node.Count--
+		a.update(node)
+		return node
 	} else {
 		if node.Left == nil || node.Right == nil {
 			var temp *AVLNode
@@ -145,12 +451,12 @@ func (a *AVLTree) deleteHelper(node *AVLNode, value int) *AVLNode {
 			if temp == nil {
 				return nil
 			} else {
-				node = temp
+				node = a.own(temp)
 			}
 		} else {
 			temp := a.minValueNode(node.Right)
-			node.Data = temp.Data
-			node.Right = a.deleteHelper(node.Right, temp.Data)
+			a.copyKey(node, temp)
+			node.Right = a.deleteHelper(node.Right, temp, true)
 		}
 	}
 
@@ -158,7 +464,7 @@ func (a *AVLTree) deleteHelper(node *AVLNode, value int) *AVLNode {
 		return node
 	}
 
-	node.height = 1 + max(a.height(node.Left), a.height(node.Right))
+	a.update(node)
 	balance := a.balanceFactor(node)
 
 	// Left Left Case
@@ -186,29 +492,430 @@ func (a *AVLTree) deleteHelper(node *AVLNode, value int) *AVLNode {
 	return node
 }
 
-func (a *AVLTree) Remove(value int) {
-	a.root = a.deleteHelper(a.root, value)
+func (a *AVLTree) Remove(value int64) {
+	a.root = a.deleteHelper(a.root, a.newNode(value), false)
+}
+
+// RemoveAll удаляет ключ вместе со всеми вхождениями
+func (a *AVLTree) RemoveAll(value int64) {
+	a.root = a.deleteHelper(a.root, a.newNode(value), true)
+}
+
+func (a *AVLTree) RemoveBig(value *big.Int) error {
+	probe, err := a.newBigNode(value)
+	if err != nil {
+		return err
+	}
+	a.root = a.deleteHelper(a.root, probe, false)
+	return nil
 }
 
-func (a *AVLTree) searchHelper(node *AVLNode, value int) *AVLNode {
-	if node == nil || node.Data == value {
+func (a *AVLTree) searchHelper(node *AVLNode, probe *AVLNode) *AVLNode {
+	if node == nil {
 		return node
 	}
 
-	if value < node.Data {
-		return a.searchHelper(node.Left, value)
+	cmp := a.compare(probe, node)
+	if cmp == 0 {
+		return node
+	}
+	if cmp < 0 {
+		return a.searchHelper(node.Left, probe)
+	}
+	return a.searchHelper(node.Right, probe)
+}
+
+func (a *AVLTree) Search(value int64) *AVLNode {
+	return a.searchHelper(a.root, a.newNode(value))
+}
+
+// Contains проверяет наличие ключа, как того требует OrderedSet
+func (a *AVLTree) Contains(value int64) bool {
+	return a.Search(value) != nil
+}
+
+func (a *AVLTree) SearchBig(value *big.Int) *AVLNode {
+	probe, err := a.newBigNode(value)
+	if err != nil {
+		return nil
+	}
+	return a.searchHelper(a.root, probe)
+}
+
+// InsertKey, RemoveKey и SearchKey принимают ключ в текстовом виде
+// и разбирают его согласно режиму дерева.
+func (a *AVLTree) InsertKey(text string) error {
+	probe, err := a.ParseKey(text)
+	if err != nil {
+		return err
+	}
+	a.insertNode(probe)
+	return nil
+}
+
+// insertNode вставляет ключ, уже разобранный через ParseKey
+func (a *AVLTree) insertNode(probe *AVLNode) {
+	a.root = a.insertHelper(a.root, probe)
+}
+
+func (a *AVLTree) RemoveKey(text string) error {
+	probe, err := a.ParseKey(text)
+	if err != nil {
+		return err
+	}
+	a.root = a.deleteHelper(a.root, probe, false)
+	return nil
+}
+
+func (a *AVLTree) RemoveAllKey(text string) error {
+	probe, err := a.ParseKey(text)
+	if err != nil {
+		return err
+	}
+	a.root = a.deleteHelper(a.root, probe, true)
+	return nil
+}
+
+// CountOf возвращает кратность ключа (0, если его нет)
+func (a *AVLTree) CountOf(value int64) int {
+	node := a.Search(value)
+	if node == nil {
+		return 0
+	}
+	return node.Count
+}
+
+func (a *AVLTree) CountOfKey(text string) (int, error) {
+	node, err := a.SearchKey(text)
+	if err != nil {
+		return 0, err
+	}
+	if node == nil {
+		return 0, nil
+	}
+	return node.Count, nil
+}
+
+func (a *AVLTree) SearchKey(text string) (*AVLNode, error) {
+	probe, err := a.ParseKey(text)
+	if err != nil {
+		return nil, err
+	}
+	return a.searchNode(probe), nil
+}
+
+// searchNode ищет ключ, уже разобранный через ParseKey
+func (a *AVLTree) searchNode(probe *AVLNode) *AVLNode {
+	return a.searchHelper(a.root, probe)
+}
+
+// Put вставляет или обновляет пару ключ-значение
+func (a *AVLTree) Put(key, value string) error {
+	probe, err := a.ParseKey(key)
+	if err != nil {
+		return err
+	}
+	probe.Value = value
+	a.root = a.insertHelper(a.root, probe)
+	return nil
+}
+
+// Get возвращает значение по ключу
+func (a *AVLTree) Get(key string) (string, bool) {
+	node, err := a.SearchKey(key)
+	if err != nil || node == nil {
+		return "", false
+	}
+	return node.Value, true
+}
+
+// Min и Max возвращают узлы с наименьшим и наибольшим ключом
+func (a *AVLTree) Min() *AVLNode {
+	if a.root == nil {
+		return nil
+	}
+	return a.minValueNode(a.root)
+}
+
+func (a *AVLTree) Max() *AVLNode {
+	current := a.root
+	for current != nil && current.Right != nil {
+		current = current.Right
+	}
+	return current
+}
+
+// boundNode ищет ближайший к probe ключ за O(log n). below выбирает
+// направление (ключи меньше probe или больше), inclusive - допускается ли
+// сам probe.
+func (a *AVLTree) boundNode(probe *AVLNode, below, inclusive bool) *AVLNode {
+	var result *AVLNode
+	current := a.root
+	for current != nil {
+		cmp := a.compare(current, probe)
+		if cmp == 0 && inclusive {
+			return current
+		}
+		if below {
+			if cmp < 0 {
+				result = current
+				current = current.Right
+			} else {
+				current = current.Left
+			}
+		} else {
+			if cmp > 0 {
+				result = current
+				current = current.Left
+			} else {
+				current = current.Right
+			}
+		}
+	}
+	return result
+}
+
+func (a *AVLTree) boundKey(text string, below, inclusive bool) (*AVLNode, error) {
+	probe, err := a.ParseKey(text)
+	if err != nil {
+		return nil, err
+	}
+	return a.boundNode(probe, below, inclusive), nil
+}
+
+// Floor - наибольший ключ <= value, Ceil - наименьший ключ >= value,
+// Prev - наибольший ключ < value, Next - наименьший ключ > value.
+func (a *AVLTree) Floor(value int64) *AVLNode {
+	return a.boundNode(a.newNode(value), true, true)
+}
+
+func (a *AVLTree) Ceil(value int64) *AVLNode {
+	return a.boundNode(a.newNode(value), false, true)
+}
+
+func (a *AVLTree) Prev(value int64) *AVLNode {
+	return a.boundNode(a.newNode(value), true, false)
+}
+
+func (a *AVLTree) Next(value int64) *AVLNode {
+	return a.boundNode(a.newNode(value), false, false)
+}
+
+func (a *AVLTree) FloorKey(text string) (*AVLNode, error) {
+	return a.boundKey(text, true, true)
+}
+
+func (a *AVLTree) CeilKey(text string) (*AVLNode, error) {
+	return a.boundKey(text, false, true)
+}
+
+func (a *AVLTree) PrevKey(text string) (*AVLNode, error) {
+	return a.boundKey(text, true, false)
+}
+
+func (a *AVLTree) NextKey(text string) (*AVLNode, error) {
+	return a.boundKey(text, false, false)
+}
+
+// rangeNodes обходит in-order только поддеревья, пересекающие [lo, hi],
+// и останавливается после limit узлов (limit <= 0 - без ограничения).
+// Стоимость O(log n + k), где k - размер результата.
+func (a *AVLTree) rangeNodes(lo, hi *AVLNode, limit int) []*AVLNode {
+	result := make([]*AVLNode, 0)
+	stack := make([]*AVLNode, 0)
+	current := a.root
+
+	for current != nil || len(stack) > 0 {
+		for current != nil {
+			if a.compare(current, lo) < 0 {
+				current = current.Right
+				continue
+			}
+			stack = append(stack, current)
+			current = current.Left
+		}
+		if len(stack) == 0 {
+			break
+		}
+
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if a.compare(node, hi) > 0 {
+			break
+		}
+
+		result = append(result, node)
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		current = node.Right
+	}
+	return result
+}
+
+// Range возвращает узлы с ключами из [lo, hi] по возрастанию
+func (a *AVLTree) Range(lo, hi int64, limit int) []*AVLNode {
+	return a.rangeNodes(a.newNode(lo), a.newNode(hi), limit)
+}
+
+func (a *AVLTree) RangeKeys(lo, hi string, limit int) ([]*AVLNode, error) {
+	loProbe, err := a.ParseKey(lo)
+	if err != nil {
+		return nil, err
+	}
+	hiProbe, err := a.ParseKey(hi)
+	if err != nil {
+		return nil, err
+	}
+	return a.rangeNodes(loProbe, hiProbe, limit), nil
+}
+
+// TraversalOrder - порядок обхода дерева
+type TraversalOrder int
+
+const (
+	IN_ORDER TraversalOrder = iota
+	PRE_ORDER
+	POST_ORDER
+	LEVEL_ORDER
+)
+
+func (o TraversalOrder) String() string {
+	switch o {
+	case PRE_ORDER:
+		return "pre-order"
+	case POST_ORDER:
+		return "post-order"
+	case LEVEL_ORDER:
+		return "level-order"
+	}
+	return "in-order"
+}
+
+func ParseTraversalOrder(text string) (TraversalOrder, error) {
+	switch strings.ToUpper(text) {
+	case "IN", "INORDER":
+		return IN_ORDER, nil
+	case "PRE", "PREORDER":
+		return PRE_ORDER, nil
+	case "POST", "POSTORDER":
+		return POST_ORDER, nil
+	case "LEVEL", "LEVELORDER", "BFS":
+		return LEVEL_ORDER, nil
 	}
-	return a.searchHelper(node.Right, value)
+	return IN_ORDER, fmt.Errorf("unknown traversal order '%s'", text)
 }
 
-func (a *AVLTree) Search(value int) *AVLNode {
-	return a.searchHelper(a.root, value)
+func (a *AVLTree) traverseHelper(node *AVLNode, order TraversalOrder, result *[]*AVLNode) {
+	if node == nil {
+		return
+	}
+	if order == PRE_ORDER {
+		*result = append(*result, node)
+	}
+	a.traverseHelper(node.Left, order, result)
+	if order == IN_ORDER {
+		*result = append(*result, node)
+	}
+	a.traverseHelper(node.Right, order, result)
+	if order == POST_ORDER {
+		*result = append(*result, node)
+	}
+}
+
+// Traverse возвращает узлы дерева в заданном порядке обхода
+func (a *AVLTree) Traverse(order TraversalOrder) []*AVLNode {
+	result := make([]*AVLNode, 0, a.Size())
+	if order != LEVEL_ORDER {
+		a.traverseHelper(a.root, order, &result)
+		return result
+	}
+
+	if a.root == nil {
+		return result
+	}
+	result = append(result, a.root)
+	for i := 0; i < len(result); i++ {
+		if result[i].Left != nil {
+			result = append(result, result[i].Left)
+		}
+		if result[i].Right != nil {
+			result = append(result, result[i].Right)
+		}
+	}
+	return result
+}
+
+func (a *AVLTree) PrintTraversal(order TraversalOrder) {
+	fmt.Printf("Дерево '%s' %s: ", a.name, order)
+	for _, node := range a.Traverse(order) {
+		fmt.Printf("%s ", a.KeyString(node))
+	}
+	fmt.Println()
+}
+
+func (a *AVLTree) renderLabel(node *AVLNode) string {
+	label := a.KeyString(node)
+	if node.Count > 1 {
+		label += fmt.Sprintf(" x%d", node.Count)
+	}
+	if a.mode == STRING_KEYS {
+		label += ": " + node.Value
+	}
+	return fmt.Sprintf("%s [h=%d, bf=%d]", label, node.height, a.balanceFactor(node))
+}
+
+func (a *AVLTree) renderHelper(node *AVLNode, prefix, side string, last bool, out *strings.Builder) {
+	connector := "├── "
+	childPrefix := prefix + "│   "
+	if last {
+		connector = "└── "
+		childPrefix = prefix + "    "
+	}
+
+	if node == nil {
+		out.WriteString(prefix + connector + side + "∅\n")
+		return
+	}
+	out.WriteString(prefix + connector + side + a.renderLabel(node) + "\n")
+	if node.Left != nil || node.Right != nil {
+		a.renderHelper(node.Left, childPrefix, "L: ", false, out)
+		a.renderHelper(node.Right, childPrefix, "R: ", true, out)
+	}
+}
+
+// RenderTree рисует форму дерева с высотами (h) и балансами (bf) узлов.
+// Отсутствующий ребёнок узла с одним ребёнком показан как ∅.
+func (a *AVLTree) RenderTree() string {
+	var out strings.Builder
+	if a.root == nil {
+		out.WriteString("(пусто)\n")
+		return out.String()
+	}
+
+	out.WriteString(a.renderLabel(a.root) + "\n")
+	if a.root.Left != nil || a.root.Right != nil {
+		a.renderHelper(a.root.Left, "", "L: ", false, &out)
+		a.renderHelper(a.root.Right, "", "R: ", true, &out)
+	}
+	return out.String()
+}
+
+func (a *AVLTree) PrintTree() {
+	fmt.Printf("Дерево '%s':\n", a.name)
+	fmt.Print(a.RenderTree())
 }
 
 func (a *AVLTree) printInOrderHelper(node *AVLNode) {
 	if node != nil {
 		a.printInOrderHelper(node.Left)
-		fmt.Printf("%d ", node.Data)
+		if a.mode == STRING_KEYS {
+			fmt.Printf("{%s: %s} ", node.Key, node.Value)
+		} else {
+			for i := 0; i < node.Count; i++ {
+				fmt.Printf("%s ", a.KeyString(node))
+			}
+		}
 		a.printInOrderHelper(node.Right)
 	}
 }
@@ -219,31 +926,158 @@ func (a *AVLTree) PrintInOrder() {
 	fmt.Println()
 }
 
-func (a *AVLTree) countElementsHelper(node *AVLNode) int {
-	if node == nil {
-		return 0
+// CountElements возвращает число ключей за O(1) - размер хранится в корне
+func (a *AVLTree) CountElements() int {
+	return a.size(a.root)
+}
+
+func (a *AVLTree) Size() int {
+	return a.size(a.root)
+}
+
+// rankNode считает ключи меньше probe (или <= probe при inclusive) за O(log n)
+func (a *AVLTree) rankNode(probe *AVLNode, inclusive bool) int {
+	rank := 0
+	current := a.root
+	for current != nil {
+		cmp := a.compare(current, probe)
+		if cmp < 0 || (cmp == 0 && inclusive) {
+			rank += a.size(current.Left) + current.Count
+			current = current.Right
+		} else {
+			current = current.Left
+		}
 	}
-	return 1 + a.countElementsHelper(node.Left) + a.countElementsHelper(node.Right)
+	return rank
 }
 
-func (a *AVLTree) CountElements() int {
-	return a.countElementsHelper(a.root)
+// Rank возвращает количество ключей, строго меньших value
+func (a *AVLTree) Rank(value int64) int {
+	return a.rankNode(a.newNode(value), false)
+}
+
+func (a *AVLTree) RankKey(text string) (int, error) {
+	probe, err := a.ParseKey(text)
+	if err != nil {
+		return 0, err
+	}
+	return a.rankNode(probe, false), nil
+}
+
+// Select возвращает k-й по возрастанию ключ (с нуля, с учётом кратности) или nil
+func (a *AVLTree) Select(k int) *AVLNode {
+	if k < 0 || k >= a.size(a.root) {
+		return nil
+	}
+
+	current := a.root
+	for current != nil {
+		leftSize := a.size(current.Left)
+		if k < leftSize {
+			current = current.Left
+		} else if k < leftSize+current.Count {
+			return current
+		} else {
+			k -= leftSize + current.Count
+			current = current.Right
+		}
+	}
+	return nil
 }
 
-func (a *AVLTree) saveTreeHelper(node *AVLNode, result *[]int) {
+// CountRange возвращает количество ключей из [lo, hi]
+func (a *AVLTree) CountRange(lo, hi int64) int {
+	return a.countRangeNodes(a.newNode(lo), a.newNode(hi))
+}
+
+func (a *AVLTree) CountRangeKeys(lo, hi string) (int, error) {
+	loProbe, err := a.ParseKey(lo)
+	if err != nil {
+		return 0, err
+	}
+	hiProbe, err := a.ParseKey(hi)
+	if err != nil {
+		return 0, err
+	}
+	return a.countRangeNodes(loProbe, hiProbe), nil
+}
+
+func (a *AVLTree) countRangeNodes(lo, hi *AVLNode) int {
+	if a.compare(lo, hi) > 0 {
+		return 0
+	}
+	return a.rankNode(hi, true) - a.rankNode(lo, false)
+}
+
+func (a *AVLTree) saveTreeHelper(node *AVLNode, result *[]int64) {
 	if node != nil {
 		a.saveTreeHelper(node.Left, result)
-		*result = append(*result, node.Data)
+		for i := 0; i < node.Count; i++ {
+			*result = append(*result, node.Data)
+		}
 		a.saveTreeHelper(node.Right, result)
 	}
 }
 
-func (a *AVLTree) SaveTree() []int {
-	result := make([]int, 0)
+// SaveTree возвращает значения in-order, ключ мультимножества повторяется
+// столько раз, какова его кратность. Для BIGINT_KEYS используйте SaveKeys.
+func (a *AVLTree) SaveTree() []int64 {
+	result := make([]int64, 0)
 	a.saveTreeHelper(a.root, &result)
 	return result
 }
 
+func (a *AVLTree) saveKeysHelper(node *AVLNode, result *[]string) {
+	if node != nil {
+		a.saveKeysHelper(node.Left, result)
+		for i := 0; i < node.Count; i++ {
+			*result = append(*result, a.KeyString(node))
+		}
+		a.saveKeysHelper(node.Right, result)
+	}
+}
+
+// SaveKeys возвращает ключи in-order в десятичной записи для любого режима
+func (a *AVLTree) SaveKeys() []string {
+	result := make([]string, 0)
+	a.saveKeysHelper(a.root, &result)
+	return result
+}
+
+func (a *AVLTree) saveEntriesHelper(node *AVLNode, keys, values *[]string) {
+	if node != nil {
+		a.saveEntriesHelper(node.Left, keys, values)
+		*keys = append(*keys, a.KeyString(node))
+		*values = append(*values, node.Value)
+		a.saveEntriesHelper(node.Right, keys, values)
+	}
+}
+
+func (a *AVLTree) saveCountsHelper(node *AVLNode, keys *[]string, counts *[]int) {
+	if node != nil {
+		a.saveCountsHelper(node.Left, keys, counts)
+		*keys = append(*keys, a.KeyString(node))
+		*counts = append(*counts, node.Count)
+		a.saveCountsHelper(node.Right, keys, counts)
+	}
+}
+
+// SaveCounts возвращает различные ключи in-order и их кратности
+func (a *AVLTree) SaveCounts() ([]string, []int) {
+	keys := make([]string, 0)
+	counts := make([]int, 0)
+	a.saveCountsHelper(a.root, &keys, &counts)
+	return keys, counts
+}
+
+// SaveEntries возвращает ключи и значения in-order
+func (a *AVLTree) SaveEntries() ([]string, []string) {
+	keys := make([]string, 0)
+	values := make([]string, 0)
+	a.saveEntriesHelper(a.root, &keys, &values)
+	return keys, values
+}
+
 func (a *AVLTree) IsEmpty() bool {
 	return a.root == nil
 }
@@ -252,14 +1086,465 @@ func (a *AVLTree) GetName() string {
 	return a.name
 }
 
+func (a *AVLTree) GetEngine() TreeEngine {
+	return AVL_ENGINE
+}
+
+func (a *AVLTree) GetMode() TreeKeyMode {
+	return a.mode
+}
+
+func (a *AVLTree) GetOrdering() TreeOrdering {
+	return a.ordering
+}
+
+func (a *AVLTree) IsMultiset() bool {
+	return a.multiset
+}
+
 func (a *AVLTree) GetRoot() *AVLNode {
 	return a.root
 }
 
+// SetRoot заменяет содержимое дерева. Новые узлы могут быть общими с
+// другим деревом, поэтому дерево переходит в новое поколение.
 func (a *AVLTree) SetRoot(root *AVLNode) {
 	a.root = root
+	a.gen = nextGeneration()
 }
 
 func (a *AVLTree) Cleanup() {
 	a.root = nil
+	a.snapshots = nil
+}
+
+func (a *AVLTree) buildSortedHelper(nodes []*AVLNode, lo, hi int) *AVLNode {
+	if lo > hi {
+		return nil
+	}
+
+	mid := lo + (hi-lo)/2
+	node := nodes[mid]
+	node.gen = a.gen
+	node.Left = a.buildSortedHelper(nodes, lo, mid-1)
+	node.Right = a.buildSortedHelper(nodes, mid+1, hi)
+	a.update(node)
+	return node
+}
+
+func (a *AVLTree) buildFromSortedNodes(nodes []*AVLNode) error {
+	for i := 1; i < len(nodes); i++ {
+		if a.compare(nodes[i], nodes[i-1]) <= 0 {
+			return fmt.Errorf("values are not strictly ascending at position %d", i)
+		}
+	}
+
+	a.root = a.buildSortedHelper(nodes, 0, len(nodes)-1)
+	return nil
+}
+
+// BuildFromSorted заменяет содержимое дерева сбалансированным деревом,
+// построенным из строго возрастающей последовательности за O(n).
+func (a *AVLTree) BuildFromSorted(values []int64) error {
+	nodes := make([]*AVLNode, len(values))
+	for i, value := range values {
+		nodes[i] = a.newNode(value)
+	}
+	return a.buildFromSortedNodes(nodes)
+}
+
+// BuildFromSortedKeys - то же, что BuildFromSorted, для ключей в текстовом виде
+func (a *AVLTree) BuildFromSortedKeys(keys []string) error {
+	return a.BuildFromSortedEntries(keys, nil)
+}
+
+// BuildFromSortedCounts строит мультимножество из различных отсортированных
+// ключей и их кратностей за O(n).
+func (a *AVLTree) BuildFromSortedCounts(keys []string, counts []int) error {
+	if len(counts) != len(keys) {
+		return fmt.Errorf("keys and counts length mismatch")
+	}
+	if !a.multiset {
+		return fmt.Errorf("tree is not a multiset")
+	}
+
+	nodes := make([]*AVLNode, len(keys))
+	for i, key := range keys {
+		if counts[i] < 1 {
+			return fmt.Errorf("invalid count %d for key %s", counts[i], key)
+		}
+		node, err := a.ParseKey(key)
+		if err != nil {
+			return err
+		}
+		node.Count = counts[i]
+		nodes[i] = node
+	}
+	return a.buildFromSortedNodes(nodes)
+}
+
+// BuildFromSortedEntries строит словарь из отсортированных пар за O(n).
+// values может быть nil, если значения не нужны.
+func (a *AVLTree) BuildFromSortedEntries(keys, values []string) error {
+	if values != nil && len(values) != len(keys) {
+		return fmt.Errorf("keys and values length mismatch")
+	}
+
+	nodes := make([]*AVLNode, len(keys))
+	for i, key := range keys {
+		node, err := a.ParseKey(key)
+		if err != nil {
+			return err
+		}
+		if values != nil {
+			node.Value = values[i]
+		}
+		nodes[i] = node
+	}
+	return a.buildFromSortedNodes(nodes)
+}
+
+func (a *AVLTree) saveTreeShapeHelper(node *AVLNode, values *[]int64, heights *[]int) {
+	if node != nil {
+		*values = append(*values, node.Data)
+		*heights = append(*heights, node.height)
+		a.saveTreeShapeHelper(node.Left, values, heights)
+		a.saveTreeShapeHelper(node.Right, values, heights)
+	}
+}
+
+// SaveTreeShape возвращает прямой (pre-order) обход дерева вместе с высотами
+// узлов. Этого достаточно, чтобы восстановить точно такую же форму дерева.
+// Поддерживаются только множества с INT_KEYS.
+func (a *AVLTree) SaveTreeShape() ([]int64, []int) {
+	values := make([]int64, 0)
+	heights := make([]int, 0)
+	a.saveTreeShapeHelper(a.root, &values, &heights)
+	return values, heights
+}
+
+func (a *AVLTree) buildShapeHelper(values []int64, heights []int, pos *int, limit int, hasLo bool, lo int64, hasHi bool, hi int64) (*AVLNode, error) {
+	if *pos >= len(values) {
+		return nil, nil
+	}
+
+	value := values[*pos]
+	if (hasLo && value <= lo) || (hasHi && value >= hi) {
+		return nil, nil
+	}
+
+	expected := heights[*pos]
+	if expected < 1 || expected >= limit {
+		return nil, fmt.Errorf("invalid height %d at value %d", expected, value)
+	}
+
+	node := &AVLNode{Data: value, Count: 1, gen: a.gen}
+	*pos++
+
+	var err error
+	if node.Left, err = a.buildShapeHelper(values, heights, pos, expected, hasLo, lo, true, value); err != nil {
+		return nil, err
+	}
+	if node.Right, err = a.buildShapeHelper(values, heights, pos, expected, true, value, hasHi, hi); err != nil {
+		return nil, err
+	}
+
+	a.update(node)
+	if node.height != expected {
+		return nil, fmt.Errorf("height mismatch at value %d: stored %d, actual %d", value, expected, node.height)
+	}
+	if balance := a.balanceFactor(node); balance > 1 || balance < -1 {
+		return nil, fmt.Errorf("node %d is not balanced", value)
+	}
+	return node, nil
+}
+
+// BuildFromShape восстанавливает дерево из результата SaveTreeShape за O(n)
+// без вставок и поворотов. Сохранённые высоты сверяются с фактическими.
+func (a *AVLTree) BuildFromShape(values []int64, heights []int) error {
+	if len(values) != len(heights) {
+		return fmt.Errorf("values and heights length mismatch")
+	}
+
+	pos := 0
+	root, err := a.buildShapeHelper(values, heights, &pos, math.MaxInt, false, 0, false, 0)
+	if err != nil {
+		return err
+	}
+	if pos != len(values) {
+		return fmt.Errorf("values are not a valid pre-order sequence")
+	}
+
+	a.root = root
+	return nil
+}
+
+// compatibleWith проверяет, что деревья сравнивают ключи одинаково и
+// их можно объединять, пересекать и склеивать.
+func (a *AVLTree) compatibleWith(other *AVLTree) bool {
+	if a.mode != other.mode || a.multiset != other.multiset {
+		return false
+	}
+	if a.mode != STRING_KEYS {
+		return true
+	}
+	if a.ordering == CUSTOM_ORDER && other.ordering == CUSTOM_ORDER {
+		// Функции в Go не сравниваются, поэтому сравниваем их код
+		return reflect.ValueOf(a.comparator).Pointer() == reflect.ValueOf(other.comparator).Pointer()
+	}
+	return a.ordering == other.ordering
+}
+
+// newEmptyLike создаёт пустое дерево с теми же настройками
+func (a *AVLTree) newEmptyLike(name string) *AVLTree {
+	tree := NewAVLTreeWithMode(name, a.mode)
+	tree.ordering = a.ordering
+	tree.comparator = a.comparator
+	tree.multiset = a.multiset
+	return tree
+}
+
+func (a *AVLTree) cloneNode(node *AVLNode, count int) *AVLNode {
+	return &AVLNode{
+		Data:   node.Data,
+		Big:    node.Big,
+		Key:    node.Key,
+		Value:  node.Value,
+		Count:  count,
+		height: 1,
+		size:   count,
+	}
+}
+
+// mergeSets сливает отсортированные обходы двух деревьев за O(n + m).
+// combine получает кратности ключа в обоих деревьях (0 - ключа нет)
+// и возвращает кратность в результате.
+func (a *AVLTree) mergeSets(name string, other *AVLTree, combine func(x, y int) int) (*AVLTree, error) {
+	if other == nil || !a.compatibleWith(other) {
+		return nil, fmt.Errorf("trees are not compatible")
+	}
+
+	left := a.Traverse(IN_ORDER)
+	right := other.Traverse(IN_ORDER)
+	nodes := make([]*AVLNode, 0, len(left)+len(right))
+	i, j := 0, 0
+	for i < len(left) || j < len(right) {
+		var source *AVLNode
+		x, y := 0, 0
+		switch {
+		case j >= len(right) || (i < len(left) && a.compare(left[i], right[j]) < 0):
+			source, x = left[i], left[i].Count
+			i++
+		case i >= len(left) || a.compare(left[i], right[j]) > 0:
+			source, y = right[j], right[j].Count
+			j++
+		default:
+			// При совпадении ключей значение словаря берётся из первого дерева
+			source, x, y = left[i], left[i].Count, right[j].Count
+			i++
+			j++
+		}
+		if count := combine(x, y); count > 0 {
+			nodes = append(nodes, a.cloneNode(source, count))
+		}
+	}
+
+	result := a.newEmptyLike(name)
+	result.root = result.buildSortedHelper(nodes, 0, len(nodes)-1)
+	return result, nil
+}
+
+// Union, Intersect и Difference строят новое дерево за линейное время.
+// Для мультимножеств кратности объединяются как max, min и разность.
+func (a *AVLTree) Union(name string, other *AVLTree) (*AVLTree, error) {
+	return a.mergeSets(name, other, func(x, y int) int {
+		return max(x, y)
+	})
+}
+
+func (a *AVLTree) Intersect(name string, other *AVLTree) (*AVLTree, error) {
+	return a.mergeSets(name, other, func(x, y int) int {
+		if x < y {
+			return x
+		}
+		return y
+	})
+}
+
+func (a *AVLTree) Difference(name string, other *AVLTree) (*AVLTree, error) {
+	return a.mergeSets(name, other, func(x, y int) int {
+		return x - y
+	})
+}
+
+// joinNodes склеивает деревья left < middle < right за O(|h(left) - h(right)|)
+func (a *AVLTree) joinNodes(left, middle, right *AVLNode) *AVLNode {
+	middle = a.own(middle)
+	if a.height(left) > a.height(right)+1 {
+		return a.joinRight(left, middle, right)
+	}
+	if a.height(right) > a.height(left)+1 {
+		return a.joinLeft(left, middle, right)
+	}
+	middle.Left = left
+	middle.Right = right
+	a.update(middle)
+	return middle
+}
+
+func (a *AVLTree) joinRight(left, middle, right *AVLNode) *AVLNode {
+	left = a.own(left)
+	if a.height(left.Right) <= a.height(right)+1 {
+		middle.Left = left.Right
+		middle.Right = right
+		a.update(middle)
+		if a.height(middle) <= a.height(left.Left)+1 {
+			left.Right = middle
+			a.update(left)
+			return left
+		}
+		left.Right = a.rotateRight(middle)
+		a.update(left)
+		return a.rotateLeft(left)
+	}
+
+	left.Right = a.joinRight(left.Right, middle, right)
+	a.update(left)
+	if a.height(left.Right) <= a.height(left.Left)+1 {
+		return left
+	}
+	return a.rotateLeft(left)
+}
+
+func (a *AVLTree) joinLeft(left, middle, right *AVLNode) *AVLNode {
+	right = a.own(right)
+	if a.height(right.Left) <= a.height(left)+1 {
+		middle.Left = left
+		middle.Right = right.Left
+		a.update(middle)
+		if a.height(middle) <= a.height(right.Right)+1 {
+			right.Left = middle
+			a.update(right)
+			return right
+		}
+		right.Left = a.rotateLeft(middle)
+		a.update(right)
+		return a.rotateRight(right)
+	}
+
+	right.Left = a.joinLeft(left, middle, right.Left)
+	a.update(right)
+	if a.height(right.Left) <= a.height(right.Right)+1 {
+		return right
+	}
+	return a.rotateRight(right)
+}
+
+// splitNodes делит дерево на ключи < probe и >= probe за O(log n)
+func (a *AVLTree) splitNodes(node, probe *AVLNode) (*AVLNode, *AVLNode) {
+	if node == nil {
+		return nil, nil
+	}
+
+	left, right := node.Left, node.Right
+	if a.compare(node, probe) < 0 {
+		lower, upper := a.splitNodes(right, probe)
+		return a.joinNodes(left, node, lower), upper
+	}
+	lower, upper := a.splitNodes(left, probe)
+	return lower, a.joinNodes(upper, node, right)
+}
+
+// removeMax отделяет узел с наибольшим ключом, возвращает остаток и этот узел
+func (a *AVLTree) removeMax(node *AVLNode) (*AVLNode, *AVLNode) {
+	if node.Right == nil {
+		return node.Left, node
+	}
+	rest, maxNode := a.removeMax(node.Right)
+	return a.joinNodes(node.Left, node, rest), maxNode
+}
+
+// SplitKey переносит ключи < pivot в дерево leftName, а ключи >= pivot -
+// в дерево rightName за O(log n). Исходное дерево становится пустым.
+func (a *AVLTree) SplitKey(pivot, leftName, rightName string) (*AVLTree, *AVLTree, error) {
+	probe, err := a.ParseKey(pivot)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lower, upper := a.splitNodes(a.root, probe)
+	left := a.newEmptyLike(leftName)
+	right := a.newEmptyLike(rightName)
+	left.root = lower
+	right.root = upper
+	a.root = nil
+	return left, right, nil
+}
+
+// Join склеивает дерево с other, все ключи которого больше, за O(log n).
+// Оба исходных дерева становятся пустыми.
+func (a *AVLTree) Join(name string, other *AVLTree) (*AVLTree, error) {
+	if other == nil || !a.compatibleWith(other) {
+		return nil, fmt.Errorf("trees are not compatible")
+	}
+	if a.root != nil && other.root != nil && a.compare(a.Max(), other.Min()) >= 0 {
+		return nil, fmt.Errorf("keys of the left tree must be less than keys of the right tree")
+	}
+
+	result := a.newEmptyLike(name)
+	if a.root == nil {
+		result.root = other.root
+	} else {
+		rest, middle := a.removeMax(a.root)
+		result.root = a.joinNodes(rest, middle, other.root)
+	}
+	a.root = nil
+	other.root = nil
+	return result, nil
+}
+
+// fork возвращает новую версию дерева с тем же содержимым за O(1).
+// Узлы становятся общими, поэтому само дерево переходит в новое поколение.
+func (a *AVLTree) fork(name string) *AVLTree {
+	version := a.newEmptyLike(name)
+	version.root = a.root
+	a.gen = nextGeneration()
+	return version
+}
+
+// Snapshot сохраняет текущую версию дерева под меткой tag за O(1).
+// Последующие изменения копируют затронутые пути и не видны в снимке.
+func (a *AVLTree) Snapshot(tag string) error {
+	if tag == "" || strings.Contains(tag, "@") {
+		return fmt.Errorf("invalid snapshot tag '%s'", tag)
+	}
+	if a.snapshots == nil {
+		a.snapshots = make(map[string]*AVLTree)
+	}
+	a.snapshots[tag] = a.fork(a.name + "@" + tag)
+	return nil
+}
+
+// GetSnapshot возвращает снимок с меткой tag
+func (a *AVLTree) GetSnapshot(tag string) (*AVLTree, bool) {
+	snapshot, ok := a.snapshots[tag]
+	return snapshot, ok
+}
+
+// Snapshots возвращает метки снимков в алфавитном порядке
+func (a *AVLTree) Snapshots() []string {
+	tags := make([]string, 0, len(a.snapshots))
+	for tag := range a.snapshots {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func (a *AVLTree) DropSnapshot(tag string) bool {
+	if _, ok := a.snapshots[tag]; !ok {
+		return false
+	}
+	delete(a.snapshots, tag)
+	return true
 }
@@ -1,6 +1,11 @@
 package dbmsgo
 
 import (
+	"math"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -106,4 +111,746 @@ func TestAVLTree(t *testing.T) {
 		assert.True(t, tree.IsEmpty())
 		assert.Equal(t, 0, tree.CountElements())
 	})
+
+	t.Run("BuildFromSorted", func(t *testing.T) {
+		tree := NewAVLTree("test_tree")
+		values := make([]int64, 1000)
+		for i := range values {
+			values[i] = int64(i * 2)
+		}
+
+		assert.NoError(t, tree.BuildFromSorted(values))
+		assert.Equal(t, 1000, tree.CountElements())
+		assert.Equal(t, values, tree.SaveTree())
+		assert.LessOrEqual(t, tree.GetRoot().height, 11)
+		assert.NotNil(t, tree.Search(500))
+		assert.Nil(t, tree.Search(501))
+
+		// После bulk-build дерево остаётся обычным AVL-деревом
+		tree.Insert(501)
+		tree.Remove(0)
+		assert.NotNil(t, tree.Search(501))
+		assert.Equal(t, 1000, tree.CountElements())
+
+		assert.Error(t, tree.BuildFromSorted([]int64{1, 3, 2}))
+		assert.Error(t, tree.BuildFromSorted([]int64{1, 1}))
+	})
+
+	t.Run("ShapeRoundTrip", func(t *testing.T) {
+		tree := NewAVLTree("test_tree")
+		for _, v := range []int64{50, 30, 70, 20, 40, 60, 80, 10, 25, 5} {
+			tree.Insert(v)
+		}
+
+		values, heights := tree.SaveTreeShape()
+		restored := NewAVLTree("restored")
+		assert.NoError(t, restored.BuildFromShape(values, heights))
+
+		restoredValues, restoredHeights := restored.SaveTreeShape()
+		assert.Equal(t, values, restoredValues)
+		assert.Equal(t, heights, restoredHeights)
+		assert.Equal(t, tree.SaveTree(), restored.SaveTree())
+	})
+
+	t.Run("BuildFromShapeInvalid", func(t *testing.T) {
+		tree := NewAVLTree("test_tree")
+
+		assert.Error(t, tree.BuildFromShape([]int64{1, 2}, []int{1}))
+		// Неверная высота
+		assert.Error(t, tree.BuildFromShape([]int64{2, 1, 3}, []int{3, 1, 1}))
+		// Не pre-order последовательность
+		assert.Error(t, tree.BuildFromShape([]int64{2, 3, 1}, []int{2, 1, 1}))
+		// Несбалансированная цепочка
+		assert.Error(t, tree.BuildFromShape([]int64{1, 2, 3}, []int{3, 2, 1}))
+		assert.True(t, tree.IsEmpty())
+	})
+
+	t.Run("BigIntKeys", func(t *testing.T) {
+		tree := NewAVLTreeWithMode("big_tree", BIGINT_KEYS)
+		assert.Equal(t, BIGINT_KEYS, tree.GetMode())
+
+		huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+		assert.NoError(t, tree.InsertBig(huge))
+		assert.NoError(t, tree.InsertKey("-99999999999999999999999"))
+		assert.NoError(t, tree.InsertKey("42"))
+		tree.Insert(7)
+		assert.Error(t, tree.InsertKey("not_a_number"))
+
+		assert.Equal(t, []string{"-99999999999999999999999", "7", "42", "123456789012345678901234567890"}, tree.SaveKeys())
+		assert.NotNil(t, tree.SearchBig(huge))
+		assert.NotNil(t, tree.Search(42))
+
+		node, err := tree.SearchKey("123456789012345678901234567890")
+		assert.NoError(t, err)
+		assert.NotNil(t, node)
+		assert.Equal(t, 0, node.Big.Cmp(huge))
+
+		assert.NoError(t, tree.RemoveBig(huge))
+		assert.Nil(t, tree.SearchBig(huge))
+		assert.NoError(t, tree.RemoveKey("42"))
+		assert.Equal(t, 2, tree.CountElements())
+	})
+
+	t.Run("Int64Keys", func(t *testing.T) {
+		tree := NewAVLTree("test_tree")
+
+		tree.Insert(math.MaxInt64)
+		tree.Insert(math.MinInt64)
+		assert.NoError(t, tree.InsertKey("9000000000"))
+		assert.Equal(t, []int64{math.MinInt64, 9000000000, math.MaxInt64}, tree.SaveTree())
+
+		// Ключ за пределами int не помещается в дерево с INT_KEYS
+		tooBig, _ := new(big.Int).SetString("99999999999999999999", 10)
+		assert.Error(t, tree.InsertBig(tooBig))
+		assert.Error(t, tree.InsertKey("99999999999999999999"))
+		assert.Nil(t, tree.SearchBig(tooBig))
+		assert.NoError(t, tree.InsertBig(big.NewInt(5)))
+		assert.NotNil(t, tree.Search(5))
+	})
+
+	t.Run("BuildFromSortedKeys", func(t *testing.T) {
+		tree := NewAVLTreeWithMode("big_tree", BIGINT_KEYS)
+		keys := []string{"-5", "10", "100000000000000000000", "200000000000000000000"}
+
+		assert.NoError(t, tree.BuildFromSortedKeys(keys))
+		assert.Equal(t, keys, tree.SaveKeys())
+		assert.Error(t, tree.BuildFromSortedKeys([]string{"2", "1"}))
+		assert.Error(t, tree.BuildFromSortedKeys([]string{"1", "x"}))
+	})
+
+	t.Run("OrderedMapLex", func(t *testing.T) {
+		tree := NewOrderedMap("dict", LEX_ORDER)
+		assert.Equal(t, STRING_KEYS, tree.GetMode())
+		assert.Equal(t, LEX_ORDER, tree.GetOrdering())
+
+		assert.NoError(t, tree.Put("banana", "yellow"))
+		assert.NoError(t, tree.Put("apple", "red"))
+		assert.NoError(t, tree.Put("10", "ten"))
+		assert.NoError(t, tree.Put("9", "nine"))
+		assert.NoError(t, tree.Put("apple", "green"))
+
+		keys, values := tree.SaveEntries()
+		assert.Equal(t, []string{"10", "9", "apple", "banana"}, keys)
+		assert.Equal(t, []string{"ten", "nine", "green", "yellow"}, values)
+
+		value, found := tree.Get("apple")
+		assert.True(t, found)
+		assert.Equal(t, "green", value)
+		_, found = tree.Get("cherry")
+		assert.False(t, found)
+
+		// При удалении узла с двумя детьми значение переносится вместе с ключом
+		assert.NoError(t, tree.RemoveKey("9"))
+		value, _ = tree.Get("10")
+		assert.Equal(t, "ten", value)
+		value, _ = tree.Get("banana")
+		assert.Equal(t, "yellow", value)
+		assert.Equal(t, 3, tree.CountElements())
+	})
+
+	t.Run("OrderedMapNumeric", func(t *testing.T) {
+		tree := NewOrderedMap("dict", NUMERIC_ORDER)
+
+		assert.NoError(t, tree.Put("10", "a"))
+		assert.NoError(t, tree.Put("9.5", "b"))
+		assert.NoError(t, tree.Put("-1e3", "c"))
+		assert.NoError(t, tree.Put("100", "d"))
+		assert.Error(t, tree.Put("abc", "e"))
+
+		keys, _ := tree.SaveEntries()
+		assert.Equal(t, []string{"-1e3", "9.5", "10", "100"}, keys)
+
+		// Численно равные ключи в разной записи остаются разными и
+		// упорядочиваются лексикографически
+		assert.NoError(t, tree.Put("10.0", "other"))
+		value, found := tree.Get("10")
+		assert.True(t, found)
+		assert.Equal(t, "a", value)
+		assert.Equal(t, 5, tree.CountElements())
+		keys, _ = tree.SaveEntries()
+		assert.Equal(t, []string{"-1e3", "9.5", "10", "10.0", "100"}, keys)
+	})
+
+	t.Run("OrderedMapCustomComparator", func(t *testing.T) {
+		byLength := func(a, b string) int {
+			if len(a) != len(b) {
+				return len(a) - len(b)
+			}
+			return strings.Compare(a, b)
+		}
+		tree := NewOrderedMapWithComparator("dict", byLength)
+		for _, key := range []string{"ccc", "a", "bb", "aa"} {
+			assert.NoError(t, tree.Put(key, key))
+		}
+
+		keys, _ := tree.SaveEntries()
+		assert.Equal(t, []string{"a", "aa", "bb", "ccc"}, keys)
+		assert.Equal(t, CUSTOM_ORDER, tree.GetOrdering())
+		assert.Equal(t, "CUSTOM", tree.GetOrdering().String())
+
+		assert.True(t, tree.compatibleWith(NewOrderedMapWithComparator("other", byLength)))
+		assert.False(t, tree.compatibleWith(NewOrderedMapWithComparator("other", LexicographicOrder)))
+		assert.False(t, tree.compatibleWith(NewOrderedMap("other", LEX_ORDER)))
+	})
+
+	t.Run("Comparators", func(t *testing.T) {
+		assert.Less(t, LexicographicOrder("10", "9"), 0)
+		assert.Greater(t, NumericOrder("10", "9"), 0)
+		assert.Equal(t, 0, NumericOrder("1.50", "1.5"))
+		assert.Less(t, NumericOrder("5", "abc"), 0)
+		assert.Greater(t, NumericOrder("abc", "5"), 0)
+		assert.Less(t, NumericKeyOrder("1", "1.0"), 0)
+		assert.Greater(t, NumericKeyOrder("10", "9.5"), 0)
+
+		ordering, err := ParseTreeOrdering("NUM")
+		assert.NoError(t, err)
+		assert.Equal(t, NUMERIC_ORDER, ordering)
+		assert.Equal(t, "NUM", ordering.String())
+		_, err = ParseTreeOrdering("RANDOM")
+		assert.Error(t, err)
+	})
+
+	t.Run("MinMax", func(t *testing.T) {
+		tree := NewAVLTree("test_tree")
+		assert.Nil(t, tree.Min())
+		assert.Nil(t, tree.Max())
+
+		for _, v := range []int64{50, 30, 70, 20, 40} {
+			tree.Insert(v)
+		}
+		assert.Equal(t, int64(20), tree.Min().Data)
+		assert.Equal(t, int64(70), tree.Max().Data)
+	})
+
+	t.Run("FloorCeilPrevNext", func(t *testing.T) {
+		tree := NewAVLTree("test_tree")
+		for _, v := range []int64{10, 20, 30, 40, 50} {
+			tree.Insert(v)
+		}
+
+		assert.Equal(t, int64(30), tree.Floor(30).Data)
+		assert.Equal(t, int64(30), tree.Floor(35).Data)
+		assert.Nil(t, tree.Floor(5))
+		assert.Equal(t, int64(30), tree.Ceil(30).Data)
+		assert.Equal(t, int64(40), tree.Ceil(35).Data)
+		assert.Nil(t, tree.Ceil(55))
+		assert.Equal(t, int64(20), tree.Prev(30).Data)
+		assert.Nil(t, tree.Prev(10))
+		assert.Equal(t, int64(40), tree.Next(30).Data)
+		assert.Nil(t, tree.Next(50))
+
+		node, err := tree.FloorKey("49")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(40), node.Data)
+		node, err = tree.NextKey("0")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), node.Data)
+		_, err = tree.CeilKey("abc")
+		assert.Error(t, err)
+		_, err = tree.PrevKey("abc")
+		assert.Error(t, err)
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		tree := NewAVLTree("test_tree")
+		for i := 1; i <= 100; i++ {
+			tree.Insert(int64(i * 10))
+		}
+
+		keys := func(nodes []*AVLNode) []int64 {
+			result := make([]int64, 0)
+			for _, node := range nodes {
+				result = append(result, node.Data)
+			}
+			return result
+		}
+
+		assert.Equal(t, []int64{250, 260, 270, 280, 290, 300}, keys(tree.Range(245, 300, 0)))
+		assert.Equal(t, []int64{250, 260}, keys(tree.Range(245, 300, 2)))
+		assert.Equal(t, []int64{10}, keys(tree.Range(-100, 10, 0)))
+		assert.Empty(t, tree.Range(301, 309, 0))
+		assert.Empty(t, tree.Range(500, 100, 0))
+		assert.Equal(t, 100, len(tree.Range(0, 1000, 0)))
+
+		dict := NewOrderedMap("dict", LEX_ORDER)
+		for _, key := range []string{"apple", "banana", "cherry", "date"} {
+			dict.Put(key, key+"_value")
+		}
+		nodes, err := dict.RangeKeys("b", "d", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(nodes))
+		assert.Equal(t, "banana", nodes[0].Key)
+		assert.Equal(t, "cherry_value", nodes[1].Value)
+
+		_, err = tree.RangeKeys("x", "10", 0)
+		assert.Error(t, err)
+		_, err = tree.RangeKeys("10", "x", 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("OrderStatistics", func(t *testing.T) {
+		tree := NewAVLTree("test_tree")
+		for i := 1; i <= 100; i++ {
+			tree.Insert(int64(i))
+		}
+		for i := 2; i <= 100; i += 2 {
+			tree.Remove(int64(i))
+		}
+		// Остались нечётные 1, 3, ..., 99
+
+		assert.Equal(t, 50, tree.Size())
+		assert.Equal(t, 50, tree.CountElements())
+		assert.Equal(t, 0, tree.Rank(1))
+		assert.Equal(t, 1, tree.Rank(2))
+		assert.Equal(t, 1, tree.Rank(3))
+		assert.Equal(t, 50, tree.Rank(1000))
+
+		assert.Equal(t, int64(1), tree.Select(0).Data)
+		assert.Equal(t, int64(99), tree.Select(49).Data)
+		assert.Equal(t, int64(95), tree.Select(47).Data)
+		assert.Nil(t, tree.Select(50))
+		assert.Nil(t, tree.Select(-1))
+
+		assert.Equal(t, 5, tree.CountRange(10, 20))
+		assert.Equal(t, 50, tree.CountRange(-5, 500))
+		assert.Equal(t, 0, tree.CountRange(20, 10))
+
+		rank, err := tree.RankKey("50")
+		assert.NoError(t, err)
+		assert.Equal(t, 25, rank)
+		_, err = tree.RankKey("x")
+		assert.Error(t, err)
+		count, err := tree.CountRangeKeys("1", "3")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		_, err = tree.CountRangeKeys("1", "x")
+		assert.Error(t, err)
+	})
+
+	t.Run("SizesAfterBulkBuild", func(t *testing.T) {
+		tree := NewAVLTree("test_tree")
+		tree.BuildFromSorted([]int64{1, 2, 3, 4, 5, 6, 7})
+		assert.Equal(t, 7, tree.Size())
+		assert.Equal(t, int64(4), tree.Select(3).Data)
+
+		values, heights := tree.SaveTreeShape()
+		restored := NewAVLTree("restored")
+		restored.BuildFromShape(values, heights)
+		assert.Equal(t, 7, restored.Size())
+		assert.Equal(t, int64(6), restored.Select(5).Data)
+	})
+
+	t.Run("Multiset", func(t *testing.T) {
+		tree := NewMultisetTree("measurements", INT_KEYS)
+		assert.True(t, tree.IsMultiset())
+
+		for _, v := range []int64{5, 3, 5, 7, 5, 3} {
+			tree.Insert(v)
+		}
+		assert.Equal(t, 6, tree.Size())
+		assert.Equal(t, 3, tree.CountOf(5))
+		assert.Equal(t, 2, tree.CountOf(3))
+		assert.Equal(t, 0, tree.CountOf(4))
+		assert.Equal(t, []int64{3, 3, 5, 5, 5, 7}, tree.SaveTree())
+
+		keys, counts := tree.SaveCounts()
+		assert.Equal(t, []string{"3", "5", "7"}, keys)
+		assert.Equal(t, []int{2, 3, 1}, counts)
+
+		// Ранги и выборка учитывают кратность
+		assert.Equal(t, 2, tree.Rank(5))
+		assert.Equal(t, 5, tree.Rank(7))
+		assert.Equal(t, int64(5), tree.Select(4).Data)
+		assert.Equal(t, int64(7), tree.Select(5).Data)
+		assert.Equal(t, 5, tree.CountRange(4, 5)+tree.CountRange(3, 3))
+
+		tree.Remove(5)
+		assert.Equal(t, 2, tree.CountOf(5))
+		assert.Equal(t, 5, tree.Size())
+
+		tree.RemoveAll(5)
+		assert.Nil(t, tree.Search(5))
+		assert.Equal(t, 3, tree.Size())
+
+		// Удаление узла с двумя детьми сохраняет кратность преемника
+		tree.Insert(5)
+		tree.Insert(8)
+		tree.Insert(8)
+		tree.Insert(6)
+		tree.RemoveAll(7)
+		assert.Equal(t, 2, tree.CountOf(8))
+		checkAVLInvariants(t, tree, tree.GetRoot())
+
+		count, err := tree.CountOfKey("3")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.NoError(t, tree.RemoveAllKey("3"))
+		assert.Equal(t, 0, tree.CountOf(3))
+		_, err = tree.CountOfKey("x")
+		assert.Error(t, err)
+		assert.Error(t, tree.RemoveAllKey("x"))
+	})
+
+	t.Run("BuildFromSortedCounts", func(t *testing.T) {
+		tree := NewMultisetTree("big", BIGINT_KEYS)
+		assert.NoError(t, tree.BuildFromSortedCounts([]string{"1", "100000000000000000000"}, []int{3, 2}))
+		assert.Equal(t, 5, tree.Size())
+		assert.Equal(t, []string{"1", "1", "1", "100000000000000000000", "100000000000000000000"}, tree.SaveKeys())
+		checkAVLInvariants(t, tree, tree.GetRoot())
+
+		assert.Error(t, tree.BuildFromSortedCounts([]string{"1"}, []int{0}))
+		assert.Error(t, tree.BuildFromSortedCounts([]string{"1"}, []int{1, 2}))
+		assert.Error(t, NewAVLTree("set").BuildFromSortedCounts([]string{"1"}, []int{1}))
+	})
+
+	t.Run("Traversals", func(t *testing.T) {
+		tree := NewAVLTree("test_tree")
+		for _, v := range []int64{50, 30, 70, 20, 40, 60} {
+			tree.Insert(v)
+		}
+
+		keys := func(order TraversalOrder) []int64 {
+			result := make([]int64, 0)
+			for _, node := range tree.Traverse(order) {
+				result = append(result, node.Data)
+			}
+			return result
+		}
+
+		assert.Equal(t, []int64{20, 30, 40, 50, 60, 70}, keys(IN_ORDER))
+		assert.Equal(t, []int64{50, 30, 20, 40, 70, 60}, keys(PRE_ORDER))
+		assert.Equal(t, []int64{20, 40, 30, 60, 70, 50}, keys(POST_ORDER))
+		assert.Equal(t, []int64{50, 30, 70, 20, 40, 60}, keys(LEVEL_ORDER))
+		assert.Empty(t, NewAVLTree("empty").Traverse(LEVEL_ORDER))
+
+		order, err := ParseTraversalOrder("level")
+		assert.NoError(t, err)
+		assert.Equal(t, LEVEL_ORDER, order)
+		assert.Equal(t, "post-order", POST_ORDER.String())
+		_, err = ParseTraversalOrder("zigzag")
+		assert.Error(t, err)
+	})
+
+	t.Run("RenderTree", func(t *testing.T) {
+		assert.Equal(t, "(пусто)\n", NewAVLTree("empty").RenderTree())
+
+		tree := NewAVLTree("test_tree")
+		for _, v := range []int64{50, 30, 70, 20} {
+			tree.Insert(v)
+		}
+
+		expected := "50 [h=3, bf=1]\n" +
+			"├── L: 30 [h=2, bf=1]\n" +
+			"│   ├── L: 20 [h=1, bf=0]\n" +
+			"│   └── R: ∅\n" +
+			"└── R: 70 [h=1, bf=0]\n"
+		assert.Equal(t, expected, tree.RenderTree())
+
+		multi := NewMultisetTree("multi", INT_KEYS)
+		multi.Insert(5)
+		multi.Insert(5)
+		assert.Equal(t, "5 x2 [h=1, bf=0]\n", multi.RenderTree())
+	})
+
+	t.Run("SetAlgebra", func(t *testing.T) {
+		a := NewAVLTree("a")
+		b := NewAVLTree("b")
+		assert.NoError(t, a.BuildFromSorted([]int64{1, 2, 3, 4, 5}))
+		assert.NoError(t, b.BuildFromSorted([]int64{4, 5, 6, 7}))
+
+		union, err := a.Union("u", b)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7}, union.SaveTree())
+		assert.Equal(t, "u", union.GetName())
+		checkAVLInvariants(t, union, union.GetRoot())
+
+		inter, err := a.Intersect("i", b)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{4, 5}, inter.SaveTree())
+
+		diff, err := a.Difference("d", b)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{1, 2, 3}, diff.SaveTree())
+
+		// Исходные деревья не меняются
+		assert.Equal(t, []int64{1, 2, 3, 4, 5}, a.SaveTree())
+		assert.Equal(t, []int64{4, 5, 6, 7}, b.SaveTree())
+	})
+
+	t.Run("SetAlgebraMultiset", func(t *testing.T) {
+		a := NewMultisetTree("a", INT_KEYS)
+		b := NewMultisetTree("b", INT_KEYS)
+		assert.NoError(t, a.BuildFromSortedCounts([]string{"1", "2", "3"}, []int{3, 1, 2}))
+		assert.NoError(t, b.BuildFromSortedCounts([]string{"1", "3", "4"}, []int{1, 5, 2}))
+
+		union, err := a.Union("u", b)
+		assert.NoError(t, err)
+		keys, counts := union.SaveCounts()
+		assert.Equal(t, []string{"1", "2", "3", "4"}, keys)
+		assert.Equal(t, []int{3, 1, 5, 2}, counts)
+		assert.Equal(t, 11, union.Size())
+
+		inter, err := a.Intersect("i", b)
+		assert.NoError(t, err)
+		keys, counts = inter.SaveCounts()
+		assert.Equal(t, []string{"1", "3"}, keys)
+		assert.Equal(t, []int{1, 2}, counts)
+
+		diff, err := a.Difference("d", b)
+		assert.NoError(t, err)
+		keys, counts = diff.SaveCounts()
+		assert.Equal(t, []string{"1", "2"}, keys)
+		assert.Equal(t, []int{2, 1}, counts)
+		checkAVLInvariants(t, diff, diff.GetRoot())
+	})
+
+	t.Run("SetAlgebraMap", func(t *testing.T) {
+		a := NewOrderedMap("a", LEX_ORDER)
+		b := NewOrderedMap("b", LEX_ORDER)
+		assert.NoError(t, a.Put("apple", "1"))
+		assert.NoError(t, a.Put("pear", "2"))
+		assert.NoError(t, b.Put("pear", "20"))
+		assert.NoError(t, b.Put("plum", "30"))
+
+		union, err := a.Union("u", b)
+		assert.NoError(t, err)
+		keys, values := union.SaveEntries()
+		assert.Equal(t, []string{"apple", "pear", "plum"}, keys)
+		assert.Equal(t, []string{"1", "2", "30"}, values)
+
+		inter, err := b.Intersect("i", a)
+		assert.NoError(t, err)
+		keys, values = inter.SaveEntries()
+		assert.Equal(t, []string{"pear"}, keys)
+		assert.Equal(t, []string{"20"}, values)
+	})
+
+	t.Run("IncompatibleTrees", func(t *testing.T) {
+		set := NewAVLTree("set")
+		multi := NewMultisetTree("multi", INT_KEYS)
+		lex := NewOrderedMap("lex", LEX_ORDER)
+		num := NewOrderedMap("num", NUMERIC_ORDER)
+
+		_, err := set.Union("u", multi)
+		assert.Error(t, err)
+		_, err = lex.Intersect("i", num)
+		assert.Error(t, err)
+		_, err = set.Difference("d", lex)
+		assert.Error(t, err)
+		_, err = set.Join("j", multi)
+		assert.Error(t, err)
+	})
+
+	t.Run("SplitAndJoin", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(2))
+		for iteration := 0; iteration < 50; iteration++ {
+			n := rng.Intn(300)
+			tree := NewAVLTree("source")
+			for i := 0; i < n; i++ {
+				tree.Insert(rng.Int63n(1000))
+			}
+			all := tree.SaveTree()
+			pivot := rng.Int63n(1100) - 50
+
+			left, right, err := tree.SplitKey(strconv.FormatInt(pivot, 10), "left", "right")
+			assert.NoError(t, err)
+			assert.True(t, tree.IsEmpty())
+			checkAVLInvariants(t, left, left.GetRoot())
+			checkAVLInvariants(t, right, right.GetRoot())
+			for _, v := range left.SaveTree() {
+				assert.Less(t, v, pivot)
+			}
+			for _, v := range right.SaveTree() {
+				assert.GreaterOrEqual(t, v, pivot)
+			}
+			assert.Equal(t, len(all), left.Size()+right.Size())
+
+			joined, err := left.Join("joined", right)
+			assert.NoError(t, err)
+			checkAVLInvariants(t, joined, joined.GetRoot())
+			assert.Equal(t, all, joined.SaveTree())
+			assert.True(t, left.IsEmpty())
+			assert.True(t, right.IsEmpty())
+		}
+	})
+
+	t.Run("JoinUnbalancedHeights", func(t *testing.T) {
+		small := NewAVLTree("small")
+		small.Insert(1)
+		large := NewAVLTree("large")
+		for i := 10; i < 1010; i++ {
+			large.Insert(int64(i))
+		}
+
+		joined, err := small.Join("joined", large)
+		assert.NoError(t, err)
+		checkAVLInvariants(t, joined, joined.GetRoot())
+		assert.Equal(t, 1001, joined.Size())
+
+		other := NewAVLTree("other")
+		other.Insert(5000)
+		joined, err = joined.Join("joined", other)
+		assert.NoError(t, err)
+		checkAVLInvariants(t, joined, joined.GetRoot())
+		assert.Equal(t, int64(5000), joined.Max().Data)
+	})
+
+	t.Run("JoinOverlapping", func(t *testing.T) {
+		left := NewAVLTree("left")
+		right := NewAVLTree("right")
+		left.Insert(5)
+		right.Insert(5)
+
+		_, err := left.Join("j", right)
+		assert.Error(t, err)
+		assert.False(t, left.IsEmpty())
+		assert.False(t, right.IsEmpty())
+	})
+
+	t.Run("SplitMultisetKeepsCounts", func(t *testing.T) {
+		multi := NewMultisetTree("multi", INT_KEYS)
+		assert.NoError(t, multi.BuildFromSortedCounts([]string{"1", "2", "3"}, []int{2, 3, 4}))
+
+		left, right, err := multi.SplitKey("2", "l", "r")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, left.Size())
+		assert.Equal(t, 7, right.Size())
+		assert.Equal(t, 3, right.CountOf(2))
+
+		_, _, err = right.SplitKey("x", "l", "r")
+		assert.Error(t, err)
+	})
+
+	t.Run("SnapshotIsolation", func(t *testing.T) {
+		tree := NewAVLTree("test_tree")
+		for i := 0; i < 100; i++ {
+			tree.Insert(int64(i))
+		}
+		assert.NoError(t, tree.Snapshot("v1"))
+		expected := tree.SaveTree()
+
+		rng := rand.New(rand.NewSource(3))
+		for i := 0; i < 2000; i++ {
+			if rng.Intn(2) == 0 {
+				tree.Insert(rng.Int63n(300))
+			} else {
+				tree.Remove(rng.Int63n(300))
+			}
+		}
+
+		snapshot, ok := tree.GetSnapshot("v1")
+		assert.True(t, ok)
+		assert.Equal(t, "test_tree@v1", snapshot.GetName())
+		assert.Equal(t, expected, snapshot.SaveTree())
+		checkAVLInvariants(t, snapshot, snapshot.GetRoot())
+		checkAVLInvariants(t, tree, tree.GetRoot())
+	})
+
+	t.Run("SnapshotSharesNodes", func(t *testing.T) {
+		tree := NewAVLTree("test_tree")
+		for i := 0; i < 1000; i++ {
+			tree.Insert(int64(i))
+		}
+		assert.NoError(t, tree.Snapshot("v1"))
+		snapshot, _ := tree.GetSnapshot("v1")
+		assert.Same(t, snapshot.GetRoot(), tree.GetRoot())
+
+		tree.Insert(1000)
+		assert.NotSame(t, snapshot.GetRoot(), tree.GetRoot())
+		// Левое поддерево корня путь вставки не затрагивает
+		assert.Same(t, snapshot.GetRoot().Left, tree.GetRoot().Left)
+		assert.Nil(t, snapshot.Search(1000))
+	})
+
+	t.Run("SnapshotMultisetAndMap", func(t *testing.T) {
+		multi := NewMultisetTree("multi", INT_KEYS)
+		multi.Insert(7)
+		assert.NoError(t, multi.Snapshot("one"))
+		multi.Insert(7)
+		multi.Insert(7)
+		snapshot, _ := multi.GetSnapshot("one")
+		assert.Equal(t, 1, snapshot.CountOf(7))
+		assert.Equal(t, 3, multi.CountOf(7))
+
+		dict := NewOrderedMap("dict", LEX_ORDER)
+		assert.NoError(t, dict.Put("color", "red"))
+		assert.NoError(t, dict.Snapshot("old"))
+		assert.NoError(t, dict.Put("color", "blue"))
+		old, _ := dict.GetSnapshot("old")
+		value, _ := old.Get("color")
+		assert.Equal(t, "red", value)
+		value, _ = dict.Get("color")
+		assert.Equal(t, "blue", value)
+	})
+
+	t.Run("SnapshotSurvivesSplitAndJoin", func(t *testing.T) {
+		tree := NewAVLTree("test_tree")
+		for i := 0; i < 200; i++ {
+			tree.Insert(int64(i))
+		}
+		assert.NoError(t, tree.Snapshot("before"))
+		expected := tree.SaveTree()
+
+		left, right, err := tree.SplitKey("77", "l", "r")
+		assert.NoError(t, err)
+		joined, err := right.Join("j", left)
+		assert.Error(t, err)
+		joined, err = left.Join("j", right)
+		assert.NoError(t, err)
+		joined.Remove(5)
+
+		snapshot, _ := tree.GetSnapshot("before")
+		assert.Equal(t, expected, snapshot.SaveTree())
+		checkAVLInvariants(t, snapshot, snapshot.GetRoot())
+	})
+
+	t.Run("SnapshotTags", func(t *testing.T) {
+		tree := NewAVLTree("test_tree")
+		assert.Error(t, tree.Snapshot(""))
+		assert.Error(t, tree.Snapshot("a@b"))
+		assert.NoError(t, tree.Snapshot("b"))
+		assert.NoError(t, tree.Snapshot("a"))
+		assert.Equal(t, []string{"a", "b"}, tree.Snapshots())
+		assert.True(t, tree.DropSnapshot("a"))
+		assert.False(t, tree.DropSnapshot("a"))
+		tree.Cleanup()
+		assert.Empty(t, tree.Snapshots())
+	})
+
+	t.Run("InvariantsUnderRandomOperations", func(t *testing.T) {
+		tree := NewAVLTree("test_tree")
+		rng := rand.New(rand.NewSource(1))
+		present := make(map[int64]bool)
+
+		for i := 0; i < 5000; i++ {
+			value := rng.Int63n(500)
+			if rng.Intn(3) == 0 {
+				tree.Remove(value)
+				delete(present, value)
+			} else {
+				tree.Insert(value)
+				present[value] = true
+			}
+		}
+
+		checkAVLInvariants(t, tree, tree.GetRoot())
+		assert.Equal(t, len(present), tree.Size())
+	})
 }
+
+// checkAVLInvariants проверяет высоты, размеры и баланс всех узлов
+func checkAVLInvariants(t *testing.T, tree *AVLTree, node *AVLNode) (int, int) {
+	if node == nil {
+		return 0, 0
+	}
+	leftHeight, leftSize := checkAVLInvariants(t, tree, node.Left)
+	rightHeight, rightSize := checkAVLInvariants(t, tree, node.Right)
+
+	height := 1 + max(leftHeight, rightHeight)
+	assert.Equal(t, height, node.height)
+	assert.Equal(t, node.Count+leftSize+rightSize, node.size)
+	assert.LessOrEqual(t, leftHeight-rightHeight, 1)
+	assert.GreaterOrEqual(t, leftHeight-rightHeight, -1)
+	return node.height, node.size
+}
+
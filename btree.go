@@ -0,0 +1,293 @@
+package dbmsgo
+
+import "sort"
+
+// bTreeDegree - минимальная степень: в узле от degree-1 до 2*degree-1 ключей
+const bTreeDegree = 32
+
+type BTreeNode struct {
+	Keys     []int64
+	Children []*BTreeNode // пусто у листа
+}
+
+func (n *BTreeNode) isLeaf() bool {
+	return len(n.Children) == 0
+}
+
+// find возвращает позицию первого ключа >= value
+func (n *BTreeNode) find(value int64) (int, bool) {
+	i := sort.Search(len(n.Keys), func(i int) bool { return n.Keys[i] >= value })
+	return i, i < len(n.Keys) && n.Keys[i] == value
+}
+
+// BTree - B-дерево: много ключей в узле, поэтому дерево низкое и хорошо
+// работает с кэшем процессора.
+type BTree struct {
+	name   string
+	root   *BTreeNode
+	degree int
+	size   int
+}
+
+func NewBTree(name string) *BTree {
+	return NewBTreeWithDegree(name, bTreeDegree)
+}
+
+// NewBTreeWithDegree создаёт B-дерево с заданной минимальной степенью (не меньше 2)
+func NewBTreeWithDegree(name string, degree int) *BTree {
+	if degree < 2 {
+		degree = 2
+	}
+	return &BTree{
+		name:   name,
+		root:   &BTreeNode{},
+		degree: degree,
+	}
+}
+
+func (t *BTree) maxKeys() int {
+	return 2*t.degree - 1
+}
+
+func (t *BTree) Contains(value int64) bool {
+	node := t.root
+	for {
+		i, found := node.find(value)
+		if found {
+			return true
+		}
+		if node.isLeaf() {
+			return false
+		}
+		node = node.Children[i]
+	}
+}
+
+// splitChild делит заполненного ребёнка parent.Children[i] пополам,
+// средний ключ поднимается в parent
+func (t *BTree) splitChild(parent *BTreeNode, i int) {
+	child := parent.Children[i]
+	mid := t.degree - 1
+
+	right := &BTreeNode{Keys: append([]int64(nil), child.Keys[mid+1:]...)}
+	if !child.isLeaf() {
+		right.Children = append([]*BTreeNode(nil), child.Children[mid+1:]...)
+		child.Children = child.Children[:mid+1]
+	}
+	median := child.Keys[mid]
+	child.Keys = child.Keys[:mid]
+
+	parent.Keys = append(parent.Keys, 0)
+	copy(parent.Keys[i+1:], parent.Keys[i:])
+	parent.Keys[i] = median
+
+	parent.Children = append(parent.Children, nil)
+	copy(parent.Children[i+2:], parent.Children[i+1:])
+	parent.Children[i+1] = right
+}
+
+// Insert спускается от корня за один проход, заранее деля заполненные узлы
+func (t *BTree) Insert(value int64) {
+	if t.Contains(value) {
+		return
+	}
+
+	if len(t.root.Keys) == t.maxKeys() {
+		root := &BTreeNode{Children: []*BTreeNode{t.root}}
+		t.splitChild(root, 0)
+		t.root = root
+	}
+
+	node := t.root
+	for !node.isLeaf() {
+		i, _ := node.find(value)
+		if len(node.Children[i].Keys) == t.maxKeys() {
+			t.splitChild(node, i)
+			if value > node.Keys[i] {
+				i++
+			}
+		}
+		node = node.Children[i]
+	}
+
+	i, _ := node.find(value)
+	node.Keys = append(node.Keys, 0)
+	copy(node.Keys[i+1:], node.Keys[i:])
+	node.Keys[i] = value
+	t.size++
+}
+
+func (t *BTree) Remove(value int64) {
+	if !t.Contains(value) {
+		return
+	}
+
+	t.removeFrom(t.root, value)
+	t.size--
+	if len(t.root.Keys) == 0 && !t.root.isLeaf() {
+		t.root = t.root.Children[0]
+	}
+}
+
+// removeFrom удаляет ключ из поддерева. Перед спуском в ребёнка тот
+// пополняется до degree ключей, поэтому возвраты вверх не нужны.
+func (t *BTree) removeFrom(node *BTreeNode, value int64) {
+	i, found := node.find(value)
+
+	if node.isLeaf() {
+		node.Keys = append(node.Keys[:i], node.Keys[i+1:]...)
+		return
+	}
+
+	if found {
+		switch {
+		case len(node.Children[i].Keys) >= t.degree:
+			predecessor := node.Children[i]
+			for !predecessor.isLeaf() {
+				predecessor = predecessor.Children[len(predecessor.Children)-1]
+			}
+			node.Keys[i] = predecessor.Keys[len(predecessor.Keys)-1]
+			t.removeFrom(node.Children[i], node.Keys[i])
+		case len(node.Children[i+1].Keys) >= t.degree:
+			successor := node.Children[i+1]
+			for !successor.isLeaf() {
+				successor = successor.Children[0]
+			}
+			node.Keys[i] = successor.Keys[0]
+			t.removeFrom(node.Children[i+1], node.Keys[i])
+		default:
+			t.merge(node, i)
+			t.removeFrom(node.Children[i], value)
+		}
+		return
+	}
+
+	if len(node.Children[i].Keys) < t.degree {
+		i = t.fill(node, i)
+	}
+	t.removeFrom(node.Children[i], value)
+}
+
+// fill пополняет ребёнка i за счёт соседа или слиянием с ним и
+// возвращает индекс ребёнка, в котором теперь лежит нужный диапазон
+func (t *BTree) fill(node *BTreeNode, i int) int {
+	if i > 0 && len(node.Children[i-1].Keys) >= t.degree {
+		child, left := node.Children[i], node.Children[i-1]
+		child.Keys = append([]int64{node.Keys[i-1]}, child.Keys...)
+		node.Keys[i-1] = left.Keys[len(left.Keys)-1]
+		left.Keys = left.Keys[:len(left.Keys)-1]
+		if !left.isLeaf() {
+			child.Children = append([]*BTreeNode{left.Children[len(left.Children)-1]}, child.Children...)
+			left.Children = left.Children[:len(left.Children)-1]
+		}
+		return i
+	}
+
+	if i < len(node.Children)-1 && len(node.Children[i+1].Keys) >= t.degree {
+		child, right := node.Children[i], node.Children[i+1]
+		child.Keys = append(child.Keys, node.Keys[i])
+		node.Keys[i] = right.Keys[0]
+		right.Keys = append(right.Keys[:0], right.Keys[1:]...)
+		if !right.isLeaf() {
+			child.Children = append(child.Children, right.Children[0])
+			right.Children = append(right.Children[:0], right.Children[1:]...)
+		}
+		return i
+	}
+
+	if i == len(node.Children)-1 {
+		i--
+	}
+	t.merge(node, i)
+	return i
+}
+
+// merge сливает детей i и i+1 вместе с разделяющим ключом
+func (t *BTree) merge(node *BTreeNode, i int) {
+	left, right := node.Children[i], node.Children[i+1]
+	left.Keys = append(left.Keys, node.Keys[i])
+	left.Keys = append(left.Keys, right.Keys...)
+	left.Children = append(left.Children, right.Children...)
+
+	node.Keys = append(node.Keys[:i], node.Keys[i+1:]...)
+	node.Children = append(node.Children[:i+1], node.Children[i+2:]...)
+}
+
+func (t *BTree) saveTreeHelper(node *BTreeNode, result *[]int64) {
+	for i, key := range node.Keys {
+		if !node.isLeaf() {
+			t.saveTreeHelper(node.Children[i], result)
+		}
+		*result = append(*result, key)
+	}
+	if !node.isLeaf() {
+		t.saveTreeHelper(node.Children[len(node.Children)-1], result)
+	}
+}
+
+func (t *BTree) SaveTree() []int64 {
+	result := make([]int64, 0, t.size)
+	t.saveTreeHelper(t.root, &result)
+	return result
+}
+
+// BuildFromSorted заменяет содержимое дерева значениями из строго
+// возрастающей последовательности
+func (t *BTree) BuildFromSorted(values []int64) error {
+	if err := checkSortedValues(values); err != nil {
+		return err
+	}
+
+	t.Cleanup()
+	for _, value := range values {
+		t.appendMax(value)
+	}
+	return nil
+}
+
+// appendMax вставляет ключ больше всех имеющихся: спуск идёт по
+// правому краю, поэтому поиск позиции не нужен
+func (t *BTree) appendMax(value int64) {
+	if len(t.root.Keys) == t.maxKeys() {
+		root := &BTreeNode{Children: []*BTreeNode{t.root}}
+		t.splitChild(root, 0)
+		t.root = root
+	}
+
+	node := t.root
+	for !node.isLeaf() {
+		i := len(node.Children) - 1
+		if len(node.Children[i].Keys) == t.maxKeys() {
+			t.splitChild(node, i)
+			i++
+		}
+		node = node.Children[i]
+	}
+	node.Keys = append(node.Keys, value)
+	t.size++
+}
+
+func (t *BTree) GetName() string {
+	return t.name
+}
+
+func (t *BTree) GetEngine() TreeEngine {
+	return BTREE_ENGINE
+}
+
+func (t *BTree) Size() int {
+	return t.size
+}
+
+func (t *BTree) IsEmpty() bool {
+	return t.size == 0
+}
+
+func (t *BTree) PrintInOrder() {
+	printOrderedSet(t)
+}
+
+func (t *BTree) Cleanup() {
+	t.root = &BTreeNode{}
+	t.size = 0
+}
@@ -0,0 +1,98 @@
+package dbmsgo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBTree(t *testing.T) {
+	t.Run("InvariantsUnderRandomOperations", func(t *testing.T) {
+		// Маленькая степень, чтобы чаще срабатывали деления и слияния
+		for _, degree := range []int{2, 3, 5} {
+			tree := NewBTreeWithDegree("btree", degree)
+			rng := rand.New(rand.NewSource(int64(degree)))
+
+			for i := 0; i < 5000; i++ {
+				value := rng.Int63n(400)
+				if rng.Intn(2) == 0 {
+					tree.Remove(value)
+				} else {
+					tree.Insert(value)
+				}
+				if i%250 == 0 {
+					checkBTreeInvariants(t, tree)
+				}
+			}
+			checkBTreeInvariants(t, tree)
+		}
+	})
+
+	t.Run("BuildFromSorted", func(t *testing.T) {
+		tree := NewBTreeWithDegree("btree", 2)
+		values := make([]int64, 1000)
+		for i := range values {
+			values[i] = int64(i)
+		}
+		assert.NoError(t, tree.BuildFromSorted(values))
+		checkBTreeInvariants(t, tree)
+
+		for i := 0; i < 1000; i += 3 {
+			tree.Remove(int64(i))
+		}
+		checkBTreeInvariants(t, tree)
+		assert.Equal(t, 666, tree.Size())
+	})
+
+	t.Run("MinimumDegree", func(t *testing.T) {
+		tree := NewBTreeWithDegree("btree", 0)
+		assert.Equal(t, 2, tree.degree)
+	})
+}
+
+// checkBTreeInvariants проверяет заполненность узлов, порядок ключей и
+// одинаковую глубину всех листьев
+func checkBTreeInvariants(t *testing.T, tree *BTree) {
+	leafDepth := -1
+	var walk func(node *BTreeNode, depth int, lo, hi *int64)
+	walk = func(node *BTreeNode, depth int, lo, hi *int64) {
+		assert.LessOrEqual(t, len(node.Keys), tree.maxKeys())
+		if node != tree.root {
+			assert.GreaterOrEqual(t, len(node.Keys), tree.degree-1)
+		}
+		for i, key := range node.Keys {
+			if i > 0 {
+				assert.Greater(t, key, node.Keys[i-1])
+			}
+			if lo != nil {
+				assert.Greater(t, key, *lo)
+			}
+			if hi != nil {
+				assert.Less(t, key, *hi)
+			}
+		}
+
+		if node.isLeaf() {
+			if leafDepth < 0 {
+				leafDepth = depth
+			}
+			assert.Equal(t, leafDepth, depth)
+			return
+		}
+
+		assert.Equal(t, len(node.Keys)+1, len(node.Children))
+		for i, child := range node.Children {
+			childLo, childHi := lo, hi
+			if i > 0 {
+				childLo = &node.Keys[i-1]
+			}
+			if i < len(node.Keys) {
+				childHi = &node.Keys[i]
+			}
+			walk(child, depth+1, childLo, childHi)
+		}
+	}
+	walk(tree.root, 0, nil, nil)
+	assert.Equal(t, tree.Size(), len(tree.SaveTree()))
+}
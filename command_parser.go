@@ -2,13 +2,19 @@ package dbmsgo
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 type CommandParser struct {
 	db     *Database
 	fileIO *FileIO
+
+	// Команды и фоновая очистка просроченных ключей не выполняются одновременно
+	mu sync.Mutex
 }
 
 func NewCommandParser(db *Database) *CommandParser {
@@ -19,7 +25,37 @@ func NewCommandParser(db *Database) *CommandParser {
 }
 
 
+// StartExpirySweeper запускает фоновое удаление ключей хеш-таблиц с
+// истёкшим сроком жизни. Возвращает функцию остановки.
+func (p *CommandParser) StartExpirySweeper(interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				p.mu.Lock()
+				p.db.SweepExpired()
+				p.mu.Unlock()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+	}
+}
+
 func (p *CommandParser) ProcessCommand(command string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	parts := strings.Fields(command)
 	if len(parts) == 0 {
 		return
@@ -40,6 +76,10 @@ func (p *CommandParser) ProcessCommand(command string) {
 		p.handleMDel(parts[1:])
 	case "MREPLACE":
 		p.handleMReplace(parts[1:])
+	case "MINCR":
+		p.handleMIncr(parts[1:])
+	case "MCAS":
+		p.handleMCas(parts[1:])
 	case "MLENGTH":
 		p.handleMLength(parts[1:])
 	case "FPUSH_FRONT":
@@ -58,6 +98,24 @@ func (p *CommandParser) ProcessCommand(command string) {
 		p.handleFDelValue(parts[1:])
 	case "FGET":
 		p.handleFGet(parts[1:])
+	case "FPOS":
+		p.handleFPos(parts[1:])
+	case "FLEN":
+		p.handleFLen(parts[1:])
+	case "FINDEX":
+		p.handleFIndex(parts[1:])
+	case "FSET":
+		p.handleFSet(parts[1:])
+	case "FINSERT_AT":
+		p.handleFInsertAt(parts[1:])
+	case "FDEL_AT":
+		p.handleFDelAt(parts[1:])
+	case "FSORT":
+		p.handleFSort(parts[1:])
+	case "FREVERSE":
+		p.handleFReverse(parts[1:])
+	case "FUNIQ":
+		p.handleFUniq(parts[1:])
 	case "LPUSH_FRONT":
 		p.handleLPushFront(parts[1:])
 	case "LPUSH_BACK":
@@ -74,6 +132,28 @@ func (p *CommandParser) ProcessCommand(command string) {
 		p.handleLDelValue(parts[1:])
 	case "LGET":
 		p.handleLGet(parts[1:])
+	case "LPOS":
+		p.handleLPos(parts[1:])
+	case "LLEN":
+		p.handleLLen(parts[1:])
+	case "LINDEX":
+		p.handleLIndex(parts[1:])
+	case "LSET":
+		p.handleLSet(parts[1:])
+	case "LINSERT_AT":
+		p.handleLInsertAt(parts[1:])
+	case "LDEL_AT":
+		p.handleLDelAt(parts[1:])
+	case "LSORT":
+		p.handleLSort(parts[1:])
+	case "LREVERSE":
+		p.handleLReverse(parts[1:])
+	case "LUNIQ":
+		p.handleLUniq(parts[1:])
+	case "LSPLICE":
+		p.handleLSplice(parts[1:])
+	case "LCONCAT":
+		p.handleLConcat(parts[1:])
 	case "SPUSH":
 		p.handleSPush(parts[1:])
 	case "SPOP":
@@ -92,6 +172,44 @@ func (p *CommandParser) ProcessCommand(command string) {
 		p.handleTDel(parts[1:])
 	case "TGET":
 		p.handleTGet(parts[1:])
+	case "TRANGE":
+		p.handleTRange(parts[1:])
+	case "TFLOOR":
+		p.handleTreeBound(parts[1:], (*AVLTree).FloorKey)
+	case "TCEIL":
+		p.handleTreeBound(parts[1:], (*AVLTree).CeilKey)
+	case "TPREV":
+		p.handleTreeBound(parts[1:], (*AVLTree).PrevKey)
+	case "TNEXT":
+		p.handleTreeBound(parts[1:], (*AVLTree).NextKey)
+	case "TMIN":
+		p.handleTreeExtreme(parts[1:], (*AVLTree).Min)
+	case "TMAX":
+		p.handleTreeExtreme(parts[1:], (*AVLTree).Max)
+	case "TRANK":
+		p.handleTRank(parts[1:])
+	case "TSELECT":
+		p.handleTSelect(parts[1:])
+	case "TCOUNT":
+		p.handleTCount(parts[1:])
+	case "TSIZE":
+		p.handleTSize(parts[1:])
+	case "TTRAVERSE":
+		p.handleTTraverse(parts[1:])
+	case "TSHOW":
+		p.handleTShow(parts[1:])
+	case "TSNAPSHOT":
+		p.handleTSnapshot(parts[1:])
+	case "TUNION":
+		p.handleTreeSetOperation(parts[1:], (*AVLTree).Union)
+	case "TINTERSECT":
+		p.handleTreeSetOperation(parts[1:], (*AVLTree).Intersect)
+	case "TDIFF":
+		p.handleTreeSetOperation(parts[1:], (*AVLTree).Difference)
+	case "TSPLIT":
+		p.handleTSplit(parts[1:])
+	case "TJOIN":
+		p.handleTJoin(parts[1:])
 	case "HINSERT":
 		p.handleHInsert(parts[1:])
 	case "HGET":
@@ -100,6 +218,77 @@ func (p *CommandParser) ProcessCommand(command string) {
 		p.handleHDel(parts[1:])
 	case "HSIZE":
 		p.handleHSize(parts[1:])
+	case "HINCRBY":
+		p.handleHIncrBy(parts[1:])
+	case "HINCRBYFLOAT":
+		p.handleHIncrByFloat(parts[1:])
+	case "HSETNX":
+		p.handleHSetNX(parts[1:])
+	case "HCAS":
+		p.handleHCas(parts[1:])
+	case "HEXPIRE":
+		p.handleHExpire(parts[1:])
+	case "HTTL":
+		p.handleHTTL(parts[1:])
+	case "HPERSIST":
+		p.handleHPersist(parts[1:])
+	case "HSTATS":
+		p.handleHStats(parts[1:])
+	case "HKEYS":
+		p.handleHashEntries(parts[1:], func(entry *HashEntry) { fmt.Println(entry.Key) })
+	case "HVALS":
+		p.handleHashEntries(parts[1:], func(entry *HashEntry) { fmt.Println(entry.Value) })
+	case "HGETALL":
+		p.handleHashEntries(parts[1:], func(entry *HashEntry) { fmt.Printf("%s %s\n", entry.Key, entry.Value) })
+	case "HSCAN":
+		p.handleHScan(parts[1:])
+	case "QMOVE":
+		p.handleMove(parts[1:], p.db.MoveQueue)
+	case "QTOS":
+		p.handleMove(parts[1:], p.db.MoveQueueToStack)
+	case "STOQ":
+		p.handleMove(parts[1:], p.db.MoveStackToQueue)
+	case "LMOVE":
+		p.handleListMove(parts[1:], p.db.MoveDLL)
+	case "FTOL":
+		p.handleListMove(parts[1:], p.db.MoveSLLToDLL)
+	case "MRANGE":
+		p.handleRange(parts[1:], func(name string) func(int, int) []string {
+			if arr := p.db.FindArray(name); arr != nil {
+				return arr.Range
+			}
+			return nil
+		})
+	case "FRANGE":
+		p.handleRange(parts[1:], func(name string) func(int, int) []string {
+			if sll := p.db.FindSLL(name); sll != nil {
+				return sll.Range
+			}
+			return nil
+		})
+	case "LRANGE":
+		p.handleRange(parts[1:], func(name string) func(int, int) []string {
+			if dll := p.db.FindDLL(name); dll != nil {
+				return dll.Range
+			}
+			return nil
+		})
+	case "SRANGE":
+		p.handleRange(parts[1:], func(name string) func(int, int) []string {
+			if stack := p.db.FindStack(name); stack != nil {
+				return stack.Range
+			}
+			return nil
+		})
+	case "QRANGE":
+		p.handleRange(parts[1:], func(name string) func(int, int) []string {
+			if queue := p.db.FindQueue(name); queue != nil {
+				return queue.Range
+			}
+			return nil
+		})
+	case "CONVERT":
+		p.handleConvert(parts[1:])
 	case "PRINT":
 		p.handlePrint(parts[1:])
 	case "SAVE":
@@ -154,11 +343,44 @@ func (p *CommandParser) handleCreate(parts []string) {
 		p.db.AddQueue(queue)
 		fmt.Printf("Очередь '%s' создан.\n", name)
 	case "TREE":
-		tree := NewAVLTree(name)
+		engine, options, err := p.parseTreeEngine(parts[2:])
+		if err != nil {
+			fmt.Printf("Ошибка: %v\n", err)
+			return
+		}
+		if engine == DISK_ENGINE {
+			tree, err := p.openDiskTree(name, options)
+			if err != nil {
+				fmt.Printf("Ошибка: %v\n", err)
+				return
+			}
+			p.db.AddOrderedSet(tree)
+			fmt.Printf("Дерево '%s' создан.\n", name)
+			return
+		}
+		if engine != AVL_ENGINE {
+			p.db.AddOrderedSet(NewOrderedSet(name, engine))
+			fmt.Printf("Дерево '%s' создан.\n", name)
+			return
+		}
+		tree, err := p.newTree(name, options)
+		if err != nil {
+			fmt.Printf("Ошибка: %v\n", err)
+			return
+		}
 		p.db.AddTree(tree)
 		fmt.Printf("Дерево '%s' создан.\n", name)
 	case "HASH":
-		table := NewHashTable(name)
+		opts, err := p.parseHashOptions(parts[2:])
+		if err != nil {
+			fmt.Printf("Ошибка: %v\n", err)
+			return
+		}
+		table, err := NewHashTableWithOptions(name, opts)
+		if err != nil {
+			fmt.Printf("Ошибка: %v\n", err)
+			return
+		}
 		p.db.AddHashTable(table)
 		fmt.Printf("Хеш-таблица '%s' создан.\n", name)
 	default:
@@ -166,6 +388,141 @@ func (p *CommandParser) handleCreate(parts []string) {
 	}
 }
 
+// parseTreeEngine извлекает ENGINE=AVL|RBTREE|BTREE|SKIPLIST|DISK из параметров
+// CREATE TREE и возвращает остальные параметры. Движки, кроме AVL,
+// хранят только множества целых чисел, дисковому нужен FILE=<путь>.
+func (p *CommandParser) parseTreeEngine(options []string) (TreeEngine, []string, error) {
+	engine := AVL_ENGINE
+	rest := make([]string, 0, len(options))
+	for _, option := range options {
+		key, value, _ := strings.Cut(option, "=")
+		if key != "ENGINE" {
+			rest = append(rest, option)
+			continue
+		}
+		parsed, err := ParseTreeEngine(value)
+		if err != nil {
+			return engine, nil, fmt.Errorf("неизвестный движок '%s'", value)
+		}
+		engine = parsed
+	}
+
+	if engine != AVL_ENGINE {
+		for _, option := range rest {
+			if option != "KEYS=INT" && !(engine == DISK_ENGINE && strings.HasPrefix(option, "FILE=")) {
+				return engine, nil, fmt.Errorf("движок %s не поддерживает параметр '%s'", engine, option)
+			}
+		}
+	}
+	return engine, rest, nil
+}
+
+// openDiskTree открывает дисковое дерево из FILE=<путь>, по умолчанию <name>.db
+func (p *CommandParser) openDiskTree(name string, options []string) (*DiskBPlusTree, error) {
+	path := name + ".db"
+	for _, option := range options {
+		if value, ok := strings.CutPrefix(option, "FILE="); ok && value != "" {
+			path = value
+		}
+	}
+	return OpenDiskBPlusTree(name, path)
+}
+
+// newTree создаёт дерево по параметрам CREATE TREE:
+// KEYS=INT|BIGINT|STRING, ORDER=LEX|NUM (для STRING) и MULTISET.
+func (p *CommandParser) newTree(name string, options []string) (*AVLTree, error) {
+	mode := INT_KEYS
+	ordering := LEX_ORDER
+	multiset := false
+	for _, option := range options {
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "KEYS":
+			parsed, err := ParseTreeKeyMode(value)
+			if err != nil {
+				return nil, fmt.Errorf("неизвестный тип ключей '%s'", value)
+			}
+			mode = parsed
+		case "MULTISET":
+			multiset = true
+		case "ORDER":
+			parsed, err := ParseTreeOrdering(value)
+			if err != nil {
+				return nil, fmt.Errorf("неизвестный порядок '%s'", value)
+			}
+			ordering = parsed
+		default:
+			return nil, fmt.Errorf("неизвестный параметр '%s'", option)
+		}
+	}
+
+	if mode == STRING_KEYS {
+		if multiset {
+			return nil, fmt.Errorf("словарь не может быть мультимножеством")
+		}
+		return NewOrderedMap(name, ordering), nil
+	}
+	if multiset {
+		return NewMultisetTree(name, mode), nil
+	}
+	return NewAVLTreeWithMode(name, mode), nil
+}
+
+// parseHashOptions разбирает параметры CREATE HASH: CAPACITY=<n>,
+// LOADFACTOR=<x>, SHRINK=<x>, ORDERED, HASH=SIPHASH|FNV1A|DJB2, SEED=<n>
+// и ENGINE=CHAINING|ROBINHOOD.
+func (p *CommandParser) parseHashOptions(options []string) (HashTableOptions, error) {
+	// Незаданные параметры остаются нулевыми, их выбирает
+	// NewHashTableWithOptions с учётом движка
+	var opts HashTableOptions
+	for _, option := range options {
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "CAPACITY":
+			capacity, err := strconv.Atoi(value)
+			if err != nil || capacity < 1 {
+				return opts, fmt.Errorf("некорректная ёмкость '%s'", value)
+			}
+			opts.Capacity = capacity
+		case "LOADFACTOR":
+			loadFactor, err := strconv.ParseFloat(value, 64)
+			if err != nil || loadFactor <= 0 {
+				return opts, fmt.Errorf("некорректный коэффициент заполнения '%s'", value)
+			}
+			opts.LoadFactor = loadFactor
+		case "SHRINK":
+			shrinkFactor, err := strconv.ParseFloat(value, 64)
+			if err != nil || shrinkFactor <= 0 {
+				return opts, fmt.Errorf("некорректный порог сжатия '%s'", value)
+			}
+			opts.ShrinkFactor = shrinkFactor
+		case "ORDERED":
+			opts.PreserveOrder = true
+		case "HASH":
+			kind, err := ParseHashKind(value)
+			if err != nil {
+				return opts, fmt.Errorf("неизвестная хеш-функция '%s'", value)
+			}
+			opts.Hash = kind
+		case "SEED":
+			seed, err := strconv.ParseUint(value, 10, 64)
+			if err != nil || seed == 0 {
+				return opts, fmt.Errorf("некорректный ключ хеширования '%s'", value)
+			}
+			opts.Seed = seed
+		case "ENGINE":
+			engine, err := ParseHashEngine(value)
+			if err != nil {
+				return opts, fmt.Errorf("неизвестный движок хеш-таблицы '%s'", value)
+			}
+			opts.Engine = engine
+		default:
+			return opts, fmt.Errorf("неизвестный параметр '%s'", option)
+		}
+	}
+	return opts, nil
+}
+
 func (p *CommandParser) handleMPush(parts []string) {
 	if len(parts) < 2 {
 		fmt.Println("FALSE")
@@ -296,6 +653,67 @@ func (p *CommandParser) handleMReplace(parts []string) {
 	fmt.Println(value)
 }
 
+// handleMIncr обрабатывает MINCR <name> <index> [delta], по умолчанию delta = 1
+func (p *CommandParser) handleMIncr(parts []string) {
+	if len(parts) < 2 || len(parts) > 3 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	index, err := strconv.Atoi(parts[1])
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+	delta := int64(1)
+	if len(parts) == 3 {
+		if delta, err = strconv.ParseInt(parts[2], 10, 64); err != nil {
+			fmt.Printf("Ошибка: некорректное приращение '%s'\n", parts[2])
+			return
+		}
+	}
+
+	arr := p.db.FindArray(parts[0])
+	if arr == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	result, err := arr.IncrBy(index, delta)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+	fmt.Println(result)
+}
+
+// handleMCas обрабатывает MCAS <name> <index> <old> <new>
+func (p *CommandParser) handleMCas(parts []string) {
+	if len(parts) != 4 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	index, err := strconv.Atoi(parts[1])
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	arr := p.db.FindArray(parts[0])
+	if arr == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	swapped, err := arr.CompareAndSwap(index, parts[2], parts[3])
+	if err != nil || !swapped {
+		fmt.Println("FALSE")
+		return
+	}
+	fmt.Println("TRUE")
+}
+
 func (p *CommandParser) handleMLength(parts []string) {
 	if len(parts) < 1 {
 		fmt.Println("FALSE")
@@ -427,14 +845,25 @@ func (p *CommandParser) handleFDelBack(parts []string) {
 	fmt.Println("TRUE")
 }
 
+// handleFDelValue обрабатывает FDEL_VALUE <name> <value> [count] и
+// печатает число удалённых элементов. Без count удаляются все совпадения.
 func (p *CommandParser) handleFDelValue(parts []string) {
-	if len(parts) < 2 {
+	if len(parts) < 2 || len(parts) > 3 {
 		fmt.Println("FALSE")
 		return
 	}
 
 	name := parts[0]
 	value := parts[1]
+	count := 0
+	if len(parts) == 3 {
+		parsed, err := strconv.Atoi(parts[2])
+		if err != nil {
+			fmt.Println("FALSE")
+			return
+		}
+		count = parsed
+	}
 
 	sll := p.db.FindSLL(name)
 	if sll == nil {
@@ -442,8 +871,37 @@ func (p *CommandParser) handleFDelValue(parts []string) {
 		return
 	}
 
-	sll.DeleteByValue(value)
-	fmt.Println("TRUE")
+	fmt.Println(sll.RemoveValue(value, count))
+}
+
+// handleFPos обрабатывает FPOS <name> <value> [LAST] и печатает номер
+// первого (LAST - последнего) совпадения или FALSE
+func (p *CommandParser) handleFPos(parts []string) {
+	if len(parts) < 2 || len(parts) > 3 {
+		fmt.Println("FALSE")
+		return
+	}
+	last := len(parts) == 3
+	if last && strings.ToUpper(parts[2]) != "LAST" {
+		fmt.Println("FALSE")
+		return
+	}
+
+	sll := p.db.FindSLL(parts[0])
+	if sll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	index := sll.IndexOf(parts[1])
+	if last {
+		index = sll.LastIndexOf(parts[1])
+	}
+	if index < 0 {
+		fmt.Println("FALSE")
+		return
+	}
+	fmt.Println(index)
 }
 
 func (p *CommandParser) handleFGet(parts []string) {
@@ -469,228 +927,196 @@ func (p *CommandParser) handleFGet(parts []string) {
 	}
 }
 
-func (p *CommandParser) handleLPushFront(parts []string) {
-	if len(parts) < 2 {
+func (p *CommandParser) handleFLen(parts []string) {
+	if len(parts) < 1 {
 		fmt.Println("FALSE")
 		return
 	}
 
-	name := parts[0]
-	value := parts[1]
-
-	dll := p.db.FindDLL(name)
-	if dll == nil {
+	sll := p.db.FindSLL(parts[0])
+	if sll == nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	dll.PushFront(value)
-	fmt.Println(value)
+	fmt.Println(sll.Length())
 }
 
-func (p *CommandParser) handleLPushBack(parts []string) {
+func (p *CommandParser) handleFIndex(parts []string) {
 	if len(parts) < 2 {
 		fmt.Println("FALSE")
 		return
 	}
 
 	name := parts[0]
-	value := parts[1]
-
-	dll := p.db.FindDLL(name)
-	if dll == nil {
+	index, err := strconv.Atoi(parts[1])
+	if err != nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	dll.PushBack(value)
-	fmt.Println(value)
-}
-
-func (p *CommandParser) handleLInsertBefore(parts []string) {
-	if len(parts) < 3 {
+	sll := p.db.FindSLL(name)
+	if sll == nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	name := parts[0]
-	target := parts[1]
-	value := parts[2]
-
-	dll := p.db.FindDLL(name)
-	if dll == nil {
+	value, err := sll.Get(index)
+	if err != nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	dll.InsertBefore(target, value)
 	fmt.Println(value)
 }
 
-func (p *CommandParser) handleLInsertAfter(parts []string) {
+func (p *CommandParser) handleFSet(parts []string) {
 	if len(parts) < 3 {
 		fmt.Println("FALSE")
 		return
 	}
 
 	name := parts[0]
-	target := parts[1]
-	value := parts[2]
-
-	dll := p.db.FindDLL(name)
-	if dll == nil {
+	index, err := strconv.Atoi(parts[1])
+	if err != nil {
 		fmt.Println("FALSE")
 		return
 	}
+	value := parts[2]
 
-	dll.InsertAfter(target, value)
-	fmt.Println(value)
-}
-
-func (p *CommandParser) handleLDelFront(parts []string) {
-	if len(parts) < 1 {
+	sll := p.db.FindSLL(name)
+	if sll == nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	name := parts[0]
-
-	dll := p.db.FindDLL(name)
-	if dll == nil {
+	if err := sll.Set(index, value); err != nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	dll.DeleteFront()
-	fmt.Println("TRUE")
+	fmt.Println(value)
 }
 
-func (p *CommandParser) handleLDelBack(parts []string) {
-	if len(parts) < 1 {
+func (p *CommandParser) handleFInsertAt(parts []string) {
+	if len(parts) < 3 {
 		fmt.Println("FALSE")
 		return
 	}
 
 	name := parts[0]
-
-	dll := p.db.FindDLL(name)
-	if dll == nil {
+	index, err := strconv.Atoi(parts[1])
+	if err != nil {
 		fmt.Println("FALSE")
 		return
 	}
+	value := parts[2]
 
-	dll.DeleteBack()
-	fmt.Println("TRUE")
-}
-
-func (p *CommandParser) handleLDelValue(parts []string) {
-	if len(parts) < 2 {
+	sll := p.db.FindSLL(name)
+	if sll == nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	name := parts[0]
-	value := parts[1]
-
-	dll := p.db.FindDLL(name)
-	if dll == nil {
+	if err := sll.InsertAt(index, value); err != nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	dll.DeleteByValue(value)
-	fmt.Println("TRUE")
+	fmt.Println(value)
 }
 
-func (p *CommandParser) handleLGet(parts []string) {
+// handleFDelAt удаляет элемент по номеру и печатает удалённое значение
+func (p *CommandParser) handleFDelAt(parts []string) {
 	if len(parts) < 2 {
 		fmt.Println("FALSE")
 		return
 	}
 
 	name := parts[0]
-	value := parts[1]
-
-	dll := p.db.FindDLL(name)
-	if dll == nil {
+	index, err := strconv.Atoi(parts[1])
+	if err != nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	node := dll.FindByValue(value)
-	if node != nil {
-		fmt.Println("TRUE")
-	} else {
+	sll := p.db.FindSLL(name)
+	if sll == nil {
 		fmt.Println("FALSE")
+		return
 	}
-}
 
-func (p *CommandParser) handleSPush(parts []string) {
-	if len(parts) < 2 {
+	value, err := sll.RemoveAt(index)
+	if err != nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	name := parts[0]
-	value := parts[1]
+	fmt.Println(value)
+}
 
-	stack := p.db.FindStack(name)
-	if stack == nil {
+// handleFSort обрабатывает FSORT <name> [ORDER=LEX|NUM]
+func (p *CommandParser) handleFSort(parts []string) {
+	if len(parts) < 1 || len(parts) > 2 {
 		fmt.Println("FALSE")
 		return
 	}
 
-	stack.Push(value)
-	fmt.Println(value)
-}
+	compare, err := parseSortOrder(parts[1:])
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
 
-func (p *CommandParser) handleSPop(parts []string) {
-	if len(parts) < 1 {
+	sll := p.db.FindSLL(parts[0])
+	if sll == nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	name := parts[0]
+	sll.Sort(compare)
+	fmt.Println("TRUE")
+}
 
-	stack := p.db.FindStack(name)
-	if stack == nil {
+func (p *CommandParser) handleFReverse(parts []string) {
+	if len(parts) < 1 {
 		fmt.Println("FALSE")
 		return
 	}
 
-	value, err := stack.Pop()
-	if err != nil {
+	sll := p.db.FindSLL(parts[0])
+	if sll == nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	fmt.Println(value)
+	sll.Reverse()
+	fmt.Println("TRUE")
 }
 
-func (p *CommandParser) handleSPeek(parts []string) {
-	if len(parts) < 1 {
+// handleFUniq обрабатывает FUNIQ <name> [ALL] и печатает число
+// удалённых элементов
+func (p *CommandParser) handleFUniq(parts []string) {
+	if len(parts) < 1 || len(parts) > 2 {
 		fmt.Println("FALSE")
 		return
 	}
-
-	name := parts[0]
-
-	stack := p.db.FindStack(name)
-	if stack == nil {
+	all := len(parts) == 2
+	if all && strings.ToUpper(parts[1]) != "ALL" {
 		fmt.Println("FALSE")
 		return
 	}
 
-	value, err := stack.Peek()
-	if err != nil {
+	sll := p.db.FindSLL(parts[0])
+	if sll == nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	fmt.Println(value)
+	fmt.Println(sll.Unique(all))
 }
 
-func (p *CommandParser) handleQPush(parts []string) {
+func (p *CommandParser) handleLPushFront(parts []string) {
 	if len(parts) < 2 {
 		fmt.Println("FALSE")
 		return
@@ -699,40 +1125,770 @@ func (p *CommandParser) handleQPush(parts []string) {
 	name := parts[0]
 	value := parts[1]
 
-	queue := p.db.FindQueue(name)
-	if queue == nil {
+	dll := p.db.FindDLL(name)
+	if dll == nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	queue.Push(value)
+	dll.PushFront(value)
 	fmt.Println(value)
 }
 
-func (p *CommandParser) handleQPop(parts []string) {
-	if len(parts) < 1 {
+func (p *CommandParser) handleLPushBack(parts []string) {
+	if len(parts) < 2 {
 		fmt.Println("FALSE")
 		return
 	}
 
 	name := parts[0]
+	value := parts[1]
 
-	queue := p.db.FindQueue(name)
-	if queue == nil {
-		fmt.Println("FALSE")
+	dll := p.db.FindDLL(name)
+	if dll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	dll.PushBack(value)
+	fmt.Println(value)
+}
+
+func (p *CommandParser) handleLInsertBefore(parts []string) {
+	if len(parts) < 3 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	target := parts[1]
+	value := parts[2]
+
+	dll := p.db.FindDLL(name)
+	if dll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	dll.InsertBefore(target, value)
+	fmt.Println(value)
+}
+
+func (p *CommandParser) handleLInsertAfter(parts []string) {
+	if len(parts) < 3 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	target := parts[1]
+	value := parts[2]
+
+	dll := p.db.FindDLL(name)
+	if dll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	dll.InsertAfter(target, value)
+	fmt.Println(value)
+}
+
+func (p *CommandParser) handleLDelFront(parts []string) {
+	if len(parts) < 1 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+
+	dll := p.db.FindDLL(name)
+	if dll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	dll.DeleteFront()
+	fmt.Println("TRUE")
+}
+
+func (p *CommandParser) handleLDelBack(parts []string) {
+	if len(parts) < 1 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+
+	dll := p.db.FindDLL(name)
+	if dll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	dll.DeleteBack()
+	fmt.Println("TRUE")
+}
+
+// handleLDelValue обрабатывает LDEL_VALUE <name> <value> [count] и
+// печатает число удалённых элементов. Без count удаляются все совпадения.
+func (p *CommandParser) handleLDelValue(parts []string) {
+	if len(parts) < 2 || len(parts) > 3 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	value := parts[1]
+	count := 0
+	if len(parts) == 3 {
+		parsed, err := strconv.Atoi(parts[2])
+		if err != nil {
+			fmt.Println("FALSE")
+			return
+		}
+		count = parsed
+	}
+
+	dll := p.db.FindDLL(name)
+	if dll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	fmt.Println(dll.RemoveValue(value, count))
+}
+
+// handleLPos обрабатывает LPOS <name> <value> [LAST] и печатает номер
+// первого (LAST - последнего) совпадения или FALSE
+func (p *CommandParser) handleLPos(parts []string) {
+	if len(parts) < 2 || len(parts) > 3 {
+		fmt.Println("FALSE")
+		return
+	}
+	last := len(parts) == 3
+	if last && strings.ToUpper(parts[2]) != "LAST" {
+		fmt.Println("FALSE")
+		return
+	}
+
+	dll := p.db.FindDLL(parts[0])
+	if dll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	index := dll.IndexOf(parts[1])
+	if last {
+		index = dll.LastIndexOf(parts[1])
+	}
+	if index < 0 {
+		fmt.Println("FALSE")
+		return
+	}
+	fmt.Println(index)
+}
+
+func (p *CommandParser) handleLGet(parts []string) {
+	if len(parts) < 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	value := parts[1]
+
+	dll := p.db.FindDLL(name)
+	if dll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	node := dll.FindByValue(value)
+	if node != nil {
+		fmt.Println("TRUE")
+	} else {
+		fmt.Println("FALSE")
+	}
+}
+
+func (p *CommandParser) handleLLen(parts []string) {
+	if len(parts) < 1 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	dll := p.db.FindDLL(parts[0])
+	if dll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	fmt.Println(dll.Length())
+}
+
+func (p *CommandParser) handleLIndex(parts []string) {
+	if len(parts) < 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	index, err := strconv.Atoi(parts[1])
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	dll := p.db.FindDLL(name)
+	if dll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	value, err := dll.Get(index)
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	fmt.Println(value)
+}
+
+func (p *CommandParser) handleLSet(parts []string) {
+	if len(parts) < 3 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	index, err := strconv.Atoi(parts[1])
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+	value := parts[2]
+
+	dll := p.db.FindDLL(name)
+	if dll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	if err := dll.Set(index, value); err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	fmt.Println(value)
+}
+
+func (p *CommandParser) handleLInsertAt(parts []string) {
+	if len(parts) < 3 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	index, err := strconv.Atoi(parts[1])
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+	value := parts[2]
+
+	dll := p.db.FindDLL(name)
+	if dll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	if err := dll.InsertAt(index, value); err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	fmt.Println(value)
+}
+
+// handleLDelAt удаляет элемент по номеру и печатает удалённое значение
+func (p *CommandParser) handleLDelAt(parts []string) {
+	if len(parts) < 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	index, err := strconv.Atoi(parts[1])
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	dll := p.db.FindDLL(name)
+	if dll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	value, err := dll.RemoveAt(index)
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	fmt.Println(value)
+}
+
+// handleLSort обрабатывает LSORT <name> [ORDER=LEX|NUM]
+func (p *CommandParser) handleLSort(parts []string) {
+	if len(parts) < 1 || len(parts) > 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	compare, err := parseSortOrder(parts[1:])
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+
+	dll := p.db.FindDLL(parts[0])
+	if dll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	dll.Sort(compare)
+	fmt.Println("TRUE")
+}
+
+func (p *CommandParser) handleLReverse(parts []string) {
+	if len(parts) < 1 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	dll := p.db.FindDLL(parts[0])
+	if dll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	dll.Reverse()
+	fmt.Println("TRUE")
+}
+
+// handleLUniq обрабатывает LUNIQ <name> [ALL] и печатает число
+// удалённых элементов
+func (p *CommandParser) handleLUniq(parts []string) {
+	if len(parts) < 1 || len(parts) > 2 {
+		fmt.Println("FALSE")
+		return
+	}
+	all := len(parts) == 2
+	if all && strings.ToUpper(parts[1]) != "ALL" {
+		fmt.Println("FALSE")
+		return
+	}
+
+	dll := p.db.FindDLL(parts[0])
+	if dll == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	fmt.Println(dll.Unique(all))
+}
+
+// handleLSplice обрабатывает LSPLICE <src> <start> <stop> <dst> <index> и
+// печатает число перенесённых элементов
+func (p *CommandParser) handleLSplice(parts []string) {
+	if len(parts) != 5 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	start, err := strconv.Atoi(parts[1])
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+	stop, err := strconv.Atoi(parts[2])
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+	index, err := strconv.Atoi(parts[4])
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	src := p.db.FindDLL(parts[0])
+	dst := p.db.FindDLL(parts[3])
+	if src == nil || dst == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	moved, err := src.Splice(start, stop, dst, index)
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+	fmt.Println(moved)
+}
+
+// handleLConcat обрабатывает LCONCAT <dst> <src>: элементы src переносятся
+// в конец dst, печатается новая длина dst
+func (p *CommandParser) handleLConcat(parts []string) {
+	if len(parts) != 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	dst := p.db.FindDLL(parts[0])
+	src := p.db.FindDLL(parts[1])
+	if dst == nil || src == nil || dst == src {
+		fmt.Println("FALSE")
+		return
+	}
+
+	dst.Concat(src)
+	fmt.Println(dst.Length())
+}
+
+func (p *CommandParser) handleSPush(parts []string) {
+	if len(parts) < 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	value := parts[1]
+
+	stack := p.db.FindStack(name)
+	if stack == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	stack.Push(value)
+	fmt.Println(value)
+}
+
+func (p *CommandParser) handleSPop(parts []string) {
+	if len(parts) < 1 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+
+	stack := p.db.FindStack(name)
+	if stack == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	value, err := stack.Pop()
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	fmt.Println(value)
+}
+
+func (p *CommandParser) handleSPeek(parts []string) {
+	if len(parts) < 1 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+
+	stack := p.db.FindStack(name)
+	if stack == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	value, err := stack.Peek()
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	fmt.Println(value)
+}
+
+func (p *CommandParser) handleQPush(parts []string) {
+	if len(parts) < 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	value := parts[1]
+
+	queue := p.db.FindQueue(name)
+	if queue == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	queue.Push(value)
+	fmt.Println(value)
+}
+
+func (p *CommandParser) handleQPop(parts []string) {
+	if len(parts) < 1 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+
+	queue := p.db.FindQueue(name)
+	if queue == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	value, err := queue.Pop()
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	fmt.Println(value)
+}
+
+func (p *CommandParser) handleQPeek(parts []string) {
+	if len(parts) < 1 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+
+	queue := p.db.FindQueue(name)
+	if queue == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	value, err := queue.Peek()
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	fmt.Println(value)
+}
+
+func (p *CommandParser) handleTInsert(parts []string) {
+	if len(parts) < 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	valueStr := parts[1]
+
+	tree := p.db.FindTree(name)
+	if tree == nil {
+		p.insertOrderedSet(name, valueStr)
 		return
 	}
 
-	value, err := queue.Pop()
+	if tree.GetMode() == STRING_KEYS {
+		if len(parts) < 3 {
+			fmt.Println("FALSE")
+			return
+		}
+		if err := tree.Put(valueStr, parts[2]); err != nil {
+			fmt.Println("FALSE")
+			return
+		}
+		fmt.Println(parts[2])
+		return
+	}
+
+	probe, err := tree.ParseKey(valueStr)
 	if err != nil {
 		fmt.Println("FALSE")
 		return
 	}
+	// Во множестве повторная вставка ничего не меняет
+	if tree.searchNode(probe) != nil && !tree.IsMultiset() {
+		fmt.Println("FALSE")
+		return
+	}
+
+	tree.insertNode(probe)
+	fmt.Println(tree.KeyString(probe))
+}
 
+// insertOrderedSet выполняет TINSERT для дерева на другом движке
+func (p *CommandParser) insertOrderedSet(name, valueStr string) {
+	set := p.db.FindOrderedSet(name)
+	value, err := strconv.ParseInt(valueStr, 10, 64)
+	if set == nil || err != nil || set.Contains(value) {
+		fmt.Println("FALSE")
+		return
+	}
+	set.Insert(value)
 	fmt.Println(value)
 }
 
-func (p *CommandParser) handleQPeek(parts []string) {
+func (p *CommandParser) handleTDel(parts []string) {
+	if len(parts) < 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	valueStr := parts[1]
+
+	tree := p.db.FindTree(name)
+	if tree == nil {
+		set := p.db.FindOrderedSet(name)
+		value, err := strconv.ParseInt(valueStr, 10, 64)
+		if set == nil || err != nil {
+			fmt.Println("FALSE")
+			return
+		}
+		set.Remove(value)
+		fmt.Println("TRUE")
+		return
+	}
+
+	remove := tree.RemoveKey
+	if len(parts) >= 3 && parts[2] == "ALL" {
+		remove = tree.RemoveAllKey
+	}
+
+	if err := remove(valueStr); err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+	fmt.Println("TRUE")
+}
+
+func (p *CommandParser) handleTGet(parts []string) {
+	if len(parts) < 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	valueStr := parts[1]
+
+	tree := p.db.FindTreeVersion(name)
+	if tree == nil {
+		set := p.db.FindOrderedSet(name)
+		value, err := strconv.ParseInt(valueStr, 10, 64)
+		if set == nil || err != nil || !set.Contains(value) {
+			fmt.Println("FALSE")
+		} else {
+			fmt.Println("TRUE")
+		}
+		return
+	}
+
+	node, err := tree.SearchKey(valueStr)
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	if node == nil {
+		fmt.Println("FALSE")
+	} else if tree.GetMode() == STRING_KEYS {
+		fmt.Println(node.Value)
+	} else {
+		fmt.Println("TRUE")
+	}
+}
+
+// printTreeNode выводит ключ узла, для словаря - ключ и значение
+func (p *CommandParser) printTreeNode(tree *AVLTree, node *AVLNode) {
+	if tree.GetMode() == STRING_KEYS {
+		fmt.Printf("%s %s\n", node.Key, node.Value)
+	} else {
+		fmt.Println(tree.KeyString(node))
+	}
+}
+
+func (p *CommandParser) handleTRange(parts []string) {
+	if len(parts) < 3 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	limit := 0
+	if len(parts) >= 5 && parts[3] == "LIMIT" {
+		var err error
+		limit, err = strconv.Atoi(parts[4])
+		if err != nil || limit < 0 {
+			fmt.Println("FALSE")
+			return
+		}
+	} else if len(parts) != 3 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	tree := p.db.FindTreeVersion(name)
+	if tree == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	nodes, err := tree.RangeKeys(parts[1], parts[2], limit)
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	if len(nodes) == 0 {
+		fmt.Println("EMPTY")
+		return
+	}
+	for _, node := range nodes {
+		p.printTreeNode(tree, node)
+	}
+}
+
+func (p *CommandParser) handleTreeBound(parts []string, bound func(*AVLTree, string) (*AVLNode, error)) {
+	if len(parts) < 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	valueStr := parts[1]
+
+	tree := p.db.FindTreeVersion(name)
+	if tree == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	node, err := bound(tree, valueStr)
+	if err != nil || node == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	p.printTreeNode(tree, node)
+}
+
+func (p *CommandParser) handleTreeExtreme(parts []string, extreme func(*AVLTree) *AVLNode) {
 	if len(parts) < 1 {
 		fmt.Println("FALSE")
 		return
@@ -740,98 +1896,307 @@ func (p *CommandParser) handleQPeek(parts []string) {
 
 	name := parts[0]
 
-	queue := p.db.FindQueue(name)
-	if queue == nil {
+	tree := p.db.FindTreeVersion(name)
+	if tree == nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	value, err := queue.Peek()
+	node := extreme(tree)
+	if node == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	p.printTreeNode(tree, node)
+}
+
+func (p *CommandParser) handleTRank(parts []string) {
+	if len(parts) < 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	valueStr := parts[1]
+
+	tree := p.db.FindTreeVersion(name)
+	if tree == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	rank, err := tree.RankKey(valueStr)
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	fmt.Println(rank)
+}
+
+func (p *CommandParser) handleTSelect(parts []string) {
+	if len(parts) < 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	k, err := strconv.Atoi(parts[1])
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	tree := p.db.FindTreeVersion(name)
+	if tree == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	node := tree.Select(k)
+	if node == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	p.printTreeNode(tree, node)
+}
+
+// handleTCount: TCOUNT <name> <value> - кратность ключа,
+// TCOUNT <name> <lo> <hi> - количество элементов в диапазоне.
+func (p *CommandParser) handleTCount(parts []string) {
+	if len(parts) < 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+
+	tree := p.db.FindTreeVersion(name)
+	if tree == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	var count int
+	var err error
+	if len(parts) == 2 {
+		count, err = tree.CountOfKey(parts[1])
+	} else {
+		count, err = tree.CountRangeKeys(parts[1], parts[2])
+	}
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	fmt.Println(count)
+}
+
+func (p *CommandParser) handleTSize(parts []string) {
+	if len(parts) < 1 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+
+	tree := p.db.FindTreeVersion(name)
+	if tree == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	fmt.Println(tree.Size())
+}
+
+func (p *CommandParser) handleTTraverse(parts []string) {
+	if len(parts) < 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	order, err := ParseTraversalOrder(parts[1])
 	if err != nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	fmt.Println(value)
+	tree := p.db.FindTreeVersion(name)
+	if tree == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	tree.PrintTraversal(order)
+}
+
+func (p *CommandParser) handleTShow(parts []string) {
+	if len(parts) < 1 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+
+	tree := p.db.FindTreeVersion(name)
+	if tree == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	tree.PrintTree()
+}
+
+func (p *CommandParser) handleTSnapshot(parts []string) {
+	if len(parts) < 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	tree := p.db.FindTree(parts[0])
+	if tree == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	if len(parts) > 2 {
+		if strings.ToUpper(parts[2]) != "DROP" {
+			fmt.Println("FALSE")
+			return
+		}
+		if tree.DropSnapshot(parts[1]) {
+			fmt.Println("TRUE")
+		} else {
+			fmt.Println("FALSE")
+		}
+		return
+	}
+
+	if err := tree.Snapshot(parts[1]); err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+	fmt.Println("TRUE")
+}
+
+// isTreeName сообщает, что под именем можно сохранить дерево: имена вида
+// name@tag зарезервированы за снимками и FindTreeVersion их не найдёт
+func isTreeName(name string) bool {
+	return name != "" && !strings.Contains(name, "@")
 }
 
-func (p *CommandParser) handleTInsert(parts []string) {
-	if len(parts) < 2 {
+// storeTree сохраняет результат под именем dst: существующее дерево
+// получает новое содержимое, иначе результат добавляется в базу.
+func (p *CommandParser) storeTree(dst string, result *AVLTree) bool {
+	if !isTreeName(dst) {
+		return false
+	}
+	existing := p.db.FindTree(dst)
+	if existing == nil {
+		p.db.AddTree(result)
+		return true
+	}
+	if !existing.compatibleWith(result) {
+		return false
+	}
+	existing.SetRoot(result.GetRoot())
+	return true
+}
+
+func (p *CommandParser) handleTreeSetOperation(parts []string, operation func(*AVLTree, string, *AVLTree) (*AVLTree, error)) {
+	if len(parts) < 3 {
 		fmt.Println("FALSE")
 		return
 	}
 
-	name := parts[0]
-	valueStr := parts[1]
-
-	value, err := strconv.Atoi(valueStr)
-	if err != nil {
+	dst := parts[0]
+	first := p.db.FindTreeVersion(parts[1])
+	second := p.db.FindTreeVersion(parts[2])
+	if first == nil || second == nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	tree := p.db.FindTree(name)
-	if tree == nil {
+	result, err := operation(first, dst, second)
+	if err != nil || !p.storeTree(dst, result) {
 		fmt.Println("FALSE")
 		return
 	}
 
-	tree.Insert(value)
-	fmt.Println(value)
+	fmt.Println(result.Size())
 }
 
-func (p *CommandParser) handleTDel(parts []string) {
-	if len(parts) < 2 {
+func (p *CommandParser) handleTSplit(parts []string) {
+	if len(parts) < 4 {
 		fmt.Println("FALSE")
 		return
 	}
 
 	name := parts[0]
-	valueStr := parts[1]
+	pivot := parts[1]
+	leftName := parts[2]
+	rightName := parts[3]
 
-	value, err := strconv.Atoi(valueStr)
-	if err != nil {
+	tree := p.db.FindTree(name)
+	if tree == nil || leftName == rightName {
 		fmt.Println("FALSE")
 		return
 	}
 
-	tree := p.db.FindTree(name)
-	if tree == nil {
+	// Целевые деревья проверяются заранее, чтобы не потерять ключи
+	for _, dst := range []string{leftName, rightName} {
+		if !isTreeName(dst) {
+			fmt.Println("FALSE")
+			return
+		}
+	}
+	for _, dst := range []string{leftName, rightName} {
+		if existing := p.db.FindTree(dst); existing != nil && !existing.compatibleWith(tree) {
+			fmt.Println("FALSE")
+			return
+		}
+	}
+
+	left, right, err := tree.SplitKey(pivot, leftName, rightName)
+	if err != nil {
 		fmt.Println("FALSE")
 		return
 	}
 
-	tree.Remove(value)
+	p.storeTree(leftName, left)
+	p.storeTree(rightName, right)
 	fmt.Println("TRUE")
 }
 
-func (p *CommandParser) handleTGet(parts []string) {
-	if len(parts) < 2 {
+func (p *CommandParser) handleTJoin(parts []string) {
+	if len(parts) < 3 {
 		fmt.Println("FALSE")
 		return
 	}
 
-	name := parts[0]
-	valueStr := parts[1]
-
-	value, err := strconv.Atoi(valueStr)
-	if err != nil {
+	dst := parts[0]
+	left := p.db.FindTree(parts[1])
+	right := p.db.FindTree(parts[2])
+	if left == nil || right == nil || left == right || !isTreeName(dst) {
 		fmt.Println("FALSE")
 		return
 	}
-
-	tree := p.db.FindTree(name)
-	if tree == nil {
+	if existing := p.db.FindTree(dst); existing != nil && !existing.compatibleWith(left) {
 		fmt.Println("FALSE")
 		return
 	}
 
-	node := tree.Search(value)
-	if node != nil {
-		fmt.Println("TRUE")
-	} else {
+	result, err := left.Join(dst, right)
+	if err != nil {
 		fmt.Println("FALSE")
+		return
 	}
+
+	p.storeTree(dst, result)
+	fmt.Println(result.Size())
 }
 
 func (p *CommandParser) handleHInsert(parts []string) {
@@ -916,6 +2281,337 @@ func (p *CommandParser) handleHSize(parts []string) {
 	fmt.Println(table.GetSize())
 }
 
+// handleHIncrBy обрабатывает HINCRBY <name> <key> <delta>
+func (p *CommandParser) handleHIncrBy(parts []string) {
+	if len(parts) != 3 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	delta, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		fmt.Printf("Ошибка: некорректное приращение '%s'\n", parts[2])
+		return
+	}
+
+	table := p.db.FindHashTable(parts[0])
+	if table == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	result, err := table.IncrBy(parts[1], delta)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+	fmt.Println(result)
+}
+
+// handleHIncrByFloat обрабатывает HINCRBYFLOAT <name> <key> <delta>
+func (p *CommandParser) handleHIncrByFloat(parts []string) {
+	if len(parts) != 3 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	delta, err := parseFloat(parts[2])
+	if err != nil {
+		fmt.Printf("Ошибка: некорректное приращение '%s'\n", parts[2])
+		return
+	}
+
+	table := p.db.FindHashTable(parts[0])
+	if table == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	result, err := table.IncrByFloat(parts[1], delta)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+	fmt.Println(formatFloat(result))
+}
+
+// handleHSetNX обрабатывает HSETNX <name> <key> <value>
+func (p *CommandParser) handleHSetNX(parts []string) {
+	if len(parts) != 3 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	table := p.db.FindHashTable(parts[0])
+	if table == nil || !table.SetNX(parts[1], parts[2]) {
+		fmt.Println("FALSE")
+		return
+	}
+	fmt.Println("TRUE")
+}
+
+// handleHCas обрабатывает HCAS <name> <key> <old> <new>
+func (p *CommandParser) handleHCas(parts []string) {
+	if len(parts) != 4 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	table := p.db.FindHashTable(parts[0])
+	if table == nil || !table.CompareAndSwap(parts[1], parts[2], parts[3]) {
+		fmt.Println("FALSE")
+		return
+	}
+	fmt.Println("TRUE")
+}
+
+// handleHExpire обрабатывает HEXPIRE <name> <key> <seconds>.
+// Неположительный срок сразу удаляет ключ.
+func (p *CommandParser) handleHExpire(parts []string) {
+	if len(parts) != 3 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	seconds, err := strconv.ParseInt(parts[2], 10, 64)
+	limit := int64(math.MaxInt64 / time.Second)
+	if err != nil || seconds > limit || seconds < -limit {
+		fmt.Printf("Ошибка: некорректный срок жизни '%s'\n", parts[2])
+		return
+	}
+
+	table := p.db.FindHashTable(parts[0])
+	if table == nil || !table.Expire(parts[1], time.Duration(seconds)*time.Second) {
+		fmt.Println("FALSE")
+		return
+	}
+	fmt.Println("TRUE")
+}
+
+// handleHTTL печатает оставшийся срок жизни ключа в секундах,
+// -1 - срок не задан, -2 - ключа нет
+func (p *CommandParser) handleHTTL(parts []string) {
+	if len(parts) != 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	table := p.db.FindHashTable(parts[0])
+	if table == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	ttl, hasTTL, found := table.TTL(parts[1])
+	switch {
+	case !found:
+		fmt.Println(-2)
+	case !hasTTL:
+		fmt.Println(-1)
+	default:
+		fmt.Println(int64((ttl + time.Second/2) / time.Second))
+	}
+}
+
+func (p *CommandParser) handleHPersist(parts []string) {
+	if len(parts) != 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	table := p.db.FindHashTable(parts[0])
+	if table == nil || !table.Persist(parts[1]) {
+		fmt.Println("FALSE")
+		return
+	}
+	fmt.Println("TRUE")
+}
+
+func (p *CommandParser) handleHStats(parts []string) {
+	if len(parts) != 1 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	table := p.db.FindHashTable(parts[0])
+	if table == nil {
+		fmt.Println("FALSE")
+		return
+	}
+	table.Stats().Print(table.GetName())
+}
+
+// handleMove обрабатывает <src> <dst> для переноса между очередями и
+// стеками: печатает перенесённое значение или FALSE
+func (p *CommandParser) handleMove(parts []string, move func(src, dst string) (string, error)) {
+	if len(parts) != 2 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	value, err := move(parts[0], parts[1])
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+	fmt.Println(value)
+}
+
+// handleListMove обрабатывает <src> <dst> <FRONT|BACK> <FRONT|BACK> для
+// переноса между списками
+func (p *CommandParser) handleListMove(parts []string, move func(src, dst string, fromEnd, toEnd ListEnd) (string, error)) {
+	if len(parts) != 4 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	fromEnd, err := ParseListEnd(strings.ToUpper(parts[2]))
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+	toEnd, err := ParseListEnd(strings.ToUpper(parts[3]))
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	value, err := move(parts[0], parts[1], fromEnd, toEnd)
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+	fmt.Println(value)
+}
+
+// parseSortOrder разбирает необязательный параметр ORDER=LEX|NUM
+func parseSortOrder(options []string) (Comparator, error) {
+	ordering := LEX_ORDER
+	for _, option := range options {
+		key, value, _ := strings.Cut(option, "=")
+		if key != "ORDER" {
+			return nil, fmt.Errorf("неизвестный параметр '%s'", option)
+		}
+		parsed, err := ParseTreeOrdering(value)
+		if err != nil {
+			return nil, fmt.Errorf("неизвестный порядок '%s'", value)
+		}
+		ordering = parsed
+	}
+	// Для сортировки равные числа остаются равными, чтобы сохранялся
+	// исходный порядок
+	if ordering == NUMERIC_ORDER {
+		return NumericOrder, nil
+	}
+	return LexicographicOrder, nil
+}
+
+// handleRange обрабатывает <name> <start> <stop> для последовательностей:
+// find возвращает метод Range структуры или nil, если её нет. Значения
+// печатаются по одному в строке, пустой диапазон - EMPTY.
+func (p *CommandParser) handleRange(parts []string, find func(name string) func(int, int) []string) {
+	if len(parts) != 3 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	start, err := strconv.Atoi(parts[1])
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+	stop, err := strconv.Atoi(parts[2])
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	rangeFunc := find(parts[0])
+	if rangeFunc == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	values := rangeFunc(start, stop)
+	if len(values) == 0 {
+		fmt.Println("EMPTY")
+		return
+	}
+	for _, value := range values {
+		fmt.Println(value)
+	}
+}
+
+// handleHashEntries печатает все записи таблицы: в порядке вставки для
+// упорядоченной таблицы и в порядке бакетов для обычной
+func (p *CommandParser) handleHashEntries(parts []string, print func(*HashEntry)) {
+	if len(parts) != 1 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	table := p.db.FindHashTable(parts[0])
+	if table == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	entries := table.Entries()
+	if len(entries) == 0 {
+		fmt.Println("EMPTY")
+		return
+	}
+	for _, entry := range entries {
+		print(entry)
+	}
+}
+
+// handleHScan обрабатывает HSCAN <name> <cursor> [MATCH <glob>] [COUNT <n>].
+// Первой строкой печатается курсор для следующего вызова, 0 - обход
+// закончен, затем найденные пары ключ-значение.
+func (p *CommandParser) handleHScan(parts []string) {
+	if len(parts) < 2 || len(parts)%2 != 0 {
+		fmt.Println("FALSE")
+		return
+	}
+
+	name := parts[0]
+	cursor, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	match, count := "", 10
+	for i := 2; i < len(parts); i += 2 {
+		switch strings.ToUpper(parts[i]) {
+		case "MATCH":
+			match = parts[i+1]
+		case "COUNT":
+			count, err = strconv.Atoi(parts[i+1])
+			if err != nil || count < 1 {
+				fmt.Println("FALSE")
+				return
+			}
+		default:
+			fmt.Println("FALSE")
+			return
+		}
+	}
+
+	table := p.db.FindHashTable(name)
+	if table == nil {
+		fmt.Println("FALSE")
+		return
+	}
+
+	next, entries := table.Scan(cursor, count, match)
+	fmt.Println(next)
+	for _, entry := range entries {
+		fmt.Printf("%s %s\n", entry.Key, entry.Value)
+	}
+}
+
 func (p *CommandParser) handlePrint(parts []string) {
 	if len(parts) < 2 {
 		fmt.Println("FALSE")
@@ -962,9 +2658,11 @@ func (p *CommandParser) handlePrint(parts []string) {
 			fmt.Println("FALSE")
 		}
 	case "TREE":
-		tree := p.db.FindTree(name)
+		tree := p.db.FindTreeVersion(name)
 		if tree != nil {
 			tree.PrintInOrder()
+		} else if set := p.db.FindOrderedSet(name); set != nil {
+			set.PrintInOrder()
 		} else {
 			fmt.Println("FALSE")
 		}
@@ -987,7 +2685,27 @@ func (p *CommandParser) handleSave(parts []string) {
 	}
 
 	filename := parts[0]
-	if err := p.fileIO.SaveDatabaseToFile(p.db, filename); err != nil {
+	encoding := p.fileIO.GetHashEncoding()
+	for _, option := range parts[1:] {
+		key, value, _ := strings.Cut(option, "=")
+		if key != "HASH" {
+			fmt.Println("FALSE")
+			return
+		}
+		parsed, err := ParseHashEncoding(strings.ToUpper(value))
+		if err != nil {
+			fmt.Println("FALSE")
+			return
+		}
+		encoding = parsed
+	}
+
+	// Формат хеш-таблиц задаётся только для этого сохранения
+	previous := p.fileIO.GetHashEncoding()
+	p.fileIO.SetHashEncoding(encoding)
+	err := p.fileIO.SaveDatabaseToFile(p.db, filename)
+	p.fileIO.SetHashEncoding(previous)
+	if err != nil {
 		fmt.Println("FALSE")
 		return
 	}
@@ -1077,47 +2795,115 @@ func (p *CommandParser) handleLoadBinary(parts []string) {
 func (p *CommandParser) handleHelp() {
 	fmt.Println("=== Доступные команды ===")
 	fmt.Println("CREATE ARRAY|SLL|DLL|STACK|QUEUE|TREE|HASH <name>")
+	fmt.Println("CREATE TREE <name> [KEYS=INT|BIGINT|STRING] [ORDER=LEX|NUM] [MULTISET] - Дерево, словарь или мультимножество")
+	fmt.Println("CREATE TREE <name> ENGINE=AVL|RBTREE|BTREE|SKIPLIST - Множество целых чисел на выбранном движке")
+	fmt.Println("CREATE TREE <name> ENGINE=DISK [FILE=<path>] - B+-дерево в файле, в памяти только нужные страницы")
+	fmt.Println("CREATE HASH <name> [CAPACITY=<n>] [LOADFACTOR=<x>] [ORDERED] - Хеш-таблица с параметрами")
+	fmt.Println("CREATE HASH <name> [ENGINE=CHAINING|ROBINHOOD] - Цепочки или открытая адресация Robin Hood")
+	fmt.Println("CREATE HASH <name> [SHRINK=<x>] - Сжимать таблицу вдвое, когда заполнение ниже порога (по умолчанию 0.1)")
+	fmt.Println("CREATE HASH <name> [HASH=SIPHASH|FNV1A|DJB2] [SEED=<n>] - Хеш-функция, по умолчанию SipHash со случайным ключом")
 	fmt.Println("MPUSH <name> <value> - Добавить в массив")
 	fmt.Println("MINSERT <name> <index> <value> - Вставить в массив")
 	fmt.Println("MDEL <name> <index> - Удалить из массива")
 	fmt.Println("MGET <name> <index> - Получить из массива")
 	fmt.Println("MREPLACE <name> <index> <value> - Заменить в массиве")
+	fmt.Println("MINCR <name> <index> [n] - Прибавить n (по умолчанию 1) к целому элементу")
+	fmt.Println("MCAS <name> <index> <old> <new> - Заменить элемент, только если он равен old")
 	fmt.Println("MLENGTH <name> - Длина массива")
+	fmt.Println("MRANGE <name> <start> <stop> - Элементы массива с start по stop, отрицательные индексы от конца")
 	fmt.Println("FPUSH_FRONT <name> <value> - Добавить в начало SLL")
 	fmt.Println("FPUSH_BACK <name> <value> - Добавить в конец SLL")
 	fmt.Println("FINSERT_BEFORE <name> <target> <value> - Вставить перед в SLL")
 	fmt.Println("FINSERT_AFTER <name> <target> <value> - Вставить после в SLL")
 	fmt.Println("FDEL_FRONT <name> - Удалить из начала SLL")
 	fmt.Println("FDEL_BACK <name> - Удалить с конца SLL")
-	fmt.Println("FDEL_VALUE <name> <value> - Удалить по значению в SLL")
+	fmt.Println("FDEL_VALUE <name> <value> [count] - Удалить первые count совпадений в SLL (count < 0 - с конца, без count - все)")
 	fmt.Println("FGET <name> <value> - Поиск в SLL")
+	fmt.Println("FPOS <name> <value> [LAST] - Номер первого (LAST - последнего) совпадения в SLL")
+	fmt.Println("FLEN <name> - Длина SLL")
+	fmt.Println("FINDEX <name> <index> - Получить элемент SLL по номеру")
+	fmt.Println("FSET <name> <index> <value> - Заменить элемент SLL по номеру")
+	fmt.Println("FINSERT_AT <name> <index> <value> - Вставить в SLL по номеру")
+	fmt.Println("FDEL_AT <name> <index> - Удалить из SLL по номеру")
+	fmt.Println("FRANGE <name> <start> <stop> - Элементы SLL с start по stop, отрицательные индексы от конца")
+	fmt.Println("FSORT <name> [ORDER=LEX|NUM] - Устойчивая сортировка SLL")
+	fmt.Println("FREVERSE <name> - Развернуть SLL")
+	fmt.Println("FUNIQ <name> [ALL] - Удалить подряд идущие (ALL - все) повторы в SLL")
 	fmt.Println("LPUSH_FRONT <name> <value> - Добавить в начало DLL")
 	fmt.Println("LPUSH_BACK <name> <value> - Добавить в конец DLL")
 	fmt.Println("LINSERT_BEFORE <name> <target> <value> - Вставить перед в DLL")
 	fmt.Println("LINSERT_AFTER <name> <target> <value> - Вставить после в DLL")
 	fmt.Println("LDEL_FRONT <name> - Удалить из начала DLL")
 	fmt.Println("LDEL_BACK <name> - Удалить с конца DLL")
-	fmt.Println("LDEL_VALUE <name> <value> - Удалить по значению в DLL")
+	fmt.Println("LDEL_VALUE <name> <value> [count] - Удалить первые count совпадений в DLL (count < 0 - с конца, без count - все)")
 	fmt.Println("LGET <name> <value> - Поиск в DLL")
+	fmt.Println("LPOS <name> <value> [LAST] - Номер первого (LAST - последнего) совпадения в DLL")
+	fmt.Println("LLEN <name> - Длина DLL")
+	fmt.Println("LINDEX <name> <index> - Получить элемент DLL по номеру")
+	fmt.Println("LSET <name> <index> <value> - Заменить элемент DLL по номеру")
+	fmt.Println("LINSERT_AT <name> <index> <value> - Вставить в DLL по номеру")
+	fmt.Println("LDEL_AT <name> <index> - Удалить из DLL по номеру")
+	fmt.Println("LRANGE <name> <start> <stop> - Элементы DLL с start по stop, отрицательные индексы от конца")
+	fmt.Println("LSORT <name> [ORDER=LEX|NUM] - Устойчивая сортировка DLL")
+	fmt.Println("LREVERSE <name> - Развернуть DLL")
+	fmt.Println("LUNIQ <name> [ALL] - Удалить подряд идущие (ALL - все) повторы в DLL")
+	fmt.Println("LSPLICE <src> <start> <stop> <dst> <index> - Перенести элементы с start по stop из src в dst на место index")
+	fmt.Println("LCONCAT <dst> <src> - Перенести все элементы src в конец dst")
 	fmt.Println("SPUSH <name> <value> - Добавить в стек")
 	fmt.Println("SPOP <name> - Извлечь из стека")
 	fmt.Println("SPEEK <name> - Посмотреть вершину стека")
+	fmt.Println("SRANGE <name> <start> <stop> - Элементы стека от вершины с start по stop")
 	fmt.Println("QPUSH <name> <value> - Добавить в очередь")
 	fmt.Println("QPOP <name> - Извлечь из очереди")
 	fmt.Println("QPEEK <name> - Посмотреть начало очереди")
+	fmt.Println("QRANGE <name> <start> <stop> - Элементы очереди от начала с start по stop")
+	fmt.Println("QMOVE <src> <dst> - Перенести начало очереди src в конец очереди dst")
+	fmt.Println("QTOS <queue> <stack> - Перенести начало очереди на вершину стека")
+	fmt.Println("STOQ <stack> <queue> - Перенести вершину стека в конец очереди")
+	fmt.Println("LMOVE <src> <dst> <FRONT|BACK> <FRONT|BACK> - Перенести элемент между концами DLL")
+	fmt.Println("FTOL <sll> <dll> <FRONT|BACK> <FRONT|BACK> - Перенести элемент из SLL в DLL")
 	fmt.Println("TINSERT <name> <value> - Добавить в дерево")
-	fmt.Println("TDEL <name> <value> - Удалить из дерева")
-	fmt.Println("TGET <name> <value> - Поиск в дереве")
+	fmt.Println("TINSERT <name> <key> <value> - Добавить пару в словарь (KEYS=STRING)")
+	fmt.Println("TDEL <name> <value> [ALL] - Удалить из дерева (ALL - все вхождения)")
+	fmt.Println("TGET <name> <value> - Поиск в дереве (для словаря - значение по ключу)")
+	fmt.Println("TRANGE <name> <lo> <hi> [LIMIT <n>] - Ключи дерева из диапазона [lo, hi]")
+	fmt.Println("TFLOOR <name> <value> - Наибольший ключ <= value")
+	fmt.Println("TCEIL <name> <value> - Наименьший ключ >= value")
+	fmt.Println("TPREV <name> <value> - Предыдущий ключ (< value)")
+	fmt.Println("TNEXT <name> <value> - Следующий ключ (> value)")
+	fmt.Println("TMIN <name> - Минимальный ключ дерева")
+	fmt.Println("TMAX <name> - Максимальный ключ дерева")
+	fmt.Println("TRANK <name> <value> - Количество ключей меньше value")
+	fmt.Println("TSELECT <name> <k> - k-й по возрастанию ключ (с нуля)")
+	fmt.Println("TCOUNT <name> <value> - Кратность ключа")
+	fmt.Println("TCOUNT <name> <lo> <hi> - Количество ключей в диапазоне [lo, hi]")
+	fmt.Println("TSIZE <name> - Количество ключей в дереве")
+	fmt.Println("TTRAVERSE <name> IN|PRE|POST|LEVEL - Обход дерева в заданном порядке")
+	fmt.Println("TSHOW <name> - Нарисовать дерево с высотами и балансами узлов")
+	fmt.Println("TSNAPSHOT <name> <tag> [DROP] - Снимок дерева, читается как <name>@<tag>")
+	fmt.Println("TUNION|TINTERSECT|TDIFF <dst> <a> <b> - Объединение, пересечение, разность деревьев")
+	fmt.Println("TSPLIT <name> <pivot> <left> <right> - Перенести ключи < pivot в left, остальные в right")
+	fmt.Println("TJOIN <dst> <left> <right> - Склеить деревья (ключи left меньше ключей right)")
 	fmt.Println("HINSERT <name> <key> <value> - Вставить в хеш-таблицу")
 	fmt.Println("HGET <name> <key> - Получить из хеш-таблицы")
 	fmt.Println("HDEL <name> <key> - Удалить из хеш-таблицы")
 	fmt.Println("HSIZE <name> - Размер хеш-таблицы")
+	fmt.Println("HINCRBY <name> <key> <n> / HINCRBYFLOAT <name> <key> <x> - Прибавить число к значению (нет ключа - 0)")
+	fmt.Println("HSETNX <name> <key> <value> - Добавить, только если ключа нет")
+	fmt.Println("HCAS <name> <key> <old> <new> - Заменить значение, только если оно равно old")
+	fmt.Println("HEXPIRE <name> <key> <seconds> - Задать срок жизни ключа")
+	fmt.Println("HTTL <name> <key> - Оставшийся срок в секундах (-1 - без срока, -2 - нет ключа)")
+	fmt.Println("HPERSIST <name> <key> - Снять срок жизни ключа")
+	fmt.Println("HSTATS <name> - Заполнение, цепочки, пустые бакеты, изменения ёмкости и память")
+	fmt.Println("HKEYS <name> / HVALS <name> / HGETALL <name> - Ключи, значения или пары ключ-значение")
+	fmt.Println("HSCAN <name> <cursor> [MATCH <glob>] [COUNT <n>] - Обход по курсору, начиная с 0; устойчив к изменению ёмкости")
+	fmt.Println("CONVERT <src> <TYPE> <dst> [FROM=<TYPE>] [FIELD=KEYS|VALUES] [параметры CREATE] - Скопировать данные в новую структуру другого типа")
 	fmt.Println("PRINT <type> <name> - Вывести структуру")
 	fmt.Println("SAVE_TEXT <filename> - Сохранить базу в текстовом формате")
 	fmt.Println("SAVE_BINARY <filename> - Сохранить базу в бинарном формате")
 	fmt.Println("LOAD_TEXT <filename> - Загрузить базу из текстового формата")
 	fmt.Println("LOAD_BINARY <filename> - Загрузить базу из бинарного формата")
-	fmt.Println("SAVE <filename> - Сохранить базу (старый формат)")
+	fmt.Println("SAVE <filename> [HASH=AUTO|ENTRIES|LAYOUT] - Сохранить базу (старый формат); ENTRIES пишет только пары, LAYOUT - раскладку всех хеш-таблиц")
 	fmt.Println("LOAD <filename> - Загрузить базу (старый формат)")
 	fmt.Println("HELP - Справка")
 	fmt.Println("EXIT - Выход")
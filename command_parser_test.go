@@ -2,9 +2,13 @@ package dbmsgo
 
 import (
 	"bytes"
+	"fmt"
+	"math"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -40,6 +44,296 @@ func TestCommandParser_CreateCommands(t *testing.T) {
 	parser.ProcessCommand("CREATE") // insufficient parameters
 }
 
+func captureOutput(f func()) string {
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	f()
+
+	w.Close()
+	os.Stdout = oldStdout
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	return strings.TrimSpace(buf.String())
+}
+
+func TestCommandParser_CreateHashWithOptions(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	parser.ProcessCommand("CREATE HASH sessions CAPACITY=32 LOADFACTOR=0.5 ORDERED")
+	table := db.FindHashTable("sessions")
+	assert.NotNil(t, table)
+	assert.Equal(t, 32, table.GetCapacity())
+	assert.Equal(t, 0.5, table.GetLoadFactor())
+	assert.True(t, table.IsOrdered())
+
+	output := captureOutput(func() {
+		parser.ProcessCommand("CREATE HASH broken LOADFACTOR=abc")
+	})
+	assert.Contains(t, output, "Ошибка")
+	assert.Nil(t, db.FindHashTable("broken"))
+
+	parser.ProcessCommand("CREATE HASH broken CAPACITY=0")
+	parser.ProcessCommand("CREATE HASH broken UNKNOWN")
+	assert.Nil(t, db.FindHashTable("broken"))
+}
+
+func TestCommandParser_BigIntTree(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	parser.ProcessCommand("CREATE TREE ids KEYS=BIGINT")
+	parser.ProcessCommand("CREATE TREE plain")
+	parser.ProcessCommand("CREATE TREE broken KEYS=FLOAT")
+	assert.Equal(t, BIGINT_KEYS, db.FindTree("ids").GetMode())
+	assert.Equal(t, INT_KEYS, db.FindTree("plain").GetMode())
+	assert.Nil(t, db.FindTree("broken"))
+
+	output := captureOutput(func() {
+		parser.ProcessCommand("TINSERT ids 340282366920938463463374607431768211456")
+	})
+	assert.Equal(t, "340282366920938463463374607431768211456", output)
+
+	output = captureOutput(func() {
+		parser.ProcessCommand("TGET ids 340282366920938463463374607431768211456")
+	})
+	assert.Equal(t, "TRUE", output)
+
+	output = captureOutput(func() {
+		parser.ProcessCommand("TINSERT plain 340282366920938463463374607431768211456")
+	})
+	assert.Equal(t, "FALSE", output)
+
+	output = captureOutput(func() {
+		parser.ProcessCommand("TINSERT plain 9000000000000000000")
+	})
+	assert.Equal(t, "9000000000000000000", output)
+
+	output = captureOutput(func() {
+		parser.ProcessCommand("TINSERT plain -9223372036854775808")
+	})
+	assert.Equal(t, "-9223372036854775808", output)
+	assert.Equal(t, []int64{math.MinInt64, 9000000000000000000}, db.FindTree("plain").SaveTree())
+
+	parser.ProcessCommand("TDEL ids 340282366920938463463374607431768211456")
+	assert.True(t, db.FindTree("ids").IsEmpty())
+}
+
+func TestCommandParser_OrderedMap(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	parser.ProcessCommand("CREATE TREE dict KEYS=STRING ORDER=NUM")
+	parser.ProcessCommand("CREATE TREE broken ORDER=RANDOM")
+	assert.Equal(t, NUMERIC_ORDER, db.FindTree("dict").GetOrdering())
+	assert.Nil(t, db.FindTree("broken"))
+
+	assert.Equal(t, "alice", captureOutput(func() {
+		parser.ProcessCommand("TINSERT dict 42 alice")
+	}))
+	assert.Equal(t, "FALSE", captureOutput(func() {
+		parser.ProcessCommand("TINSERT dict 43")
+	}))
+	assert.Equal(t, "FALSE", captureOutput(func() {
+		parser.ProcessCommand("TINSERT dict notanumber bob")
+	}))
+	assert.Equal(t, "alice", captureOutput(func() {
+		parser.ProcessCommand("TGET dict 42")
+	}))
+	assert.Equal(t, "FALSE", captureOutput(func() {
+		parser.ProcessCommand("TGET dict 7")
+	}))
+	assert.Contains(t, captureOutput(func() {
+		parser.ProcessCommand("PRINT TREE dict")
+	}), "{42: alice}")
+
+	parser.ProcessCommand("TDEL dict 42")
+	assert.True(t, db.FindTree("dict").IsEmpty())
+}
+
+func TestCommandParser_TreeRangeQueries(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	parser.ProcessCommand("CREATE TREE ts")
+	for _, v := range []string{"100", "200", "300", "400", "500"} {
+		parser.ProcessCommand("TINSERT ts " + v)
+	}
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	assert.Equal(t, "200\n300\n400", run("TRANGE ts 150 450"))
+	assert.Equal(t, "200\n300", run("TRANGE ts 150 450 LIMIT 2"))
+	assert.Equal(t, "EMPTY", run("TRANGE ts 101 199"))
+	assert.Equal(t, "FALSE", run("TRANGE ts 1 2 LIMIT x"))
+	assert.Equal(t, "FALSE", run("TRANGE ts 1 2 3"))
+	assert.Equal(t, "FALSE", run("TRANGE missing 1 2"))
+	assert.Equal(t, "300", run("TFLOOR ts 399"))
+	assert.Equal(t, "400", run("TCEIL ts 301"))
+	assert.Equal(t, "200", run("TPREV ts 300"))
+	assert.Equal(t, "400", run("TNEXT ts 300"))
+	assert.Equal(t, "FALSE", run("TNEXT ts 500"))
+	assert.Equal(t, "FALSE", run("TFLOOR ts abc"))
+	assert.Equal(t, "100", run("TMIN ts"))
+	assert.Equal(t, "500", run("TMAX ts"))
+
+	parser.ProcessCommand("CREATE TREE empty")
+	assert.Equal(t, "FALSE", run("TMIN empty"))
+	assert.Equal(t, "FALSE", run("TMAX missing"))
+
+	parser.ProcessCommand("CREATE TREE dict KEYS=STRING")
+	parser.ProcessCommand("TINSERT dict b beta")
+	parser.ProcessCommand("TINSERT dict a alpha")
+	assert.Equal(t, "a alpha\nb beta", run("TRANGE dict a z"))
+	assert.Equal(t, "b beta", run("TMAX dict"))
+}
+
+func TestCommandParser_TreeOrderStatistics(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	parser.ProcessCommand("CREATE TREE latency")
+	for i := 1; i <= 20; i++ {
+		parser.ProcessCommand(fmt.Sprintf("TINSERT latency %d", i*5))
+	}
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	assert.Equal(t, "20", run("TSIZE latency"))
+	assert.Equal(t, "4", run("TRANK latency 25"))
+	assert.Equal(t, "95", run("TSELECT latency 18"))
+	assert.Equal(t, "FALSE", run("TSELECT latency 20"))
+	assert.Equal(t, "FALSE", run("TSELECT latency x"))
+	assert.Equal(t, "3", run("TCOUNT latency 10 20"))
+	assert.Equal(t, "FALSE", run("TCOUNT latency 10 x"))
+	assert.Equal(t, "FALSE", run("TRANK latency x"))
+	assert.Equal(t, "FALSE", run("TSIZE missing"))
+}
+
+func TestCommandParser_MultisetTree(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	parser.ProcessCommand("CREATE TREE m MULTISET")
+	parser.ProcessCommand("CREATE TREE s")
+	parser.ProcessCommand("CREATE TREE broken KEYS=STRING MULTISET")
+	assert.True(t, db.FindTree("m").IsMultiset())
+	assert.Nil(t, db.FindTree("broken"))
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	assert.Equal(t, "7", run("TINSERT m 7"))
+	assert.Equal(t, "7", run("TINSERT m 7"))
+	assert.Equal(t, "7", run("TINSERT m 7"))
+	assert.Equal(t, "3", run("TCOUNT m 7"))
+	assert.Equal(t, "3", run("TCOUNT m 0 10"))
+
+	assert.Equal(t, "TRUE", run("TDEL m 7"))
+	assert.Equal(t, "2", run("TCOUNT m 7"))
+	assert.Equal(t, "TRUE", run("TDEL m 7 ALL"))
+	assert.Equal(t, "0", run("TCOUNT m 7"))
+	assert.Equal(t, "FALSE", run("TCOUNT m x"))
+
+	// Во множестве повторная вставка сообщает, что ничего не добавлено
+	assert.Equal(t, "7", run("TINSERT s 7"))
+	assert.Equal(t, "FALSE", run("TINSERT s 7"))
+	assert.Equal(t, "1", run("TCOUNT s 7"))
+}
+
+func TestCommandParser_TreeTraversalAndShow(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	parser.ProcessCommand("CREATE TREE t")
+	for _, v := range []string{"2", "1", "3"} {
+		parser.ProcessCommand("TINSERT t " + v)
+	}
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	assert.Equal(t, "Дерево 't' pre-order: 2 1 3", run("TTRAVERSE t PRE"))
+	assert.Equal(t, "Дерево 't' post-order: 1 3 2", run("TTRAVERSE t POST"))
+	assert.Equal(t, "Дерево 't' level-order: 2 1 3", run("TTRAVERSE t LEVEL"))
+	assert.Equal(t, "Дерево 't' in-order: 1 2 3", run("TTRAVERSE t IN"))
+	assert.Equal(t, "FALSE", run("TTRAVERSE t SIDEWAYS"))
+	assert.Equal(t, "FALSE", run("TTRAVERSE missing IN"))
+
+	output := run("TSHOW t")
+	assert.Contains(t, output, "2 [h=2, bf=0]")
+	assert.Contains(t, output, "L: 1 [h=1, bf=0]")
+	assert.Equal(t, "FALSE", run("TSHOW missing"))
+}
+
+func TestCommandParser_TreeSetAlgebra(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	parser.ProcessCommand("CREATE TREE a")
+	parser.ProcessCommand("CREATE TREE b")
+	for _, v := range []string{"1", "2", "3", "4"} {
+		parser.ProcessCommand("TINSERT a " + v)
+	}
+	for _, v := range []string{"3", "4", "5"} {
+		parser.ProcessCommand("TINSERT b " + v)
+	}
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	assert.Equal(t, "5", run("TUNION u a b"))
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, db.FindTree("u").SaveTree())
+	assert.Equal(t, "2", run("TINTERSECT i a b"))
+	assert.Equal(t, "2", run("TDIFF d a b"))
+	assert.Equal(t, []int64{1, 2}, db.FindTree("d").SaveTree())
+
+	// Повторная запись заменяет содержимое существующего дерева
+	assert.Equal(t, "1", run("TDIFF u b a"))
+	assert.Equal(t, []int64{5}, db.FindTree("u").SaveTree())
+
+	parser.ProcessCommand("CREATE TREE m MULTISET")
+	assert.Equal(t, "FALSE", run("TUNION x a m"))
+	assert.Equal(t, "FALSE", run("TUNION m a b"))
+	assert.Equal(t, "FALSE", run("TUNION x a missing"))
+	assert.Equal(t, "FALSE", run("TUNION x a"))
+
+	assert.Equal(t, "TRUE", run("TSPLIT a 3 low high"))
+	assert.True(t, db.FindTree("a").IsEmpty())
+	assert.Equal(t, []int64{1, 2}, db.FindTree("low").SaveTree())
+	assert.Equal(t, []int64{3, 4}, db.FindTree("high").SaveTree())
+	assert.Equal(t, "FALSE", run("TSPLIT low x l r"))
+	assert.Equal(t, "FALSE", run("TSPLIT low 1 m r"))
+
+	assert.Equal(t, "FALSE", run("TJOIN j high low"))
+	assert.Equal(t, "4", run("TJOIN a low high"))
+	assert.Equal(t, []int64{1, 2, 3, 4}, db.FindTree("a").SaveTree())
+	assert.True(t, db.FindTree("low").IsEmpty())
+	assert.Equal(t, "FALSE", run("TJOIN j a a"))
+
+	// Одинаковые приёмники потеряли бы половину ключей
+	assert.Equal(t, "FALSE", run("TSPLIT a 3 x x"))
+	assert.Equal(t, "FALSE", run("TSPLIT a 3 a a"))
+	assert.Equal(t, []int64{1, 2, 3, 4}, db.FindTree("a").SaveTree())
+	assert.Nil(t, db.FindTree("x"))
+
+	// Имена вида name@tag зарезервированы за снимками
+	assert.Equal(t, "FALSE", run("TUNION u@v a b"))
+	assert.Nil(t, db.FindTree("u@v"))
+	assert.Equal(t, "FALSE", run("TSPLIT a 3 l@v r"))
+	assert.Equal(t, 4, db.FindTree("a").Size())
+	assert.Equal(t, "FALSE", run("TJOIN j@v low a"))
+}
+
 func TestCommandParser_ArrayOperations(t *testing.T) {
 	db := NewDatabase()
 	parser := NewCommandParser(db)
@@ -407,3 +701,586 @@ func TestCommandParser_EdgeCases(t *testing.T) {
 	parser.ProcessCommand("create array lower_array")
 	assert.NotNil(t, db.FindArray("lower_array"))
 }
+
+func TestCommandParser_TreeSnapshots(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	parser.ProcessCommand("CREATE TREE cfg KEYS=STRING")
+	parser.ProcessCommand("TINSERT cfg mode fast")
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	assert.Equal(t, "TRUE", run("TSNAPSHOT cfg v1"))
+	parser.ProcessCommand("TINSERT cfg mode safe")
+	parser.ProcessCommand("TINSERT cfg level 3")
+
+	assert.Equal(t, "fast", run("TGET cfg@v1 mode"))
+	assert.Equal(t, "safe", run("TGET cfg mode"))
+	assert.Equal(t, "FALSE", run("TGET cfg@v1 level"))
+	assert.Equal(t, "1", run("TSIZE cfg@v1"))
+	assert.Equal(t, "2", run("TSIZE cfg"))
+	assert.Equal(t, "FALSE", run("TGET cfg@v2 mode"))
+
+	// Снимок доступен только для чтения
+	assert.Equal(t, "FALSE", run("TINSERT cfg@v1 mode slow"))
+	assert.Equal(t, "fast", run("TGET cfg@v1 mode"))
+
+	assert.Equal(t, "FALSE", run("TSNAPSHOT missing v1"))
+	assert.Equal(t, "FALSE", run("TSNAPSHOT cfg a@b"))
+	assert.Equal(t, "TRUE", run("TSNAPSHOT cfg v1 DROP"))
+	assert.Equal(t, "FALSE", run("TSNAPSHOT cfg v1 DROP"))
+	assert.Equal(t, "FALSE", run("TGET cfg@v1 mode"))
+}
+
+func TestCommandParser_TreeEngines(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	for _, engine := range []string{"RBTREE", "BTREE", "SKIPLIST"} {
+		name := "t_" + engine
+		assert.Equal(t, "Дерево '"+name+"' создан.", run("CREATE TREE "+name+" ENGINE="+engine))
+		assert.Equal(t, "10", run("TINSERT "+name+" 10"))
+		assert.Equal(t, "5", run("TINSERT "+name+" 5"))
+		assert.Equal(t, "FALSE", run("TINSERT "+name+" 10"))
+		assert.Equal(t, "FALSE", run("TINSERT "+name+" x"))
+		assert.Equal(t, "TRUE", run("TGET "+name+" 5"))
+		assert.Equal(t, "TRUE", run("TDEL "+name+" 5"))
+		assert.Equal(t, "FALSE", run("TGET "+name+" 5"))
+		assert.Equal(t, "Дерево '"+name+"' in-order: 10", run("PRINT TREE "+name))
+		assert.Equal(t, "FALSE", run("TDEL "+name+" x"))
+		assert.Equal(t, "9223372036854775807", run("TINSERT "+name+" 9223372036854775807"))
+		assert.Equal(t, "TRUE", run("TGET "+name+" 9223372036854775807"))
+		assert.Equal(t, "FALSE", run("TINSERT "+name+" 9223372036854775808"))
+	}
+	assert.Nil(t, db.FindTree("t_BTREE"))
+	assert.Len(t, db.OrderedSets, 3)
+
+	run("CREATE TREE plain ENGINE=AVL")
+	assert.NotNil(t, db.FindTree("plain"))
+
+	assert.Contains(t, run("CREATE TREE bad ENGINE=HEAP"), "Ошибка")
+	assert.Contains(t, run("CREATE TREE bad ENGINE=BTREE KEYS=STRING"), "Ошибка")
+	assert.Contains(t, run("CREATE TREE bad ENGINE=SKIPLIST MULTISET"), "Ошибка")
+	assert.Nil(t, db.FindOrderedSet("bad"))
+}
+
+func TestCommandParser_DiskTree(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+	defer db.Cleanup()
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	path := filepath.Join(t.TempDir(), "ids.db")
+	assert.Equal(t, "Дерево 'ids' создан.", run("CREATE TREE ids ENGINE=DISK FILE="+path))
+	assert.Equal(t, "7", run("TINSERT ids 7"))
+	assert.Equal(t, "3", run("TINSERT ids 3"))
+	assert.Equal(t, "TRUE", run("TGET ids 3"))
+	assert.Equal(t, "TRUE", run("TDEL ids 3"))
+	assert.Equal(t, "Дерево 'ids' in-order: 7", run("PRINT TREE ids"))
+
+	assert.Contains(t, run("CREATE TREE other ENGINE=RBTREE FILE="+path), "Ошибка")
+	assert.Contains(t, run("CREATE TREE bad ENGINE=DISK FILE="+t.TempDir()), "Ошибка")
+}
+
+func TestCommandParser_HashFunctionOptions(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	run("CREATE HASH fnv HASH=FNV1A")
+	assert.Equal(t, FNV1A_HASH, db.FindHashTable("fnv").GetHashFunction().Kind())
+
+	run("CREATE HASH keyed HASH=SIPHASH SEED=99")
+	assert.Equal(t, uint64(99), db.FindHashTable("keyed").GetHashFunction().Seed())
+
+	assert.Contains(t, run("CREATE HASH bad HASH=MD5"), "Ошибка")
+	assert.Contains(t, run("CREATE HASH bad SEED=-1"), "Ошибка")
+	assert.Contains(t, run("CREATE HASH bad SEED=0"), "Ошибка")
+	assert.Nil(t, db.FindHashTable("bad"))
+}
+
+func TestCommandParser_HashShrinkOption(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	run("CREATE HASH sparse LOADFACTOR=0.8 SHRINK=0.25")
+	assert.Equal(t, 0.25, db.FindHashTable("sparse").GetShrinkFactor())
+
+	// Без SHRINK порог подбирается под коэффициент заполнения
+	run("CREATE HASH dense LOADFACTOR=0.15")
+	assert.Less(t, db.FindHashTable("dense").GetShrinkFactor(), 0.075)
+
+	assert.Contains(t, run("CREATE HASH bad SHRINK=0"), "Ошибка")
+	assert.Contains(t, run("CREATE HASH bad SHRINK=x"), "Ошибка")
+	assert.Contains(t, run("CREATE HASH bad LOADFACTOR=0.5 SHRINK=0.3"), "Ошибка")
+	assert.Nil(t, db.FindHashTable("bad"))
+}
+
+func TestCommandParser_HashEngines(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	run("CREATE HASH open ENGINE=ROBINHOOD")
+	table := db.FindHashTable("open")
+	assert.Equal(t, ROBINHOOD_ENGINE, table.GetEngine())
+	assert.Equal(t, robinHoodLoadFactor, table.GetLoadFactor())
+
+	assert.Equal(t, "1", run("HINSERT open a 1"))
+	assert.Equal(t, "2", run("HINSERT open b 2"))
+	assert.Equal(t, "1", run("HGET open a"))
+	assert.Equal(t, "TRUE", run("HDEL open a"))
+	assert.Equal(t, "FALSE", run("HGET open a"))
+	assert.Equal(t, "1", run("HSIZE open"))
+	assert.Contains(t, run("PRINT HASH open"), "{b: 2}")
+
+	run("CREATE HASH chained ENGINE=CHAINING")
+	assert.Equal(t, CHAINING_ENGINE, db.FindHashTable("chained").GetEngine())
+
+	assert.Contains(t, run("CREATE HASH bad ENGINE=CUCKOO"), "Ошибка")
+	assert.Contains(t, run("CREATE HASH bad ENGINE=ROBINHOOD ORDERED"), "Ошибка")
+	assert.Contains(t, run("CREATE HASH bad ENGINE=ROBINHOOD LOADFACTOR=1"), "Ошибка")
+	assert.Nil(t, db.FindHashTable("bad"))
+}
+
+func TestCommandParser_HashIteration(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	run("CREATE HASH users ORDERED")
+	assert.Equal(t, "EMPTY", run("HKEYS users"))
+	run("HINSERT users alice 30")
+	run("HINSERT users bob 25")
+	run("HINSERT users carol 41")
+
+	assert.Equal(t, "alice\nbob\ncarol", run("HKEYS users"))
+	assert.Equal(t, "30\n25\n41", run("HVALS users"))
+	assert.Equal(t, "alice 30\nbob 25\ncarol 41", run("HGETALL users"))
+	assert.Equal(t, "FALSE", run("HKEYS missing"))
+	assert.Equal(t, "FALSE", run("HGETALL"))
+
+	lines := strings.Split(run("HSCAN users 0 COUNT 100"), "\n")
+	assert.Equal(t, "0", lines[0])
+	assert.ElementsMatch(t, []string{"alice 30", "bob 25", "carol 41"}, lines[1:])
+
+	lines = strings.Split(run("HSCAN users 0 MATCH b* COUNT 100"), "\n")
+	assert.Equal(t, []string{"0", "bob 25"}, lines)
+
+	// Обход по курсору, пока таблица растёт
+	run("CREATE HASH big CAPACITY=2")
+	for i := 0; i < 40; i++ {
+		run(fmt.Sprintf("HINSERT big key%d v", i))
+	}
+	seen := make(map[string]bool)
+	cursor := "0"
+	for step := 0; ; step++ {
+		lines := strings.Split(run("HSCAN big "+cursor+" COUNT 3"), "\n")
+		for _, line := range lines[1:] {
+			if line != "" {
+				seen[strings.Fields(line)[0]] = true
+			}
+		}
+		cursor = lines[0]
+		if cursor == "0" {
+			break
+		}
+		run(fmt.Sprintf("HINSERT big extra%d v", step))
+	}
+	for i := 0; i < 40; i++ {
+		assert.True(t, seen[fmt.Sprintf("key%d", i)])
+	}
+
+	assert.Equal(t, "FALSE", run("HSCAN big x"))
+	assert.Equal(t, "FALSE", run("HSCAN big 0 COUNT 0"))
+	assert.Equal(t, "FALSE", run("HSCAN big 0 COUNT"))
+	assert.Equal(t, "FALSE", run("HSCAN big 0 LIMIT 5"))
+	assert.Equal(t, "FALSE", run("HSCAN missing 0"))
+}
+
+func TestCommandParser_AtomicOperations(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	run("CREATE HASH counters")
+	assert.Equal(t, "5", run("HINCRBY counters hits 5"))
+	assert.Equal(t, "3", run("HINCRBY counters hits -2"))
+	assert.Equal(t, "3", run("HGET counters hits"))
+	assert.Equal(t, "1.5", run("HINCRBYFLOAT counters ratio 1.5"))
+	assert.Equal(t, "4.5", run("HINCRBYFLOAT counters hits 1.5"))
+
+	run("HINSERT counters name bob")
+	assert.Contains(t, run("HINCRBY counters name 1"), "Ошибка")
+	assert.Contains(t, run("HINCRBYFLOAT counters name 1"), "Ошибка")
+	assert.Contains(t, run("HINCRBY counters hits x"), "Ошибка")
+	assert.Contains(t, run("HINCRBY counters hits 1"), "not an integer")
+	assert.Equal(t, "FALSE", run("HINCRBY missing hits 1"))
+
+	assert.Equal(t, "TRUE", run("HSETNX counters lock owner1"))
+	assert.Equal(t, "FALSE", run("HSETNX counters lock owner2"))
+	assert.Equal(t, "owner1", run("HGET counters lock"))
+	assert.Equal(t, "FALSE", run("HCAS counters lock owner2 owner3"))
+	assert.Equal(t, "TRUE", run("HCAS counters lock owner1 owner3"))
+	assert.Equal(t, "owner3", run("HGET counters lock"))
+	assert.Equal(t, "FALSE", run("HCAS counters lock owner3"))
+
+	run("CREATE ARRAY nums")
+	run("MPUSH nums 10")
+	run("MPUSH nums text")
+	assert.Equal(t, "11", run("MINCR nums 0"))
+	assert.Equal(t, "1", run("MINCR nums 0 -10"))
+	assert.Contains(t, run("MINCR nums 1"), "Ошибка")
+	assert.Contains(t, run("MINCR nums 5"), "Ошибка")
+	assert.Contains(t, run("MINCR nums 0 x"), "Ошибка")
+	assert.Equal(t, "TRUE", run("MCAS nums 1 text done"))
+	assert.Equal(t, "FALSE", run("MCAS nums 1 text again"))
+	assert.Equal(t, "FALSE", run("MCAS nums 7 a b"))
+	assert.Equal(t, "done", run("MGET nums 1"))
+}
+
+func TestCommandParser_HashExpiry(t *testing.T) {
+	db := NewDatabase()
+	clock := newFakeClock()
+	db.SetClock(clock.Now)
+	parser := NewCommandParser(db)
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	run("CREATE HASH sessions")
+	run("HINSERT sessions sid alice")
+	run("HINSERT sessions other bob")
+
+	assert.Equal(t, "-1", run("HTTL sessions sid"))
+	assert.Equal(t, "-2", run("HTTL sessions missing"))
+	assert.Equal(t, "TRUE", run("HEXPIRE sessions sid 60"))
+	assert.Equal(t, "FALSE", run("HEXPIRE sessions missing 60"))
+	assert.Contains(t, run("HEXPIRE sessions sid soon"), "Ошибка")
+	assert.Equal(t, "FALSE", run("HEXPIRE nothing sid 60"))
+
+	clock.Advance(20 * time.Second)
+	assert.Equal(t, "40", run("HTTL sessions sid"))
+	clock.Advance(40 * time.Second)
+	assert.Equal(t, "FALSE", run("HGET sessions sid"))
+	assert.Equal(t, "-2", run("HTTL sessions sid"))
+	assert.Equal(t, "1", run("HSIZE sessions"))
+
+	run("HEXPIRE sessions other 5")
+	assert.Equal(t, "TRUE", run("HPERSIST sessions other"))
+	assert.Equal(t, "FALSE", run("HPERSIST sessions other"))
+	assert.Equal(t, "-1", run("HTTL sessions other"))
+	assert.Equal(t, "FALSE", run("HTTL sessions"))
+}
+
+func TestCommandParser_ExpirySweeper(t *testing.T) {
+	db := NewDatabase()
+	clock := newFakeClock()
+	db.SetClock(clock.Now)
+	parser := NewCommandParser(db)
+
+	captureOutput(func() {
+		parser.ProcessCommand("CREATE HASH sessions")
+		for i := 0; i < 100; i++ {
+			parser.ProcessCommand(fmt.Sprintf("HINSERT sessions key%d v", i))
+			parser.ProcessCommand(fmt.Sprintf("HEXPIRE sessions key%d 1", i))
+		}
+	})
+	table := db.FindHashTable("sessions")
+
+	parser.mu.Lock()
+	clock.Advance(time.Second)
+	parser.mu.Unlock()
+
+	stop := parser.StartExpirySweeper(time.Millisecond)
+	defer stop()
+	assert.Eventually(t, func() bool {
+		parser.mu.Lock()
+		defer parser.mu.Unlock()
+		return table.size == 0
+	}, 5*time.Second, time.Millisecond)
+	stop()
+}
+
+func TestCommandParser_HashStats(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	run("CREATE HASH users CAPACITY=4 HASH=FNV1A")
+	for i := 0; i < 10; i++ {
+		run(fmt.Sprintf("HINSERT users user%d v", i))
+	}
+	output := run("HSTATS users")
+	assert.Contains(t, output, "Хеш-таблица 'users'")
+	assert.Contains(t, output, "Движок: CHAINING, хеш-функция: FNV1A")
+	assert.Contains(t, output, "Записей: 10, ёмкость: 16")
+	assert.Contains(t, output, "Изменений ёмкости: 2")
+	assert.Contains(t, output, "Самая длинная цепочка")
+
+	run("CREATE HASH open ENGINE=ROBINHOOD")
+	assert.Contains(t, run("HSTATS open"), "Самое длинное пробирование: 0")
+	assert.Equal(t, "FALSE", run("HSTATS missing"))
+	assert.Equal(t, "FALSE", run("HSTATS"))
+}
+
+func TestCommandParser_ListIndexOperations(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	for _, prefix := range []string{"F", "L"} {
+		kind := map[string]string{"F": "SLL", "L": "DLL"}[prefix]
+		list := kind + "_list"
+		run("CREATE " + kind + " " + list)
+		assert.Equal(t, "0", run(prefix+"LEN "+list))
+
+		run(prefix + "PUSH_BACK " + list + " a")
+		run(prefix + "PUSH_BACK " + list + " c")
+		assert.Equal(t, "b", run(prefix+"INSERT_AT "+list+" 1 b"))
+		assert.Equal(t, "d", run(prefix+"INSERT_AT "+list+" 3 d"))
+		assert.Equal(t, "FALSE", run(prefix+"INSERT_AT "+list+" 9 x"))
+		assert.Equal(t, "4", run(prefix+"LEN "+list))
+
+		assert.Equal(t, "a", run(prefix+"INDEX "+list+" 0"))
+		assert.Equal(t, "d", run(prefix+"INDEX "+list+" 3"))
+		assert.Equal(t, "FALSE", run(prefix+"INDEX "+list+" 4"))
+		assert.Equal(t, "FALSE", run(prefix+"INDEX "+list+" x"))
+
+		assert.Equal(t, "B", run(prefix+"SET "+list+" 1 B"))
+		assert.Equal(t, "B", run(prefix+"INDEX "+list+" 1"))
+		assert.Equal(t, "FALSE", run(prefix+"SET "+list+" 4 x"))
+
+		assert.Equal(t, "c", run(prefix+"DEL_AT "+list+" 2"))
+		assert.Equal(t, "FALSE", run(prefix+"DEL_AT "+list+" 3"))
+		assert.Equal(t, "3", run(prefix+"LEN "+list))
+
+		assert.Equal(t, "FALSE", run(prefix+"LEN missing"))
+		assert.Equal(t, "FALSE", run(prefix+"INDEX "+list))
+	}
+}
+
+func TestCommandParser_RangeCommands(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	run("CREATE ARRAY arr")
+	run("CREATE SLL sll")
+	run("CREATE DLL dll")
+	run("CREATE STACK stack")
+	run("CREATE QUEUE queue")
+	for _, value := range []string{"a", "b", "c", "d"} {
+		run("MPUSH arr " + value)
+		run("FPUSH_BACK sll " + value)
+		run("LPUSH_BACK dll " + value)
+		run("SPUSH stack " + value)
+		run("QPUSH queue " + value)
+	}
+
+	assert.Equal(t, "b\nc", run("MRANGE arr 1 2"))
+	assert.Equal(t, "c\nd", run("FRANGE sll -2 -1"))
+	assert.Equal(t, "a\nb\nc\nd", run("LRANGE dll 0 -1"))
+	assert.Equal(t, "d\nc", run("SRANGE stack 0 1"))
+	assert.Equal(t, "a", run("QRANGE queue 0 0"))
+
+	assert.Equal(t, "EMPTY", run("MRANGE arr 3 1"))
+	assert.Equal(t, "EMPTY", run("QRANGE queue 10 20"))
+	assert.Equal(t, "FALSE", run("MRANGE missing 0 -1"))
+	assert.Equal(t, "FALSE", run("LRANGE sll 0 -1"))
+	assert.Equal(t, "FALSE", run("SRANGE stack 0"))
+	assert.Equal(t, "FALSE", run("FRANGE sll a 1"))
+}
+
+func TestCommandParser_ListAlgorithms(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	run("CREATE SLL sll")
+	for _, value := range []string{"10", "2", "2", "1", "10"} {
+		run("FPUSH_BACK sll " + value)
+	}
+	assert.Equal(t, "TRUE", run("FSORT sll ORDER=NUM"))
+	assert.Equal(t, "1\n2\n2\n10\n10", run("FRANGE sll 0 -1"))
+	assert.Equal(t, "2", run("FUNIQ sll"))
+	assert.Equal(t, "TRUE", run("FREVERSE sll"))
+	assert.Equal(t, "10\n2\n1", run("FRANGE sll 0 -1"))
+	assert.Equal(t, "TRUE", run("FSORT sll"))
+	assert.Equal(t, "1\n10\n2", run("FRANGE sll 0 -1"))
+	assert.Contains(t, run("FSORT sll ORDER=RANDOM"), "Ошибка")
+
+	run("CREATE DLL left")
+	run("CREATE DLL right")
+	for _, value := range []string{"a", "b", "a", "c"} {
+		run("LPUSH_BACK left " + value)
+	}
+	run("LPUSH_BACK right x")
+	assert.Equal(t, "1", run("LUNIQ left ALL"))
+	assert.Equal(t, "FALSE", run("LUNIQ left SOME"))
+	assert.Equal(t, "2", run("LSPLICE left 1 -1 right 1"))
+	assert.Equal(t, "a", run("LRANGE left 0 -1"))
+	assert.Equal(t, "x\nb\nc", run("LRANGE right 0 -1"))
+	assert.Equal(t, "FALSE", run("LSPLICE left 0 0 right 9"))
+	assert.Equal(t, "4", run("LCONCAT left right"))
+	assert.Equal(t, "EMPTY", run("LRANGE right 0 -1"))
+	assert.Equal(t, "TRUE", run("LREVERSE left"))
+	assert.Equal(t, "c\nb\nx\na", run("LRANGE left 0 -1"))
+	assert.Equal(t, "TRUE", run("LSORT left"))
+	assert.Equal(t, "a\nb\nc\nx", run("LRANGE left 0 -1"))
+	assert.Equal(t, "FALSE", run("LCONCAT left left"))
+	assert.Equal(t, "FALSE", run("LSORT missing"))
+}
+
+func TestCommandParser_ListRemoveByValue(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	for _, prefix := range []string{"F", "L"} {
+		kind := map[string]string{"F": "SLL", "L": "DLL"}[prefix]
+		list := kind + "_list"
+		run("CREATE " + kind + " " + list)
+		for _, value := range []string{"a", "b", "a", "c", "a", "a"} {
+			run(prefix + "PUSH_BACK " + list + " " + value)
+		}
+
+		assert.Equal(t, "0", run(prefix+"POS "+list+" a"))
+		assert.Equal(t, "5", run(prefix+"POS "+list+" a LAST"))
+		assert.Equal(t, "FALSE", run(prefix+"POS "+list+" z"))
+		assert.Equal(t, "FALSE", run(prefix+"POS "+list+" a FIRST"))
+
+		assert.Equal(t, "1", run(prefix+"DEL_VALUE "+list+" a 1"))
+		assert.Equal(t, "2", run(prefix+"DEL_VALUE "+list+" a -2"))
+		assert.Equal(t, "b\na\nc", run(prefix+"RANGE "+list+" 0 -1"))
+		assert.Equal(t, "0", run(prefix+"DEL_VALUE "+list+" z"))
+		assert.Equal(t, "1", run(prefix+"DEL_VALUE "+list+" a"))
+		assert.Equal(t, "FALSE", run(prefix+"DEL_VALUE "+list+" a x"))
+		assert.Equal(t, "FALSE", run(prefix+"DEL_VALUE missing a"))
+	}
+}
+
+func TestCommandParser_MoveCommands(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	run("CREATE QUEUE jobs")
+	run("CREATE QUEUE done")
+	run("CREATE STACK undo")
+	run("QPUSH jobs a")
+	run("QPUSH jobs b")
+
+	assert.Equal(t, "a", run("QMOVE jobs done"))
+	assert.Equal(t, "b", run("QTOS jobs undo"))
+	assert.Equal(t, "FALSE", run("QTOS jobs undo"))
+	assert.Equal(t, "b", run("STOQ undo jobs"))
+	assert.Equal(t, "FALSE", run("QMOVE jobs undo"))
+	assert.Equal(t, "b", run("QRANGE jobs 0 -1"))
+	assert.Equal(t, "FALSE", run("QMOVE jobs"))
+
+	run("CREATE DLL left")
+	run("CREATE DLL right")
+	run("CREATE SLL tasks")
+	run("LPUSH_BACK left x")
+	run("LPUSH_BACK left y")
+	run("FPUSH_BACK tasks t1")
+	run("FPUSH_BACK tasks t2")
+
+	assert.Equal(t, "y", run("LMOVE left right BACK FRONT"))
+	assert.Equal(t, "x", run("LMOVE left right front back"))
+	assert.Equal(t, "y\nx", run("LRANGE right 0 -1"))
+	assert.Equal(t, "FALSE", run("LMOVE left right FRONT BACK"))
+	assert.Equal(t, "FALSE", run("LMOVE right left UP BACK"))
+	assert.Equal(t, "t1", run("FTOL tasks right FRONT FRONT"))
+	assert.Equal(t, "t1\ny\nx", run("LRANGE right 0 -1"))
+	assert.Equal(t, "FALSE", run("FTOL tasks missing FRONT FRONT"))
+	assert.Equal(t, "t2", run("FRANGE tasks 0 -1"))
+}
+
+func TestCommandParser_SaveHashEncoding(t *testing.T) {
+	db := NewDatabase()
+	parser := NewCommandParser(db)
+
+	run := func(command string) string {
+		return captureOutput(func() { parser.ProcessCommand(command) })
+	}
+
+	filename := "test_save_hash_encoding.txt"
+	defer os.Remove(filename)
+
+	run("CREATE HASH h HASH=FNV1A")
+	run("HINSERT h a 1")
+	assert.True(t, strings.HasSuffix(run("SAVE "+filename+" HASH=LAYOUT"), "TRUE"))
+	data, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "HASH_LAYOUT h")
+	assert.Equal(t, HASH_AUTO, parser.fileIO.GetHashEncoding())
+
+	// ENTRIES пишет старую запись даже для таблицы со случайным ключом
+	run("CREATE HASH plain")
+	run("HINSERT plain b 2")
+	assert.True(t, strings.HasSuffix(run("SAVE "+filename+" HASH=ENTRIES"), "TRUE"))
+	data, err = os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, "HASH h 1 a 1\nHASH plain 1 b 2\n", string(data))
+
+	loaded := NewDatabase()
+	assert.NoError(t, NewFileIO().LoadDatabaseFromFile(loaded, filename))
+	value, found := loaded.FindHashTable("plain").Search("b")
+	assert.True(t, found)
+	assert.Equal(t, "2", value)
+
+	assert.True(t, strings.HasSuffix(run("SAVE "+filename+" HASH=AUTO"), "TRUE"))
+	data, err = os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "HASH_LAYOUT plain")
+
+	assert.Equal(t, "FALSE", run("SAVE "+filename+" HASH=CSV"))
+	assert.Equal(t, "FALSE", run("SAVE "+filename+" FORMAT=LAYOUT"))
+}
@@ -319,7 +319,7 @@ func TestAVLTreeComprehensive(t *testing.T) {
 	// Test Search
 	node := tree.Search(50)
 	assert.NotNil(t, node)
-	assert.Equal(t, 50, node.Data)
+	assert.Equal(t, int64(50), node.Data)
 
 	node = tree.Search(100)
 	assert.Nil(t, node)
@@ -0,0 +1,380 @@
+package dbmsgo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Сколько пропущенных элементов CONVERT перечисляет поимённо
+const convertSkipReportLimit = 10
+
+var convertTypes = []string{"ARRAY", "SLL", "DLL", "STACK", "QUEUE", "TREE", "HASH"}
+
+// convertItem - элемент источника. У хеш-таблицы и словаря есть значение,
+// у остальных структур только key.
+type convertItem struct {
+	key   string
+	value string
+}
+
+// convertSkip - элемент, который не попал в приёмник, и причина
+type convertSkip struct {
+	value  string
+	reason string
+}
+
+// convertSource - содержимое источника в порядке обхода: массив и списки
+// от начала, стек от вершины, очередь от начала, дерево по возрастанию
+type convertSource struct {
+	items []convertItem
+	keyed bool // пары ключ-значение: хеш-таблица или словарь
+}
+
+// projection возвращает ключи или, если values, значения элементов
+func (s convertSource) projection(values bool) []string {
+	result := make([]string, len(s.items))
+	for i, item := range s.items {
+		result[i] = item.key
+		if values {
+			result[i] = item.value
+		}
+	}
+	return result
+}
+
+// pairs возвращает пары для приёмника с ключами. Элементы структур без
+// значений становятся ключами, значение - число их повторений в источнике;
+// порядок - по первому вхождению.
+func (s convertSource) pairs() []convertItem {
+	if s.keyed {
+		return s.items
+	}
+	counts := make(map[string]int)
+	order := make([]string, 0, len(s.items))
+	for _, item := range s.items {
+		if counts[item.key] == 0 {
+			order = append(order, item.key)
+		}
+		counts[item.key]++
+	}
+	result := make([]convertItem, len(order))
+	for i, key := range order {
+		result[i] = convertItem{key: key, value: strconv.Itoa(counts[key])}
+	}
+	return result
+}
+
+// findTypes возвращает типы структур с именем name
+func (p *CommandParser) findTypes(name string) []string {
+	found := make([]string, 0, 1)
+	for _, typeName := range convertTypes {
+		if p.structureExists(typeName, name) {
+			found = append(found, typeName)
+		}
+	}
+	return found
+}
+
+func (p *CommandParser) structureExists(typeName, name string) bool {
+	switch typeName {
+	case "ARRAY":
+		return p.db.FindArray(name) != nil
+	case "SLL":
+		return p.db.FindSLL(name) != nil
+	case "DLL":
+		return p.db.FindDLL(name) != nil
+	case "STACK":
+		return p.db.FindStack(name) != nil
+	case "QUEUE":
+		return p.db.FindQueue(name) != nil
+	case "TREE":
+		return p.db.FindOrderedSet(name) != nil
+	case "HASH":
+		return p.db.FindHashTable(name) != nil
+	}
+	return false
+}
+
+// readSource читает содержимое структуры типа typeName
+func (p *CommandParser) readSource(typeName, name string) convertSource {
+	var values []string
+	switch typeName {
+	case "ARRAY":
+		values = p.db.FindArray(name).GetData()
+	case "SLL":
+		values = p.db.FindSLL(name).Range(0, -1)
+	case "DLL":
+		values = p.db.FindDLL(name).Range(0, -1)
+	case "STACK":
+		values = p.db.FindStack(name).Range(0, -1)
+	case "QUEUE":
+		values = p.db.FindQueue(name).Range(0, -1)
+	case "TREE":
+		if tree := p.db.FindTree(name); tree != nil {
+			if tree.GetMode() == STRING_KEYS {
+				keys, treeValues := tree.SaveEntries()
+				source := convertSource{items: make([]convertItem, len(keys)), keyed: true}
+				for i := range keys {
+					source.items[i] = convertItem{key: keys[i], value: treeValues[i]}
+				}
+				return source
+			}
+			values = tree.SaveKeys()
+			break
+		}
+		for _, value := range p.db.FindOrderedSet(name).SaveTree() {
+			values = append(values, strconv.FormatInt(value, 10))
+		}
+	case "HASH":
+		entries := p.db.FindHashTable(name).Entries()
+		source := convertSource{items: make([]convertItem, len(entries)), keyed: true}
+		for i, entry := range entries {
+			source.items[i] = convertItem{key: entry.Key, value: entry.Value}
+		}
+		return source
+	}
+
+	source := convertSource{items: make([]convertItem, len(values))}
+	for i, value := range values {
+		source.items[i] = convertItem{key: value}
+	}
+	return source
+}
+
+// handleConvert обрабатывает CONVERT <src> <TYPE> <dst> [FROM=<TYPE>]
+// [FIELD=KEYS|VALUES] [параметры CREATE для TREE и HASH]. Приёмник
+// создаётся заново и добавляется в базу, только если построен целиком.
+func (p *CommandParser) handleConvert(parts []string) {
+	if len(parts) < 3 {
+		fmt.Println("Ошибка: недостаточно параметров для CONVERT")
+		return
+	}
+
+	srcName, dstType, dstName := parts[0], strings.ToUpper(parts[1]), parts[2]
+	if !p.isConvertType(dstType) {
+		fmt.Println("Ошибка: неизвестный тип структуры")
+		return
+	}
+
+	srcType := ""
+	useValues := false
+	fieldSet := false
+	options := make([]string, 0, len(parts)-3)
+	for _, option := range parts[3:] {
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "FROM":
+			srcType = strings.ToUpper(value)
+			if !p.isConvertType(srcType) {
+				fmt.Printf("Ошибка: неизвестный тип структуры '%s'\n", value)
+				return
+			}
+		case "FIELD":
+			switch strings.ToUpper(value) {
+			case "KEYS":
+				useValues = false
+			case "VALUES":
+				useValues = true
+			default:
+				fmt.Printf("Ошибка: неизвестное поле '%s'\n", value)
+				return
+			}
+			fieldSet = true
+		default:
+			options = append(options, option)
+		}
+	}
+
+	if srcType == "" {
+		found := p.findTypes(srcName)
+		switch len(found) {
+		case 0:
+			fmt.Printf("Ошибка: структура '%s' не найдена\n", srcName)
+			return
+		case 1:
+			srcType = found[0]
+		default:
+			fmt.Printf("Ошибка: имя '%s' есть у нескольких структур (%s), укажите FROM=<тип>\n",
+				srcName, strings.Join(found, ", "))
+			return
+		}
+	} else if !p.structureExists(srcType, srcName) {
+		fmt.Printf("Ошибка: структура %s '%s' не найдена\n", srcType, srcName)
+		return
+	}
+	if p.structureExists(dstType, dstName) {
+		fmt.Printf("Ошибка: структура %s '%s' уже существует\n", dstType, dstName)
+		return
+	}
+
+	source := p.readSource(srcType, srcName)
+	if fieldSet && !source.keyed {
+		fmt.Println("Ошибка: FIELD применим только к хеш-таблице и словарю")
+		return
+	}
+
+	converted, skipped, err := p.buildConverted(dstType, dstName, source, useValues, options)
+	if err != nil {
+		fmt.Printf("Ошибка: %v\n", err)
+		return
+	}
+
+	fmt.Printf("%s '%s' преобразован в %s '%s': элементов %d, пропущено %d\n",
+		srcType, srcName, dstType, dstName, converted, len(skipped))
+	for i, skip := range skipped {
+		if i == convertSkipReportLimit {
+			fmt.Printf("  ... и ещё %d\n", len(skipped)-i)
+			break
+		}
+		fmt.Printf("  пропущен '%s': %s\n", skip.value, skip.reason)
+	}
+}
+
+func (p *CommandParser) isConvertType(typeName string) bool {
+	for _, known := range convertTypes {
+		if known == typeName {
+			return true
+		}
+	}
+	return false
+}
+
+// buildConverted строит приёмник, добавляет его в базу и возвращает число
+// добавленных элементов и пропущенные элементы
+func (p *CommandParser) buildConverted(typeName, name string, source convertSource, useValues bool, options []string) (int, []convertSkip, error) {
+	if typeName != "TREE" && typeName != "HASH" && len(options) > 0 {
+		return 0, nil, fmt.Errorf("параметр '%s' не поддерживается для %s", options[0], typeName)
+	}
+
+	values := source.projection(useValues)
+	switch typeName {
+	case "ARRAY":
+		arr := NewArray(name)
+		for _, value := range values {
+			arr.PushBack(value)
+		}
+		p.db.AddArray(arr)
+	case "SLL":
+		sll := NewSinglyLinkedList(name)
+		for _, value := range values {
+			sll.PushBack(value)
+		}
+		p.db.AddSLL(sll)
+	case "DLL":
+		dll := NewDoublyLinkedList(name)
+		for _, value := range values {
+			dll.PushBack(value)
+		}
+		p.db.AddDLL(dll)
+	case "STACK":
+		// Первый элемент источника оказывается на вершине
+		stack := NewStack(name)
+		for i := len(values) - 1; i >= 0; i-- {
+			stack.Push(values[i])
+		}
+		p.db.AddStack(stack)
+	case "QUEUE":
+		queue := NewQueue(name)
+		for _, value := range values {
+			queue.Push(value)
+		}
+		p.db.AddQueue(queue)
+	case "TREE":
+		return p.convertToTree(name, source, values, options)
+	case "HASH":
+		opts, err := p.parseHashOptions(options)
+		if err != nil {
+			return 0, nil, err
+		}
+		table, err := NewHashTableWithOptions(name, opts)
+		if err != nil {
+			return 0, nil, err
+		}
+		pairs := source.pairs()
+		for _, pair := range pairs {
+			table.Insert(pair.key, pair.value)
+		}
+		p.db.AddHashTable(table)
+		return len(pairs), nil, nil
+	}
+	return len(values), nil, nil
+}
+
+// convertToTree заполняет дерево. В дерево с целыми ключами попадают только
+// целые числа, в словарь с ORDER=NUM - только числа; повторы пропускаются,
+// кроме мультимножества.
+func (p *CommandParser) convertToTree(name string, source convertSource, values []string, options []string) (int, []convertSkip, error) {
+	engine, rest, err := p.parseTreeEngine(options)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var skipped []convertSkip
+	if engine != AVL_ENGINE {
+		var set OrderedSet
+		if engine == DISK_ENGINE {
+			tree, err := p.openDiskTree(name, rest)
+			if err != nil {
+				return 0, nil, err
+			}
+			set = tree
+		} else {
+			set = NewOrderedSet(name, engine)
+		}
+		for _, text := range values {
+			value, err := strconv.ParseInt(text, 10, 64)
+			if err != nil {
+				skipped = append(skipped, convertSkip{text, "не целое число"})
+				continue
+			}
+			if set.Contains(value) {
+				skipped = append(skipped, convertSkip{text, "повтор"})
+				continue
+			}
+			set.Insert(value)
+		}
+		p.db.AddOrderedSet(set)
+		return set.Size(), skipped, nil
+	}
+
+	tree, err := p.newTree(name, rest)
+	if err != nil {
+		return 0, nil, err
+	}
+	invalid := "не целое число"
+	if tree.GetMode() == STRING_KEYS {
+		invalid = "не число"
+	}
+
+	converted := 0
+	add := func(key, value string) {
+		node, err := tree.SearchKey(key)
+		if err != nil {
+			skipped = append(skipped, convertSkip{key, invalid})
+			return
+		}
+		if node != nil && !tree.IsMultiset() {
+			skipped = append(skipped, convertSkip{key, "повтор"})
+			return
+		}
+		if tree.GetMode() == STRING_KEYS {
+			tree.Put(key, value)
+		} else {
+			tree.InsertKey(key)
+		}
+		converted++
+	}
+
+	if tree.GetMode() == STRING_KEYS {
+		for _, pair := range source.pairs() {
+			add(pair.key, pair.value)
+		}
+	} else {
+		for _, value := range values {
+			add(value, "")
+		}
+	}
+	p.db.AddTree(tree)
+	return converted, skipped, nil
+}
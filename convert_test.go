@@ -0,0 +1,140 @@
+package dbmsgo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandParser_Convert(t *testing.T) {
+	newParser := func() (*Database, func(string) string) {
+		db := NewDatabase()
+		parser := NewCommandParser(db)
+		return db, func(command string) string {
+			return captureOutput(func() { parser.ProcessCommand(command) })
+		}
+	}
+
+	t.Run("ArrayToTree", func(t *testing.T) {
+		db, run := newParser()
+		run("CREATE ARRAY nums")
+		for _, value := range []string{"5", "x", "3", "5", "1.5", "1"} {
+			run("MPUSH nums " + value)
+		}
+
+		output := run("CONVERT nums TREE set")
+		assert.Contains(t, output, "ARRAY 'nums' преобразован в TREE 'set': элементов 3, пропущено 3")
+		assert.Contains(t, output, "пропущен 'x': не целое число")
+		assert.Contains(t, output, "пропущен '5': повтор")
+		assert.Contains(t, output, "пропущен '1.5': не целое число")
+		assert.Equal(t, []int64{1, 3, 5}, db.FindTree("set").SaveTree())
+
+		run("CONVERT nums TREE multi MULTISET")
+		assert.Equal(t, []int64{1, 3, 5, 5}, db.FindTree("multi").SaveTree())
+
+		run("CONVERT nums TREE rb ENGINE=RBTREE")
+		assert.Equal(t, []int64{1, 3, 5}, db.FindOrderedSet("rb").SaveTree())
+		assert.Nil(t, db.FindTree("rb"))
+	})
+
+	t.Run("Sequences", func(t *testing.T) {
+		db, run := newParser()
+		run("CREATE QUEUE jobs")
+		for _, value := range []string{"a", "b", "c"} {
+			run("QPUSH jobs " + value)
+		}
+
+		assert.Contains(t, run("CONVERT jobs ARRAY snapshot"), "элементов 3, пропущено 0")
+		assert.Equal(t, []string{"a", "b", "c"}, db.FindArray("snapshot").GetData())
+		assert.Equal(t, 3, db.FindQueue("jobs").GetSize())
+
+		// Стек получает первый элемент на вершину, обратное преобразование
+		// сохраняет порядок
+		run("CONVERT jobs STACK stack")
+		assert.Equal(t, "a", run("SPOP stack"))
+		run("CONVERT stack DLL list")
+		assert.Equal(t, []string{"b", "c"}, db.FindDLL("list").Range(0, -1))
+		run("CONVERT list SLL flist")
+		assert.Equal(t, []string{"b", "c"}, db.FindSLL("flist").Range(0, -1))
+		run("CONVERT flist QUEUE queue")
+		assert.Equal(t, []string{"b", "c"}, db.FindQueue("queue").Range(0, -1))
+	})
+
+	t.Run("HashKeysAndValues", func(t *testing.T) {
+		db, run := newParser()
+		run("CREATE HASH users ORDERED")
+		run("HINSERT users alice 30")
+		run("HINSERT users bob 25")
+
+		run("CONVERT users DLL names")
+		assert.Equal(t, []string{"alice", "bob"}, db.FindDLL("names").Range(0, -1))
+		run("CONVERT users ARRAY ages FIELD=VALUES")
+		assert.Equal(t, []string{"30", "25"}, db.FindArray("ages").GetData())
+		run("CONVERT users TREE byage FIELD=VALUES")
+		assert.Equal(t, []int64{25, 30}, db.FindTree("byage").SaveTree())
+
+		run("CONVERT users TREE dict KEYS=STRING")
+		keys, values := db.FindTree("dict").SaveEntries()
+		assert.Equal(t, []string{"alice", "bob"}, keys)
+		assert.Equal(t, []string{"30", "25"}, values)
+
+		run("CONVERT dict HASH copy ENGINE=ROBINHOOD")
+		value, found := db.FindHashTable("copy").Search("bob")
+		assert.True(t, found)
+		assert.Equal(t, "25", value)
+		assert.Equal(t, ROBINHOOD_ENGINE, db.FindHashTable("copy").GetEngine())
+	})
+
+	t.Run("SequenceToHashCountsDuplicates", func(t *testing.T) {
+		db, run := newParser()
+		run("CREATE SLL words")
+		for _, value := range []string{"go", "db", "go"} {
+			run("FPUSH_BACK words " + value)
+		}
+
+		assert.Contains(t, run("CONVERT words HASH counts"), "элементов 2, пропущено 0")
+		value, _ := db.FindHashTable("counts").Search("go")
+		assert.Equal(t, "2", value)
+
+		output := run("CONVERT words TREE nums KEYS=STRING ORDER=NUM")
+		assert.Contains(t, output, "элементов 0, пропущено 2")
+		assert.Contains(t, output, "пропущен 'go': не число")
+	})
+
+	t.Run("SkipReportLimit", func(t *testing.T) {
+		_, run := newParser()
+		run("CREATE ARRAY words")
+		for i := 0; i < 15; i++ {
+			run(fmt.Sprintf("MPUSH words w%d", i))
+		}
+
+		output := run("CONVERT words TREE set")
+		assert.Contains(t, output, "пропущено 15")
+		assert.Equal(t, convertSkipReportLimit, strings.Count(output, "пропущен '"))
+		assert.Contains(t, output, "... и ещё 5")
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		db, run := newParser()
+		run("CREATE ARRAY data")
+		run("CREATE STACK data")
+		run("CREATE QUEUE taken")
+		run("MPUSH data 1")
+
+		assert.Contains(t, run("CONVERT data QUEUE out"), "укажите FROM=<тип>")
+		assert.Contains(t, run("CONVERT data QUEUE taken FROM=ARRAY"), "уже существует")
+		assert.Contains(t, run("CONVERT missing QUEUE out"), "не найдена")
+		assert.Contains(t, run("CONVERT data GRAPH out FROM=ARRAY"), "неизвестный тип")
+		assert.Contains(t, run("CONVERT data QUEUE out FROM=ARRAY FIELD=VALUES"), "FIELD")
+		assert.Contains(t, run("CONVERT data QUEUE out FROM=ARRAY CAPACITY=8"), "не поддерживается")
+		assert.Contains(t, run("CONVERT data TREE out FROM=ARRAY KEYS=FLOAT"), "Ошибка")
+		assert.Contains(t, run("CONVERT data"), "недостаточно параметров")
+		assert.Nil(t, db.FindQueue("out"))
+		assert.Nil(t, db.FindTree("out"))
+
+		assert.Contains(t, run("CONVERT data QUEUE out FROM=STACK"), "элементов 0")
+		assert.NotNil(t, db.FindQueue("out"))
+	})
+}
@@ -1,5 +1,10 @@
 package dbmsgo
 
+import (
+	"io"
+	"strings"
+)
+
 type Database struct {
 	Arrays           []*Array
 	SinglyLinkedLists []*SinglyLinkedList
@@ -7,7 +12,10 @@ type Database struct {
 	Stacks           []*Stack
 	Queues           []*Queue
 	Trees            []*AVLTree
+	OrderedSets      []OrderedSet // деревья на движках, отличных от AVL
 	HashTables       []*HashTable
+
+	clock Clock // часы хеш-таблиц, nil - системное время
 }
 
 func NewDatabase() *Database {
@@ -18,6 +26,7 @@ func NewDatabase() *Database {
 		Stacks:           make([]*Stack, 0),
 		Queues:           make([]*Queue, 0),
 		Trees:            make([]*AVLTree, 0),
+		OrderedSets:      make([]OrderedSet, 0),
 		HashTables:       make([]*HashTable, 0),
 	}
 }
@@ -76,6 +85,30 @@ func (d *Database) FindTree(name string) *AVLTree {
 	return nil
 }
 
+// FindTreeVersion ищет дерево по имени или его снимок по имени вида name@tag
+func (d *Database) FindTreeVersion(name string) *AVLTree {
+	base, tag, found := strings.Cut(name, "@")
+	tree := d.FindTree(base)
+	if !found || tree == nil {
+		return tree
+	}
+	snapshot, _ := tree.GetSnapshot(tag)
+	return snapshot
+}
+
+// FindOrderedSet ищет дерево с целыми ключами на любом движке
+func (d *Database) FindOrderedSet(name string) OrderedSet {
+	if tree := d.FindTree(name); tree != nil {
+		return tree
+	}
+	for _, set := range d.OrderedSets {
+		if set.GetName() == name {
+			return set
+		}
+	}
+	return nil
+}
+
 func (d *Database) FindHashTable(name string) *HashTable {
 	for _, table := range d.HashTables {
 		if table.GetName() == name {
@@ -109,10 +142,39 @@ func (d *Database) AddTree(tree *AVLTree) {
 	d.Trees = append(d.Trees, tree)
 }
 
+func (d *Database) AddOrderedSet(set OrderedSet) {
+	if tree, ok := set.(*AVLTree); ok {
+		d.AddTree(tree)
+		return
+	}
+	d.OrderedSets = append(d.OrderedSets, set)
+}
+
 func (d *Database) AddHashTable(table *HashTable) {
+	if d.clock != nil {
+		table.SetClock(d.clock)
+	}
 	d.HashTables = append(d.HashTables, table)
 }
 
+// SetClock подменяет часы всех хеш-таблиц базы, в том числе добавленных позже
+func (d *Database) SetClock(clock Clock) {
+	d.clock = clock
+	for _, table := range d.HashTables {
+		table.SetClock(clock)
+	}
+}
+
+// SweepExpired удаляет часть ключей с истёкшим сроком жизни во всех
+// хеш-таблицах и возвращает число удалённых
+func (d *Database) SweepExpired() int {
+	removed := 0
+	for _, table := range d.HashTables {
+		removed += table.SweepExpired(expirySweepBatch)
+	}
+	return removed
+}
+
 func (d *Database) Cleanup() {
 	d.Arrays = make([]*Array, 0)
 	d.SinglyLinkedLists = make([]*SinglyLinkedList, 0)
@@ -120,5 +182,12 @@ func (d *Database) Cleanup() {
 	d.Stacks = make([]*Stack, 0)
 	d.Queues = make([]*Queue, 0)
 	d.Trees = make([]*AVLTree, 0)
+	// Дисковые деревья сбрасывают страницы и закрывают файлы
+	for _, set := range d.OrderedSets {
+		if closer, ok := set.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+	d.OrderedSets = make([]OrderedSet, 0)
 	d.HashTables = make([]*HashTable, 0)
 }
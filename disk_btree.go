@@ -0,0 +1,544 @@
+package dbmsgo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// Страница узла: type u8, pad u8, count u16, next u32, затем ключи int64.
+// У внутреннего узла после места под ключи идут номера детей u32.
+const (
+	diskNodeHeaderSize = 8
+
+	diskLeafPage     = 1
+	diskInternalPage = 2
+)
+
+type diskNode struct {
+	id       uint32
+	leaf     bool
+	keys     []int64
+	children []uint32
+	next     uint32 // следующий лист, 0 - последний
+}
+
+// DiskBPlusTree - B+-дерево в файле страниц. Ключи лежат только в листьях,
+// связанных в список, поэтому полный обход читает страницы по порядку.
+// В памяти находятся только страницы из буферного пула.
+type DiskBPlusTree struct {
+	name        string
+	path        string
+	pager       *Pager
+	maxLeaf     int
+	maxInternal int
+	err         error // первая ошибка ввода-вывода
+}
+
+// OpenDiskBPlusTree открывает дерево в файле path или создаёт новое
+func OpenDiskBPlusTree(name, path string) (*DiskBPlusTree, error) {
+	return OpenDiskBPlusTreeWithOptions(name, path, defaultPageSize, defaultPoolSize)
+}
+
+// OpenDiskBPlusTreeWithOptions задаёт размер страницы новой базы и
+// размер буферного пула в страницах
+func OpenDiskBPlusTreeWithOptions(name, path string, pageSize, poolSize int) (*DiskBPlusTree, error) {
+	pager, err := OpenPager(path, pageSize, poolSize)
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize = pager.GetPageSize()
+	return &DiskBPlusTree{
+		name:        name,
+		path:        path,
+		pager:       pager,
+		maxLeaf:     (pageSize - diskNodeHeaderSize) / 8,
+		maxInternal: (pageSize - diskNodeHeaderSize - 4) / 12,
+	}, nil
+}
+
+func (t *DiskBPlusTree) minLeaf() int {
+	return t.maxLeaf / 2
+}
+
+func (t *DiskBPlusTree) minInternal() int {
+	return t.maxInternal / 2
+}
+
+func (t *DiskBPlusTree) readNode(id uint32) (*diskNode, error) {
+	data, err := t.pager.page(id)
+	if err != nil {
+		return nil, err
+	}
+
+	count := int(binary.LittleEndian.Uint16(data[2:]))
+	node := &diskNode{
+		id:   id,
+		leaf: data[0] == diskLeafPage,
+		next: binary.LittleEndian.Uint32(data[4:]),
+	}
+	if data[0] != diskLeafPage && data[0] != diskInternalPage {
+		return nil, fmt.Errorf("page %d is not a tree node", id)
+	}
+	if (node.leaf && count > t.maxLeaf) || (!node.leaf && count > t.maxInternal) {
+		return nil, fmt.Errorf("page %d has invalid key count %d", id, count)
+	}
+
+	node.keys = make([]int64, count)
+	for i := range node.keys {
+		node.keys[i] = int64(binary.LittleEndian.Uint64(data[diskNodeHeaderSize+8*i:]))
+	}
+	if !node.leaf {
+		offset := diskNodeHeaderSize + 8*t.maxInternal
+		node.children = make([]uint32, count+1)
+		for i := range node.children {
+			node.children[i] = binary.LittleEndian.Uint32(data[offset+4*i:])
+		}
+	}
+	return node, nil
+}
+
+func (t *DiskBPlusTree) writeNode(node *diskNode) error {
+	data, err := t.pager.page(node.id)
+	if err != nil {
+		return err
+	}
+
+	clear(data)
+	if node.leaf {
+		data[0] = diskLeafPage
+	} else {
+		data[0] = diskInternalPage
+	}
+	binary.LittleEndian.PutUint16(data[2:], uint16(len(node.keys)))
+	binary.LittleEndian.PutUint32(data[4:], node.next)
+	for i, key := range node.keys {
+		binary.LittleEndian.PutUint64(data[diskNodeHeaderSize+8*i:], uint64(key))
+	}
+	if !node.leaf {
+		offset := diskNodeHeaderSize + 8*t.maxInternal
+		for i, child := range node.children {
+			binary.LittleEndian.PutUint32(data[offset+4*i:], child)
+		}
+	}
+	t.pager.markDirty(node.id)
+	return nil
+}
+
+func (t *DiskBPlusTree) newNode(leaf bool) (*diskNode, error) {
+	id, err := t.pager.allocate()
+	if err != nil {
+		return nil, err
+	}
+	return &diskNode{id: id, leaf: leaf}, nil
+}
+
+// childIndex возвращает ребёнка, в поддереве которого может быть key
+func (n *diskNode) childIndex(key int64) int {
+	return sort.Search(len(n.keys), func(i int) bool { return n.keys[i] > key })
+}
+
+func (n *diskNode) keyIndex(key int64) (int, bool) {
+	i := sort.Search(len(n.keys), func(i int) bool { return n.keys[i] >= key })
+	return i, i < len(n.keys) && n.keys[i] == key
+}
+
+// fail запоминает первую ошибку: методы OrderedSet ошибок не возвращают
+func (t *DiskBPlusTree) fail(err error) {
+	if err != nil && t.err == nil {
+		t.err = err
+	}
+}
+
+// Err возвращает первую ошибку ввода-вывода
+func (t *DiskBPlusTree) Err() error {
+	return t.err
+}
+
+func (t *DiskBPlusTree) findLeaf(key int64) (*diskNode, error) {
+	node, err := t.readNode(t.pager.root)
+	for err == nil && !node.leaf {
+		node, err = t.readNode(node.children[node.childIndex(key)])
+	}
+	return node, err
+}
+
+func (t *DiskBPlusTree) Contains(value int64) bool {
+	if t.pager.root == 0 {
+		return false
+	}
+	leaf, err := t.findLeaf(value)
+	if err != nil {
+		t.fail(err)
+		return false
+	}
+	_, found := leaf.keyIndex(value)
+	return found
+}
+
+func (t *DiskBPlusTree) Insert(value int64) {
+	t.fail(t.insert(value))
+}
+
+func (t *DiskBPlusTree) insert(key int64) error {
+	if t.pager.root == 0 {
+		root, err := t.newNode(true)
+		if err != nil {
+			return err
+		}
+		t.pager.root = root.id
+		if err := t.writeNode(root); err != nil {
+			return err
+		}
+	}
+
+	inserted, split, separator, err := t.insertInto(t.pager.root, key)
+	if err != nil || !inserted {
+		return err
+	}
+	t.pager.keys++
+	if split == nil {
+		return nil
+	}
+
+	// Корень разделился - дерево растёт на уровень
+	root, err := t.newNode(false)
+	if err != nil {
+		return err
+	}
+	root.keys = []int64{separator}
+	root.children = []uint32{t.pager.root, split.id}
+	t.pager.root = root.id
+	return t.writeNode(root)
+}
+
+// insertInto вставляет ключ в поддерево. Если узел переполнился, он
+// делится, и вызывающему возвращаются новый правый узел и разделитель.
+func (t *DiskBPlusTree) insertInto(id uint32, key int64) (bool, *diskNode, int64, error) {
+	node, err := t.readNode(id)
+	if err != nil {
+		return false, nil, 0, err
+	}
+
+	if node.leaf {
+		i, found := node.keyIndex(key)
+		if found {
+			return false, nil, 0, nil
+		}
+		node.keys = append(node.keys, 0)
+		copy(node.keys[i+1:], node.keys[i:])
+		node.keys[i] = key
+		if len(node.keys) <= t.maxLeaf {
+			return true, nil, 0, t.writeNode(node)
+		}
+
+		right, err := t.newNode(true)
+		if err != nil {
+			return false, nil, 0, err
+		}
+		mid := len(node.keys) / 2
+		right.keys = append([]int64(nil), node.keys[mid:]...)
+		right.next = node.next
+		node.keys = node.keys[:mid]
+		node.next = right.id
+		if err := t.writeNode(node); err != nil {
+			return false, nil, 0, err
+		}
+		return true, right, right.keys[0], t.writeNode(right)
+	}
+
+	i := node.childIndex(key)
+	inserted, child, separator, err := t.insertInto(node.children[i], key)
+	if err != nil || child == nil {
+		return inserted, nil, 0, err
+	}
+
+	node.keys = append(node.keys, 0)
+	copy(node.keys[i+1:], node.keys[i:])
+	node.keys[i] = separator
+	node.children = append(node.children, 0)
+	copy(node.children[i+2:], node.children[i+1:])
+	node.children[i+1] = child.id
+	if len(node.keys) <= t.maxInternal {
+		return true, nil, 0, t.writeNode(node)
+	}
+
+	// Средний ключ внутреннего узла поднимается выше и в узлах не остаётся
+	right, err := t.newNode(false)
+	if err != nil {
+		return false, nil, 0, err
+	}
+	mid := len(node.keys) / 2
+	promoted := node.keys[mid]
+	right.keys = append([]int64(nil), node.keys[mid+1:]...)
+	right.children = append([]uint32(nil), node.children[mid+1:]...)
+	node.keys = node.keys[:mid]
+	node.children = node.children[:mid+1]
+	if err := t.writeNode(node); err != nil {
+		return false, nil, 0, err
+	}
+	return true, right, promoted, t.writeNode(right)
+}
+
+func (t *DiskBPlusTree) Remove(value int64) {
+	t.fail(t.remove(value))
+}
+
+func (t *DiskBPlusTree) remove(key int64) error {
+	if t.pager.root == 0 {
+		return nil
+	}
+
+	removed, _, err := t.removeFrom(t.pager.root, key)
+	if err != nil || !removed {
+		return err
+	}
+	t.pager.keys--
+
+	root, err := t.readNode(t.pager.root)
+	if err != nil {
+		return err
+	}
+	if !root.leaf && len(root.keys) == 0 {
+		t.pager.root = root.children[0]
+		return t.pager.free(root.id)
+	}
+	if root.leaf && len(root.keys) == 0 {
+		t.pager.root = 0
+		return t.pager.free(root.id)
+	}
+	return nil
+}
+
+// removeFrom удаляет ключ из поддерева и сообщает, что узлу стало не
+// хватать ключей. Недостача исправляется родителем через соседа.
+func (t *DiskBPlusTree) removeFrom(id uint32, key int64) (bool, bool, error) {
+	node, err := t.readNode(id)
+	if err != nil {
+		return false, false, err
+	}
+
+	if node.leaf {
+		i, found := node.keyIndex(key)
+		if !found {
+			return false, false, nil
+		}
+		node.keys = append(node.keys[:i], node.keys[i+1:]...)
+		return true, len(node.keys) < t.minLeaf(), t.writeNode(node)
+	}
+
+	i := node.childIndex(key)
+	removed, underflow, err := t.removeFrom(node.children[i], key)
+	if err != nil || !underflow {
+		return removed, false, err
+	}
+	if err := t.rebalance(node, i); err != nil {
+		return removed, false, err
+	}
+	return removed, len(node.keys) < t.minInternal(), nil
+}
+
+// rebalance пополняет ребёнка i родителя: берёт ключ у соседа, а если у
+// соседа лишних нет - сливает их и освобождает страницу
+func (t *DiskBPlusTree) rebalance(parent *diskNode, i int) error {
+	left := i - 1
+	if i == 0 {
+		left = 0
+	}
+	leftNode, err := t.readNode(parent.children[left])
+	if err != nil {
+		return err
+	}
+	rightNode, err := t.readNode(parent.children[left+1])
+	if err != nil {
+		return err
+	}
+
+	if leftNode.leaf {
+		switch {
+		case left+1 == i && len(leftNode.keys) > t.minLeaf():
+			// Последний ключ левого соседа переходит в начало ребёнка
+			last := len(leftNode.keys) - 1
+			rightNode.keys = append([]int64{leftNode.keys[last]}, rightNode.keys...)
+			leftNode.keys = leftNode.keys[:last]
+			parent.keys[left] = rightNode.keys[0]
+		case left == i && len(rightNode.keys) > t.minLeaf():
+			leftNode.keys = append(leftNode.keys, rightNode.keys[0])
+			rightNode.keys = append(rightNode.keys[:0], rightNode.keys[1:]...)
+			parent.keys[left] = rightNode.keys[0]
+		default:
+			leftNode.keys = append(leftNode.keys, rightNode.keys...)
+			leftNode.next = rightNode.next
+			return t.mergeChildren(parent, left, leftNode, rightNode)
+		}
+	} else {
+		switch {
+		case left+1 == i && len(leftNode.keys) > t.minInternal():
+			last := len(leftNode.keys) - 1
+			rightNode.keys = append([]int64{parent.keys[left]}, rightNode.keys...)
+			rightNode.children = append([]uint32{leftNode.children[last+1]}, rightNode.children...)
+			parent.keys[left] = leftNode.keys[last]
+			leftNode.keys = leftNode.keys[:last]
+			leftNode.children = leftNode.children[:last+1]
+		case left == i && len(rightNode.keys) > t.minInternal():
+			leftNode.keys = append(leftNode.keys, parent.keys[left])
+			leftNode.children = append(leftNode.children, rightNode.children[0])
+			parent.keys[left] = rightNode.keys[0]
+			rightNode.keys = append(rightNode.keys[:0], rightNode.keys[1:]...)
+			rightNode.children = append(rightNode.children[:0], rightNode.children[1:]...)
+		default:
+			leftNode.keys = append(leftNode.keys, parent.keys[left])
+			leftNode.keys = append(leftNode.keys, rightNode.keys...)
+			leftNode.children = append(leftNode.children, rightNode.children...)
+			return t.mergeChildren(parent, left, leftNode, rightNode)
+		}
+	}
+
+	if err := t.writeNode(leftNode); err != nil {
+		return err
+	}
+	if err := t.writeNode(rightNode); err != nil {
+		return err
+	}
+	return t.writeNode(parent)
+}
+
+// mergeChildren убирает из родителя разделитель left и правого ребёнка
+func (t *DiskBPlusTree) mergeChildren(parent *diskNode, left int, leftNode, rightNode *diskNode) error {
+	parent.keys = append(parent.keys[:left], parent.keys[left+1:]...)
+	parent.children = append(parent.children[:left+1], parent.children[left+2:]...)
+	if err := t.writeNode(leftNode); err != nil {
+		return err
+	}
+	if err := t.writeNode(parent); err != nil {
+		return err
+	}
+	return t.pager.free(rightNode.id)
+}
+
+// firstLeaf возвращает самый левый лист
+func (t *DiskBPlusTree) firstLeaf() (*diskNode, error) {
+	node, err := t.readNode(t.pager.root)
+	for err == nil && !node.leaf {
+		node, err = t.readNode(node.children[0])
+	}
+	return node, err
+}
+
+// scan обходит ключи от lo по возрастанию по цепочке листьев, пока visit
+// возвращает true
+func (t *DiskBPlusTree) scan(lo int64, fromStart bool, visit func(key int64) bool) error {
+	if t.pager.root == 0 {
+		return nil
+	}
+
+	var leaf *diskNode
+	var err error
+	if fromStart {
+		leaf, err = t.firstLeaf()
+	} else {
+		leaf, err = t.findLeaf(lo)
+	}
+	for err == nil {
+		for _, key := range leaf.keys {
+			if (fromStart || key >= lo) && !visit(key) {
+				return nil
+			}
+		}
+		if leaf.next == 0 {
+			return nil
+		}
+		leaf, err = t.readNode(leaf.next)
+	}
+	return err
+}
+
+func (t *DiskBPlusTree) SaveTree() []int64 {
+	result := make([]int64, 0, t.Size())
+	t.fail(t.scan(0, true, func(key int64) bool {
+		result = append(result, key)
+		return true
+	}))
+	return result
+}
+
+// Range возвращает ключи из [lo, hi], не больше limit (0 - без ограничения).
+// Читаются только листья, пересекающие диапазон.
+func (t *DiskBPlusTree) Range(lo, hi int64, limit int) []int64 {
+	result := make([]int64, 0)
+	t.fail(t.scan(lo, false, func(key int64) bool {
+		if key > hi {
+			return false
+		}
+		result = append(result, key)
+		return limit <= 0 || len(result) < limit
+	}))
+	return result
+}
+
+// BuildFromSorted заменяет содержимое дерева значениями из строго
+// возрастающей последовательности
+func (t *DiskBPlusTree) BuildFromSorted(values []int64) error {
+	if err := checkSortedValues(values); err != nil {
+		return err
+	}
+	if err := t.pager.reset(); err != nil {
+		return err
+	}
+	for _, value := range values {
+		if err := t.insert(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush записывает изменённые страницы на диск
+func (t *DiskBPlusTree) Flush() error {
+	if t.err != nil {
+		return t.err
+	}
+	return t.pager.Flush()
+}
+
+func (t *DiskBPlusTree) Close() error {
+	if t.err != nil {
+		t.pager.Close()
+		return t.err
+	}
+	return t.pager.Close()
+}
+
+func (t *DiskBPlusTree) GetName() string {
+	return t.name
+}
+
+func (t *DiskBPlusTree) GetPath() string {
+	return t.path
+}
+
+func (t *DiskBPlusTree) GetPager() *Pager {
+	return t.pager
+}
+
+func (t *DiskBPlusTree) GetEngine() TreeEngine {
+	return DISK_ENGINE
+}
+
+func (t *DiskBPlusTree) Size() int {
+	return int(t.pager.keys)
+}
+
+func (t *DiskBPlusTree) IsEmpty() bool {
+	return t.pager.keys == 0
+}
+
+func (t *DiskBPlusTree) PrintInOrder() {
+	printOrderedSet(t)
+}
+
+// Cleanup удаляет все ключи и обрезает файл до заголовка
+func (t *DiskBPlusTree) Cleanup() {
+	t.fail(t.pager.reset())
+}
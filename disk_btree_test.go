@@ -0,0 +1,176 @@
+package dbmsgo
+
+import (
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskBPlusTree(t *testing.T) {
+	t.Run("RandomOperations", func(t *testing.T) {
+		// Маленькие страницы и пул, чтобы чаще делились узлы и вытеснялись страницы
+		tree, err := OpenDiskBPlusTreeWithOptions("disk", filepath.Join(t.TempDir(), "tree.db"), minPageSize, 4)
+		assert.NoError(t, err)
+		defer tree.Close()
+
+		rng := rand.New(rand.NewSource(10))
+		present := make(map[int64]bool)
+		for i := 0; i < 20000; i++ {
+			value := rng.Int63n(3000) - 1500
+			if rng.Intn(3) == 0 {
+				tree.Remove(value)
+				delete(present, value)
+			} else {
+				tree.Insert(value)
+				present[value] = true
+			}
+			if i%2000 == 0 {
+				checkDiskTreeInvariants(t, tree)
+			}
+		}
+		assert.NoError(t, tree.Err())
+		checkDiskTreeInvariants(t, tree)
+
+		expected := make([]int64, 0, len(present))
+		for value := range present {
+			expected = append(expected, value)
+		}
+		sort.Slice(expected, func(i, j int) bool { return expected[i] < expected[j] })
+		assert.Equal(t, expected, tree.SaveTree())
+		assert.Equal(t, len(expected), tree.Size())
+		assert.LessOrEqual(t, tree.GetPager().CachedPages(), 4)
+	})
+
+	t.Run("Reopen", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "tree.db")
+		tree, err := OpenDiskBPlusTreeWithOptions("disk", path, 256, 8)
+		assert.NoError(t, err)
+		for i := 0; i < 5000; i++ {
+			tree.Insert(int64(i * 7))
+		}
+		assert.NoError(t, tree.Close())
+
+		reopened, err := OpenDiskBPlusTree("disk", path)
+		assert.NoError(t, err)
+		defer reopened.Close()
+		assert.Equal(t, 5000, reopened.Size())
+		assert.True(t, reopened.Contains(700))
+		assert.False(t, reopened.Contains(701))
+		assert.Equal(t, []int64{70, 77, 84}, reopened.Range(70, 90, 0))
+		assert.Equal(t, []int64{70, 77}, reopened.Range(70, 90, 2))
+		// Поиск читает только страницы одного пути от корня
+		reads, _, _ := reopened.GetPager().Stats()
+		assert.Less(t, reads, 10)
+	})
+
+	t.Run("FreedPagesAreReused", func(t *testing.T) {
+		tree, err := OpenDiskBPlusTreeWithOptions("disk", filepath.Join(t.TempDir(), "tree.db"), minPageSize, 16)
+		assert.NoError(t, err)
+		defer tree.Close()
+
+		for i := 0; i < 2000; i++ {
+			tree.Insert(int64(i))
+		}
+		pages := tree.GetPager().GetPageCount()
+		for i := 0; i < 2000; i++ {
+			tree.Remove(int64(i))
+		}
+		assert.True(t, tree.IsEmpty())
+		assert.Equal(t, pages-1, tree.GetPager().FreePages())
+
+		for i := 0; i < 2000; i++ {
+			tree.Insert(int64(i))
+		}
+		assert.Equal(t, pages, tree.GetPager().GetPageCount())
+		checkDiskTreeInvariants(t, tree)
+	})
+
+	t.Run("BuildFromSortedAndCleanup", func(t *testing.T) {
+		tree, err := OpenDiskBPlusTreeWithOptions("disk", filepath.Join(t.TempDir(), "tree.db"), minPageSize, 4)
+		assert.NoError(t, err)
+		defer tree.Close()
+
+		tree.Insert(99)
+		assert.NoError(t, tree.BuildFromSorted([]int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}))
+		assert.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, tree.SaveTree())
+		assert.Error(t, tree.BuildFromSorted([]int64{3, 1}))
+
+		tree.Cleanup()
+		assert.True(t, tree.IsEmpty())
+		assert.Equal(t, 1, tree.GetPager().GetPageCount())
+		assert.Empty(t, tree.SaveTree())
+		assert.False(t, tree.Contains(1))
+		assert.NoError(t, tree.Err())
+	})
+}
+
+// checkDiskTreeInvariants проверяет заполненность узлов, порядок ключей,
+// одинаковую глубину листьев и цепочку листьев
+func checkDiskTreeInvariants(t *testing.T, tree *DiskBPlusTree) {
+	if tree.pager.root == 0 {
+		assert.Equal(t, 0, tree.Size())
+		return
+	}
+
+	leafDepth := -1
+	leaves := make([]uint32, 0)
+	var walk func(id uint32, depth int, lo, hi *int64)
+	walk = func(id uint32, depth int, lo, hi *int64) {
+		node, err := tree.readNode(id)
+		if !assert.NoError(t, err) {
+			return
+		}
+		isRoot := id == tree.pager.root
+		for i, key := range node.keys {
+			if i > 0 {
+				assert.Greater(t, key, node.keys[i-1])
+			}
+			if lo != nil {
+				assert.GreaterOrEqual(t, key, *lo)
+			}
+			if hi != nil {
+				assert.Less(t, key, *hi)
+			}
+		}
+
+		if node.leaf {
+			if !isRoot {
+				assert.GreaterOrEqual(t, len(node.keys), tree.minLeaf())
+			}
+			if leafDepth < 0 {
+				leafDepth = depth
+			}
+			assert.Equal(t, leafDepth, depth)
+			leaves = append(leaves, id)
+			return
+		}
+
+		if !isRoot {
+			assert.GreaterOrEqual(t, len(node.keys), tree.minInternal())
+		}
+		for i, child := range node.children {
+			childLo, childHi := lo, hi
+			if i > 0 {
+				childLo = &node.keys[i-1]
+			}
+			if i < len(node.keys) {
+				childHi = &node.keys[i]
+			}
+			walk(child, depth+1, childLo, childHi)
+		}
+	}
+	walk(tree.pager.root, 0, nil, nil)
+
+	for i, id := range leaves {
+		node, _ := tree.readNode(id)
+		if i+1 < len(leaves) {
+			assert.Equal(t, leaves[i+1], node.next)
+		} else {
+			assert.Equal(t, uint32(0), node.next)
+		}
+	}
+	assert.Equal(t, tree.Size(), len(tree.SaveTree()))
+}
@@ -12,6 +12,7 @@ type DoublyLinkedList struct {
 	name string
 	head *DLLNode
 	tail *DLLNode
+	size int
 }
 
 func NewDoublyLinkedList(name string) *DoublyLinkedList {
@@ -31,6 +32,7 @@ func (d *DoublyLinkedList) PushFront(value string) {
 	if d.tail == nil {
 		d.tail = newNode
 	}
+	d.size++
 }
 
 func (d *DoublyLinkedList) PushBack(value string) {
@@ -42,6 +44,7 @@ func (d *DoublyLinkedList) PushBack(value string) {
 	if d.head == nil {
 		d.head = newNode
 	}
+	d.size++
 }
 
 func (d *DoublyLinkedList) InsertBefore(target, value string) {
@@ -58,6 +61,7 @@ func (d *DoublyLinkedList) InsertBefore(target, value string) {
 			d.head = newNode
 		}
 		current.Prev = newNode
+		d.size++
 	}
 }
 
@@ -75,6 +79,7 @@ func (d *DoublyLinkedList) InsertAfter(target, value string) {
 			d.tail = newNode
 		}
 		current.Next = newNode
+		d.size++
 	}
 }
 
@@ -89,6 +94,7 @@ func (d *DoublyLinkedList) DeleteFront() {
 	} else {
 		d.tail = nil
 	}
+	d.size--
 }
 
 func (d *DoublyLinkedList) DeleteBack() {
@@ -102,25 +108,39 @@ func (d *DoublyLinkedList) DeleteBack() {
 	} else {
 		d.head = nil
 	}
+	d.size--
 }
 
 func (d *DoublyLinkedList) DeleteByValue(value string) {
-	current := d.head
-	for current != nil {
-		if current.Data == value {
-			if current.Prev != nil {
-				current.Prev.Next = current.Next
-			} else {
-				d.head = current.Next
-			}
-			if current.Next != nil {
-				current.Next.Prev = current.Prev
-			} else {
-				d.tail = current.Prev
+	d.RemoveValue(value, 0)
+}
+
+// RemoveValue удаляет элементы, равные value, как LREM в Redis: count > 0 -
+// первые count совпадений с начала, count < 0 - первые |count| с конца,
+// count == 0 - все. Возвращает число удалённых элементов.
+func (d *DoublyLinkedList) RemoveValue(value string, count int) int {
+	removed := 0
+	if count < 0 {
+		for current := d.tail; current != nil && removed < -count; {
+			prev := current.Prev
+			if current.Data == value {
+				d.unlink(current)
+				removed++
 			}
+			current = prev
 		}
-		current = current.Next
+		return removed
 	}
+
+	for current := d.head; current != nil && (count == 0 || removed < count); {
+		next := current.Next
+		if current.Data == value {
+			d.unlink(current)
+			removed++
+		}
+		current = next
+	}
+	return removed
 }
 
 func (d *DoublyLinkedList) FindByValue(value string) *DLLNode {
@@ -134,6 +154,296 @@ func (d *DoublyLinkedList) FindByValue(value string) *DLLNode {
 	return nil
 }
 
+// IndexOf возвращает номер первого элемента, равного value, или -1
+func (d *DoublyLinkedList) IndexOf(value string) int {
+	index := 0
+	for current := d.head; current != nil; current = current.Next {
+		if current.Data == value {
+			return index
+		}
+		index++
+	}
+	return -1
+}
+
+// LastIndexOf ищет с конца и возвращает номер последнего элемента,
+// равного value, или -1
+func (d *DoublyLinkedList) LastIndexOf(value string) int {
+	index := d.size - 1
+	for current := d.tail; current != nil; current = current.Prev {
+		if current.Data == value {
+			return index
+		}
+		index--
+	}
+	return -1
+}
+
+// nodeAt возвращает узел с номером index или nil. Обход идёт от
+// ближайшего конца, поэтому проходится не больше половины списка.
+func (d *DoublyLinkedList) nodeAt(index int) *DLLNode {
+	if index < 0 || index >= d.size {
+		return nil
+	}
+	if index < d.size/2 {
+		current := d.head
+		for i := 0; i < index; i++ {
+			current = current.Next
+		}
+		return current
+	}
+	current := d.tail
+	for i := d.size - 1; i > index; i-- {
+		current = current.Prev
+	}
+	return current
+}
+
+// unlink исключает узел из списка
+func (d *DoublyLinkedList) unlink(node *DLLNode) {
+	if node.Prev != nil {
+		node.Prev.Next = node.Next
+	} else {
+		d.head = node.Next
+	}
+	if node.Next != nil {
+		node.Next.Prev = node.Prev
+	} else {
+		d.tail = node.Prev
+	}
+	node.Prev = nil
+	node.Next = nil
+	d.size--
+}
+
+func (d *DoublyLinkedList) Length() int {
+	return d.size
+}
+
+func (d *DoublyLinkedList) Get(index int) (string, error) {
+	node := d.nodeAt(index)
+	if node == nil {
+		return "", fmt.Errorf("index out of range")
+	}
+	return node.Data, nil
+}
+
+func (d *DoublyLinkedList) Set(index int, value string) error {
+	node := d.nodeAt(index)
+	if node == nil {
+		return fmt.Errorf("index out of range")
+	}
+	node.Data = value
+	return nil
+}
+
+// InsertAt вставляет значение так, чтобы оно получило номер index;
+// index == Length() добавляет в конец
+func (d *DoublyLinkedList) InsertAt(index int, value string) error {
+	if index < 0 || index > d.size {
+		return fmt.Errorf("index out of range")
+	}
+	if index == d.size {
+		d.PushBack(value)
+		return nil
+	}
+
+	next := d.nodeAt(index)
+	newNode := &DLLNode{Data: value, Prev: next.Prev, Next: next}
+	if next.Prev != nil {
+		next.Prev.Next = newNode
+	} else {
+		d.head = newNode
+	}
+	next.Prev = newNode
+	d.size++
+	return nil
+}
+
+// RemoveAt удаляет элемент с номером index и возвращает его значение
+func (d *DoublyLinkedList) RemoveAt(index int) (string, error) {
+	node := d.nodeAt(index)
+	if node == nil {
+		return "", fmt.Errorf("index out of range")
+	}
+	d.unlink(node)
+	return node.Data, nil
+}
+
+// Range возвращает элементы с start по stop включительно; отрицательные
+// индексы отсчитываются от конца
+func (d *DoublyLinkedList) Range(start, stop int) []string {
+	from, to := normalizeRange(start, stop, d.size)
+	result := make([]string, 0, to-from)
+	if from == to {
+		return result
+	}
+	for current := d.nodeAt(from); len(result) < to-from; current = current.Next {
+		result = append(result, current.Data)
+	}
+	return result
+}
+
+// mergeDLLNodes сливает два отсортированных по Next списка узлов; ссылки
+// Prev восстанавливаются после сортировки
+func mergeDLLNodes(left, right *DLLNode, compare Comparator) *DLLNode {
+	dummy := &DLLNode{}
+	tail := dummy
+	for left != nil && right != nil {
+		if compare(left.Data, right.Data) <= 0 {
+			tail.Next = left
+			left = left.Next
+		} else {
+			tail.Next = right
+			right = right.Next
+		}
+		tail = tail.Next
+	}
+	if left != nil {
+		tail.Next = left
+	} else {
+		tail.Next = right
+	}
+	return dummy.Next
+}
+
+// mergeSortDLLNodes сортирует length узлов начиная с head
+func mergeSortDLLNodes(head *DLLNode, length int, compare Comparator) *DLLNode {
+	if length < 2 {
+		return head
+	}
+	middle := head
+	for i := 1; i < length/2; i++ {
+		middle = middle.Next
+	}
+	right := middle.Next
+	middle.Next = nil
+	return mergeDLLNodes(
+		mergeSortDLLNodes(head, length/2, compare),
+		mergeSortDLLNodes(right, length-length/2, compare),
+		compare,
+	)
+}
+
+// Sort устойчиво сортирует список слиянием без выделения новых узлов
+func (d *DoublyLinkedList) Sort(compare Comparator) {
+	d.head = mergeSortDLLNodes(d.head, d.size, compare)
+	var prev *DLLNode
+	for current := d.head; current != nil; current = current.Next {
+		current.Prev = prev
+		prev = current
+	}
+	d.tail = prev
+}
+
+func (d *DoublyLinkedList) Reverse() {
+	for current := d.head; current != nil; current = current.Prev {
+		current.Next, current.Prev = current.Prev, current.Next
+	}
+	d.head, d.tail = d.tail, d.head
+}
+
+// Unique удаляет повторы: подряд идущие или, если all, все кроме первого
+// вхождения. Возвращает число удалённых элементов.
+func (d *DoublyLinkedList) Unique(all bool) int {
+	var seen map[string]bool
+	if all {
+		seen = make(map[string]bool)
+	}
+	removed := 0
+	for current := d.head; current != nil; {
+		next := current.Next
+		duplicate := current.Prev != nil && current.Data == current.Prev.Data
+		if all {
+			duplicate = seen[current.Data]
+			seen[current.Data] = true
+		}
+		if duplicate {
+			d.unlink(current)
+			removed++
+		}
+		current = next
+	}
+	return removed
+}
+
+// Concat за O(1) переносит все элементы other в конец списка, other
+// становится пустым
+func (d *DoublyLinkedList) Concat(other *DoublyLinkedList) {
+	if other == d || other.head == nil {
+		return
+	}
+	if d.tail == nil {
+		d.head = other.head
+	} else {
+		d.tail.Next = other.head
+		other.head.Prev = d.tail
+	}
+	d.tail = other.tail
+	d.size += other.size
+	other.head = nil
+	other.tail = nil
+	other.size = 0
+}
+
+// Splice переносит элементы с start по stop включительно в список dst так,
+// чтобы первый из них получил номер index. Отрицательные start и stop
+// отсчитываются от конца. Если dst - тот же список, index считается после
+// изъятия диапазона. Возвращает число перенесённых элементов.
+func (d *DoublyLinkedList) Splice(start, stop int, dst *DoublyLinkedList, index int) (int, error) {
+	from, to := normalizeRange(start, stop, d.size)
+	count := to - from
+	limit := dst.size
+	if dst == d {
+		limit -= count
+	}
+	if index < 0 || index > limit {
+		return 0, fmt.Errorf("index out of range")
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	// Вырезаем узлы [from, to)
+	first, last := d.nodeAt(from), d.nodeAt(to-1)
+	if first.Prev != nil {
+		first.Prev.Next = last.Next
+	} else {
+		d.head = last.Next
+	}
+	if last.Next != nil {
+		last.Next.Prev = first.Prev
+	} else {
+		d.tail = first.Prev
+	}
+	first.Prev = nil
+	last.Next = nil
+	d.size -= count
+
+	// Вставляем перед узлом с номером index
+	var before *DLLNode
+	if index > 0 {
+		before = dst.nodeAt(index - 1)
+	}
+	var after *DLLNode
+	if before != nil {
+		after = before.Next
+		before.Next = first
+	} else {
+		after = dst.head
+		dst.head = first
+	}
+	first.Prev = before
+	last.Next = after
+	if after != nil {
+		after.Prev = last
+	} else {
+		dst.tail = last
+	}
+	dst.size += count
+	return count, nil
+}
+
 func (d *DoublyLinkedList) PrintForward() {
 	fmt.Printf("Двусвязный список '%s' (прямой): ", d.name)
 	current := d.head
@@ -179,4 +489,5 @@ func (d *DoublyLinkedList) GetTail() *DLLNode {
 func (d *DoublyLinkedList) Cleanup() {
 	d.head = nil
 	d.tail = nil
+	d.size = 0
 }
@@ -1,6 +1,7 @@
 package dbmsgo
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -111,4 +112,229 @@ func TestDoublyLinkedList(t *testing.T) {
 		assert.Nil(t, dll.GetHead())
 		assert.Nil(t, dll.GetTail())
 	})
+
+	t.Run("Length", func(t *testing.T) {
+		dll := NewDoublyLinkedList("test_dll")
+		assert.Equal(t, 0, dll.Length())
+
+		dll.PushBack("a")
+		dll.PushFront("b")
+		dll.PushBack("a")
+		dll.InsertBefore("b", "c")
+		dll.InsertAfter("missing", "x")
+		assert.Equal(t, 4, dll.Length())
+
+		dll.DeleteByValue("a")
+		assert.Equal(t, 2, dll.Length())
+		dll.DeleteBack()
+		dll.DeleteFront()
+		dll.DeleteFront()
+		assert.Equal(t, 0, dll.Length())
+
+		dll.PushBack("a")
+		dll.Cleanup()
+		assert.Equal(t, 0, dll.Length())
+	})
+
+	t.Run("IndexOperations", func(t *testing.T) {
+		dll := NewDoublyLinkedList("test_dll")
+		for i := 0; i < 9; i++ {
+			assert.NoError(t, dll.InsertAt(i, fmt.Sprintf("v%d", i)))
+		}
+		assert.Error(t, dll.InsertAt(10, "x"))
+
+		// Элементы из обеих половин списка
+		for _, i := range []int{0, 3, 4, 5, 8} {
+			value, err := dll.Get(i)
+			assert.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("v%d", i), value)
+		}
+		_, err := dll.Get(9)
+		assert.Error(t, err)
+		_, err = dll.Get(-1)
+		assert.Error(t, err)
+
+		assert.NoError(t, dll.Set(7, "seven"))
+		value, _ := dll.Get(7)
+		assert.Equal(t, "seven", value)
+
+		assert.NoError(t, dll.InsertAt(6, "mid"))
+		assert.NoError(t, dll.InsertAt(0, "first"))
+		value, err = dll.RemoveAt(7)
+		assert.NoError(t, err)
+		assert.Equal(t, "mid", value)
+		value, err = dll.RemoveAt(dll.Length() - 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "v8", value)
+		value, err = dll.RemoveAt(0)
+		assert.NoError(t, err)
+		assert.Equal(t, "first", value)
+		assert.Equal(t, 8, dll.Length())
+
+		// Связи в обе стороны остаются согласованными
+		var forward, backward []string
+		for node := dll.GetHead(); node != nil; node = node.Next {
+			forward = append(forward, node.Data)
+		}
+		for node := dll.GetTail(); node != nil; node = node.Prev {
+			backward = append([]string{node.Data}, backward...)
+		}
+		assert.Equal(t, []string{"v0", "v1", "v2", "v3", "v4", "v5", "v6", "seven"}, forward)
+		assert.Equal(t, forward, backward)
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		dll := NewDoublyLinkedList("test_dll")
+		for _, value := range []string{"a", "b", "c", "d"} {
+			dll.PushBack(value)
+		}
+
+		assert.Equal(t, []string{"a", "b", "c", "d"}, dll.Range(0, -1))
+		assert.Equal(t, []string{"c", "d"}, dll.Range(2, 3))
+		assert.Equal(t, []string{"a", "b"}, dll.Range(-10, -3))
+		assert.Empty(t, dll.Range(4, -1))
+		assert.Empty(t, NewDoublyLinkedList("empty").Range(0, -1))
+	})
+
+	t.Run("Sort", func(t *testing.T) {
+		dll := NewDoublyLinkedList("test_dll")
+		for _, value := range []string{"10", "9", "1.0", "x", "2", "1"} {
+			dll.PushBack(value)
+		}
+
+		dll.Sort(NumericOrder)
+		assert.Equal(t, []string{"1.0", "1", "2", "9", "10", "x"}, dllValues(t, dll))
+		dll.Sort(LexicographicOrder)
+		assert.Equal(t, []string{"1", "1.0", "10", "2", "9", "x"}, dllValues(t, dll))
+	})
+
+	t.Run("Reverse", func(t *testing.T) {
+		dll := NewDoublyLinkedList("test_dll")
+		for _, value := range []string{"a", "b", "c"} {
+			dll.PushBack(value)
+		}
+
+		dll.Reverse()
+		assert.Equal(t, []string{"c", "b", "a"}, dllValues(t, dll))
+		empty := NewDoublyLinkedList("empty")
+		empty.Reverse()
+		assert.True(t, empty.IsEmpty())
+	})
+
+	t.Run("Unique", func(t *testing.T) {
+		dll := NewDoublyLinkedList("test_dll")
+		for _, value := range []string{"a", "a", "b", "a", "b", "b"} {
+			dll.PushBack(value)
+		}
+
+		assert.Equal(t, 2, dll.Unique(false))
+		assert.Equal(t, []string{"a", "b", "a", "b"}, dllValues(t, dll))
+		assert.Equal(t, 2, dll.Unique(true))
+		assert.Equal(t, []string{"a", "b"}, dllValues(t, dll))
+		assert.Equal(t, 2, dll.Length())
+	})
+
+	t.Run("Splice", func(t *testing.T) {
+		src := NewDoublyLinkedList("src")
+		dst := NewDoublyLinkedList("dst")
+		for _, value := range []string{"a", "b", "c", "d"} {
+			src.PushBack(value)
+		}
+		dst.PushBack("x")
+		dst.PushBack("y")
+
+		moved, err := src.Splice(1, 2, dst, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, moved)
+		assert.Equal(t, []string{"a", "d"}, dllValues(t, src))
+		assert.Equal(t, []string{"b", "c", "x", "y"}, dllValues(t, dst))
+
+		moved, err = src.Splice(0, -1, dst, 4)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, moved)
+		assert.Empty(t, dllValues(t, src))
+		assert.Equal(t, []string{"b", "c", "x", "y", "a", "d"}, dllValues(t, dst))
+
+		moved, err = src.Splice(0, -1, dst, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, moved)
+		_, err = dst.Splice(0, 0, src, 1)
+		assert.Error(t, err)
+
+		moved, err = dst.Splice(-2, -1, dst, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, moved)
+		assert.Equal(t, []string{"b", "a", "d", "c", "x", "y"}, dllValues(t, dst))
+	})
+
+	t.Run("Concat", func(t *testing.T) {
+		first := NewDoublyLinkedList("first")
+		second := NewDoublyLinkedList("second")
+		second.PushBack("c")
+		second.PushBack("d")
+
+		first.Concat(second)
+		assert.Equal(t, []string{"c", "d"}, dllValues(t, first))
+		assert.True(t, second.IsEmpty())
+		assert.Equal(t, 0, second.Length())
+
+		second.PushBack("e")
+		first.PushFront("b")
+		first.Concat(second)
+		assert.Equal(t, []string{"b", "c", "d", "e"}, dllValues(t, first))
+		assert.Equal(t, 4, first.Length())
+
+		first.Concat(first)
+		assert.Equal(t, 4, first.Length())
+	})
+	t.Run("RemoveValue", func(t *testing.T) {
+		fill := func() *DoublyLinkedList {
+			dll := NewDoublyLinkedList("test_dll")
+			for _, value := range []string{"a", "b", "a", "c", "a"} {
+				dll.PushBack(value)
+			}
+			return dll
+		}
+
+		dll := fill()
+		assert.Equal(t, 2, dll.RemoveValue("a", 2))
+		assert.Equal(t, []string{"b", "c", "a"}, dllValues(t, dll))
+
+		dll = fill()
+		assert.Equal(t, 2, dll.RemoveValue("a", -2))
+		assert.Equal(t, []string{"a", "b", "c"}, dllValues(t, dll))
+
+		dll = fill()
+		assert.Equal(t, 3, dll.RemoveValue("a", 0))
+		assert.Equal(t, []string{"b", "c"}, dllValues(t, dll))
+		assert.Equal(t, 0, dll.RemoveValue("a", -1))
+	})
+
+	t.Run("IndexOf", func(t *testing.T) {
+		dll := NewDoublyLinkedList("test_dll")
+		for _, value := range []string{"a", "b", "a"} {
+			dll.PushBack(value)
+		}
+
+		assert.Equal(t, 0, dll.IndexOf("a"))
+		assert.Equal(t, 2, dll.LastIndexOf("a"))
+		assert.Equal(t, 1, dll.IndexOf("b"))
+		assert.Equal(t, -1, dll.IndexOf("z"))
+		assert.Equal(t, -1, dll.LastIndexOf("z"))
+	})
+}
+
+// dllValues возвращает элементы списка от головы и проверяет, что обход
+// с хвоста по Prev и длина с ним согласованы
+func dllValues(t *testing.T, dll *DoublyLinkedList) []string {
+	var forward, backward []string
+	for node := dll.GetHead(); node != nil; node = node.Next {
+		forward = append(forward, node.Data)
+	}
+	for node := dll.GetTail(); node != nil; node = node.Prev {
+		backward = append([]string{node.Data}, backward...)
+	}
+	assert.Equal(t, forward, backward)
+	assert.Equal(t, len(forward), dll.Length())
+	return forward
 }
@@ -3,21 +3,65 @@ package dbmsgo
 import (
 	"bufio"
 	"fmt"
+	"math/bits"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// Одна запись занимает одну строку, а у больших деревьев она может весить
+// десятки мегабайт - стандартного буфера bufio.Scanner (64 КБ) не хватает.
+const maxRecordSize = 512 * 1024 * 1024
+
 type FileIO struct {
-	serializer *Serializer
+	serializer   *Serializer
+	treeEncoding TreeEncoding
+	hashEncoding HashEncoding
 }
 
 func NewFileIO() *FileIO {
 	return &FileIO{
-		serializer: NewSerializer(),
+		serializer:   NewSerializer(),
+		treeEncoding: TREE_SORTED,
+		hashEncoding: HASH_AUTO,
 	}
 }
 
+func (f *FileIO) SetTreeEncoding(encoding TreeEncoding) {
+	f.treeEncoding = encoding
+}
+
+func (f *FileIO) GetTreeEncoding() TreeEncoding {
+	return f.treeEncoding
+}
+
+func (f *FileIO) SetHashEncoding(encoding HashEncoding) {
+	f.hashEncoding = encoding
+}
+
+func (f *FileIO) GetHashEncoding() HashEncoding {
+	return f.hashEncoding
+}
+
+// needsHashLayout выбирает запись для таблицы. В режиме HASH_AUTO
+// раскладка нужна, если таблица потеряет настройки или порядок записей при
+// записи в старом формате. Старая запись загружается в таблицу по
+// умолчанию со случайным ключом SipHash, поэтому ключевая хеш-функция тоже
+// требует раскладки.
+func (f *FileIO) needsHashLayout(table *HashTable) bool {
+	switch f.hashEncoding {
+	case HASH_ENTRIES:
+		return false
+	case HASH_LAYOUT:
+		return true
+	}
+	return table.IsOrdered() || table.GetLoadFactor() != defaultHashLoadFactor ||
+		table.GetShrinkFactor() != defaultHashShrinkFactor || table.GetEngine() != CHAINING_ENGINE ||
+		table.GetCapacity() != defaultHashCapacity || table.GetMinCapacity() != defaultHashCapacity ||
+		table.GetHashFunction().Kind() != SIPHASH_HASH || table.GetHashFunction().Seed() != 0
+}
+
 func (f *FileIO) loadArray(db *Database, parts []string) {
 	if len(parts) < 3 {
 		return
@@ -140,7 +184,7 @@ func (f *FileIO) loadTree(db *Database, parts []string) {
 	
 	name := parts[1]
 	size, err := strconv.Atoi(parts[2])
-	if err != nil {
+	if err != nil || size < 0 {
 		return
 	}
 	
@@ -149,15 +193,205 @@ func (f *FileIO) loadTree(db *Database, parts []string) {
 	}
 	
 	tree := NewAVLTree(name)
+	values := make([]int64, 0, size)
 	for i := 0; i < size; i++ {
-		value, err := strconv.Atoi(parts[3+i])
+		value, err := strconv.ParseInt(parts[3+i], 10, 64)
 		if err == nil {
+			values = append(values, value)
+		}
+	}
+	
+	// Значения сохраняются in-order, поэтому обычно дерево строится за O(n);
+	// вставка по одному остаётся для старых и повреждённых файлов
+	if err := tree.BuildFromSorted(values); err != nil {
+		for _, value := range values {
 			tree.Insert(value)
 		}
 	}
 	db.AddTree(tree)
 }
 
+func (f *FileIO) loadTreeBig(db *Database, parts []string) {
+	if len(parts) < 3 {
+		return
+	}
+	
+	name := parts[1]
+	size, err := strconv.Atoi(parts[2])
+	if err != nil || size < 0 {
+		return
+	}
+	
+	if len(parts) < 3+size {
+		return
+	}
+	
+	tree := NewAVLTreeWithMode(name, BIGINT_KEYS)
+	keys := parts[3 : 3+size]
+	if err := tree.BuildFromSortedKeys(keys); err != nil {
+		for _, key := range keys {
+			tree.InsertKey(key)
+		}
+	}
+	db.AddTree(tree)
+}
+
+func (f *FileIO) loadTreeMap(db *Database, parts []string) {
+	if len(parts) < 4 {
+		return
+	}
+	
+	name := parts[1]
+	// Собственный компаратор в файл не записывается, такой словарь
+	// загружается с лексикографическим порядком
+	custom := parts[2] == CUSTOM_ORDER.String()
+	ordering, err := ParseTreeOrdering(parts[2])
+	if err != nil && !custom {
+		return
+	}
+	size, err := strconv.Atoi(parts[3])
+	if err != nil || size < 0 {
+		return
+	}
+	
+	if len(parts) < 4+size*2 {
+		return
+	}
+	
+	tree := NewOrderedMap(name, ordering)
+	keys := make([]string, size)
+	values := make([]string, size)
+	for i := 0; i < size; i++ {
+		keys[i] = parts[4+i*2]
+		values[i] = parts[4+i*2+1]
+	}
+	if err := tree.BuildFromSortedEntries(keys, values); err != nil {
+		for i := range keys {
+			tree.Put(keys[i], values[i])
+		}
+	}
+	db.AddTree(tree)
+}
+
+func (f *FileIO) loadTreeMulti(db *Database, parts []string) {
+	if len(parts) < 4 {
+		return
+	}
+	
+	name := parts[1]
+	mode, err := ParseTreeKeyMode(parts[2])
+	if err != nil || mode == STRING_KEYS {
+		return
+	}
+	size, err := strconv.Atoi(parts[3])
+	if err != nil || size < 0 {
+		return
+	}
+	
+	if len(parts) < 4+size*2 {
+		return
+	}
+	
+	keys := make([]string, size)
+	counts := make([]int, size)
+	for i := 0; i < size; i++ {
+		keys[i] = parts[4+i*2]
+		if counts[i], err = strconv.Atoi(parts[4+i*2+1]); err != nil {
+			return
+		}
+	}
+	
+	tree := NewMultisetTree(name, mode)
+	if err := tree.BuildFromSortedCounts(keys, counts); err != nil {
+		for i, key := range keys {
+			for j := 0; j < counts[i]; j++ {
+				tree.InsertKey(key)
+			}
+		}
+	}
+	db.AddTree(tree)
+}
+
+func (f *FileIO) loadTreeShape(db *Database, parts []string) {
+	if len(parts) < 3 {
+		return
+	}
+	
+	name := parts[1]
+	size, err := strconv.Atoi(parts[2])
+	if err != nil || size < 0 {
+		return
+	}
+	
+	if len(parts) < 3+size*2 {
+		return
+	}
+	
+	values := make([]int64, size)
+	heights := make([]int, size)
+	for i := 0; i < size; i++ {
+		if values[i], err = strconv.ParseInt(parts[3+i*2], 10, 64); err != nil {
+			return
+		}
+		if heights[i], err = strconv.Atoi(parts[3+i*2+1]); err != nil {
+			return
+		}
+	}
+	
+	tree := NewAVLTree(name)
+	if err := tree.BuildFromShape(values, heights); err != nil {
+		return
+	}
+	db.AddTree(tree)
+}
+
+// loadTreeEngine читает запись TREE_ENGINE name ENGINE n v1 ... vn
+func (f *FileIO) loadTreeEngine(db *Database, parts []string) {
+	if len(parts) < 4 {
+		return
+	}
+
+	name := parts[1]
+	engine, err := ParseTreeEngine(parts[2])
+	if err != nil || engine == DISK_ENGINE {
+		return
+	}
+	size, err := strconv.Atoi(parts[3])
+	if err != nil || size < 0 || len(parts) < 4+size {
+		return
+	}
+
+	values := make([]int64, 0, size)
+	for i := 0; i < size; i++ {
+		value, err := strconv.ParseInt(parts[4+i], 10, 64)
+		if err != nil {
+			return
+		}
+		values = append(values, value)
+	}
+
+	set := NewOrderedSet(name, engine)
+	if err := set.BuildFromSorted(values); err != nil {
+		for _, value := range values {
+			set.Insert(value)
+		}
+	}
+	db.AddOrderedSet(set)
+}
+
+// loadTreeDisk открывает дисковое дерево по записи TREE_DISK name path
+func (f *FileIO) loadTreeDisk(db *Database, parts []string) {
+	if len(parts) < 3 {
+		return
+	}
+
+	tree, err := OpenDiskBPlusTree(parts[1], parts[2])
+	if err != nil {
+		return
+	}
+	db.AddOrderedSet(tree)
+}
+
 func (f *FileIO) loadHashTable(db *Database, parts []string) {
 	if len(parts) < 3 {
 		return
@@ -182,6 +416,124 @@ func (f *FileIO) loadHashTable(db *Database, parts []string) {
 	db.AddHashTable(table)
 }
 
+func (f *FileIO) loadHashLayout(db *Database, parts []string) {
+	if len(parts) < 6 {
+		return
+	}
+	
+	name := parts[1]
+	capacity, err := strconv.Atoi(parts[2])
+	if err != nil || capacity < 1 {
+		return
+	}
+	loadFactor, err := strconv.ParseFloat(parts[3], 64)
+	if err != nil || loadFactor <= 0 {
+		return
+	}
+	opts := HashTableOptions{
+		Capacity:      capacity,
+		LoadFactor:    loadFactor,
+		PreserveOrder: parts[4] == "1",
+		Hash:          DJB2_HASH, // в старых файлах хеш-функция не записана
+	}
+
+	// Хеш-функция записывается как KIND:seed перед числом записей
+	pos := 5
+	if kindText, seedText, found := strings.Cut(parts[pos], ":"); found {
+		kind, err := ParseHashKind(kindText)
+		if err != nil {
+			return
+		}
+		seed, err := strconv.ParseUint(seedText, 10, 64)
+		if err != nil || (kind == SIPHASH_HASH && seed == 0) {
+			return
+		}
+		opts.Hash = kind
+		opts.Seed = seed
+		pos++
+	}
+
+	// Необязательные параметры SHRINK:x, MIN:n и ENGINE:name
+	minCapacity := capacity
+	for pos < len(parts) {
+		key, value, found := strings.Cut(parts[pos], ":")
+		if !found {
+			break
+		}
+		switch key {
+		case "SHRINK":
+			shrinkFactor, err := strconv.ParseFloat(value, 64)
+			if err != nil || shrinkFactor <= 0 {
+				return
+			}
+			opts.ShrinkFactor = shrinkFactor
+		case "MIN":
+			minCapacity, err = strconv.Atoi(value)
+			// Ёмкость должна получаться из минимальной удвоениями
+			if err != nil || minCapacity < 1 || capacity%minCapacity != 0 ||
+				bits.OnesCount(uint(capacity/minCapacity)) != 1 {
+				return
+			}
+		case "ENGINE":
+			engine, err := ParseHashEngine(value)
+			if err != nil {
+				return
+			}
+			opts.Engine = engine
+		default:
+			return
+		}
+		pos++
+	}
+
+	if len(parts) <= pos {
+		return
+	}
+	size, err := strconv.Atoi(parts[pos])
+	if err != nil {
+		return
+	}
+	
+	if len(parts) < pos+1+size*2 {
+		return
+	}
+	
+	table, err := NewHashTableWithOptions(name, opts)
+	if err != nil {
+		return
+	}
+	table.minCapacity = minCapacity
+	for i := 0; i < size; i++ {
+		table.restoreEntry(parts[pos+1+i*2], parts[pos+1+i*2+1])
+	}
+	db.AddHashTable(table)
+}
+
+// loadHashExpiry восстанавливает сроки жизни ключей уже загруженной
+// таблицы. Ключи, срок которых истёк, пока база лежала в файле, удаляются.
+func (f *FileIO) loadHashExpiry(db *Database, parts []string) {
+	if len(parts) < 3 {
+		return
+	}
+
+	table := db.FindHashTable(parts[1])
+	if table == nil {
+		return
+	}
+	size, err := strconv.Atoi(parts[2])
+	if err != nil || size < 0 || len(parts) < 3+size*2 {
+		return
+	}
+
+	for i := 0; i < size; i++ {
+		deadline, err := strconv.ParseInt(parts[3+i*2+1], 10, 64)
+		if err != nil {
+			continue
+		}
+		table.ExpireAt(parts[3+i*2], time.UnixMilli(deadline))
+	}
+}
+
 func (f *FileIO) SaveDatabaseToFile(db *Database, filename string) error {
 	file, err := os.Create(filename)
 	if err != nil {
@@ -239,7 +591,21 @@ func (f *FileIO) SaveDatabaseToFile(db *Database, filename string) error {
 	// Сохраняем деревья
 	for _, tree := range db.Trees {
 		if tree != nil {
-			if err := f.serializer.SerializeTree(tree, writer, TEXT); err != nil {
+			if f.treeEncoding == TREE_SHAPE && tree.GetMode() == INT_KEYS && !tree.IsMultiset() {
+				err = f.serializer.SerializeTreeShape(tree, writer, TEXT)
+			} else {
+				err = f.serializer.SerializeTree(tree, writer, TEXT)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Сохраняем деревья на других движках
+	for _, set := range db.OrderedSets {
+		if set != nil {
+			if err := f.serializer.SerializeOrderedSet(set, writer, TEXT); err != nil {
 				return err
 			}
 		}
@@ -248,7 +614,15 @@ func (f *FileIO) SaveDatabaseToFile(db *Database, filename string) error {
 	// Сохраняем хеш-таблицы
 	for _, table := range db.HashTables {
 		if table != nil {
-			if err := f.serializer.SerializeHashTable(table, writer, TEXT); err != nil {
+			if f.needsHashLayout(table) {
+				err = f.serializer.SerializeHashLayout(table, writer, TEXT)
+			} else {
+				err = f.serializer.SerializeHashTable(table, writer, TEXT)
+			}
+			if err != nil {
+				return err
+			}
+			if err := f.serializer.SerializeHashExpiry(table, writer, TEXT); err != nil {
 				return err
 			}
 		}
@@ -272,6 +646,7 @@ func (f *FileIO) LoadDatabaseFromFile(db *Database, filename string) error {
 	db.Cleanup()
 
 	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
 	for scanner.Scan() {
 		line := scanner.Text()
 		parts := strings.Fields(line)
@@ -292,8 +667,24 @@ func (f *FileIO) LoadDatabaseFromFile(db *Database, filename string) error {
 			f.loadQueue(db, parts)
 		case "TREE":
 			f.loadTree(db, parts)
+		case "TREE_SHAPE":
+			f.loadTreeShape(db, parts)
+		case "TREE_BIG":
+			f.loadTreeBig(db, parts)
+		case "TREE_MAP":
+			f.loadTreeMap(db, parts)
+		case "TREE_MULTI":
+			f.loadTreeMulti(db, parts)
+		case "TREE_ENGINE":
+			f.loadTreeEngine(db, parts)
+		case "TREE_DISK":
+			f.loadTreeDisk(db, parts)
 		case "HASH":
 			f.loadHashTable(db, parts)
+		case "HASH_LAYOUT":
+			f.loadHashLayout(db, parts)
+		case "HASH_TTL":
+			f.loadHashExpiry(db, parts)
 		}
 	}
 
@@ -1,8 +1,11 @@
 package dbmsgo
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -245,6 +248,303 @@ func TestFileIO_LoadTree_InvalidNumbers(t *testing.T) {
 	assert.NotNil(t, tree.Search(10))
 }
 
+func TestFileIO_LoadTree_Unsorted(t *testing.T) {
+	fileIO := NewFileIO()
+	db := NewDatabase()
+
+	// Старые файлы могли содержать значения в произвольном порядке
+	parts := []string{"TREE", "test_tree", "4", "10", "5", "15", "5"}
+	fileIO.loadTree(db, parts)
+
+	tree := db.FindTree("test_tree")
+	assert.NotNil(t, tree)
+	assert.Equal(t, []int64{5, 10, 15}, tree.SaveTree())
+}
+
+func TestFileIO_TreeShapeEncoding(t *testing.T) {
+	fileIO := NewFileIO()
+	fileIO.SetTreeEncoding(TREE_SHAPE)
+	assert.Equal(t, TREE_SHAPE, fileIO.GetTreeEncoding())
+
+	db := NewDatabase()
+	tree := NewAVLTree("test_tree")
+	for i := 1; i <= 20; i++ {
+		tree.Insert(int64(i))
+	}
+	db.AddTree(tree)
+
+	filename := "test_tree_shape.txt"
+	defer os.Remove(filename)
+	assert.NoError(t, fileIO.SaveDatabaseToFile(db, filename))
+
+	newDB := NewDatabase()
+	assert.NoError(t, fileIO.LoadDatabaseFromFile(newDB, filename))
+
+	loaded := newDB.FindTree("test_tree")
+	assert.NotNil(t, loaded)
+	values, heights := tree.SaveTreeShape()
+	loadedValues, loadedHeights := loaded.SaveTreeShape()
+	assert.Equal(t, values, loadedValues)
+	assert.Equal(t, heights, loadedHeights)
+}
+
+func TestFileIO_LoadTreeShape_InvalidData(t *testing.T) {
+	fileIO := NewFileIO()
+	db := NewDatabase()
+
+	fileIO.loadTreeShape(db, []string{"TREE_SHAPE", "bad_height", "2", "1", "5", "2", "1"})
+	fileIO.loadTreeShape(db, []string{"TREE_SHAPE", "short", "2", "1", "2"})
+	fileIO.loadTreeShape(db, []string{"TREE_SHAPE", "not_number", "1", "x", "1"})
+
+	assert.Nil(t, db.FindTree("bad_height"))
+	assert.Nil(t, db.FindTree("short"))
+	assert.Nil(t, db.FindTree("not_number"))
+}
+
+func TestFileIO_LoadTree_NegativeSize(t *testing.T) {
+	fileIO := NewFileIO()
+
+	// Повреждённые записи пропускаются, остальные загружаются
+	content := "TREE bad -1\nTREE_SHAPE bad_shape -1\nTREE good 1 5\n"
+	filename := "test_negative_tree.txt"
+	assert.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+	defer os.Remove(filename)
+
+	db := NewDatabase()
+	assert.NoError(t, fileIO.LoadDatabaseFromFile(db, filename))
+	assert.Nil(t, db.FindTree("bad"))
+	assert.Nil(t, db.FindTree("bad_shape"))
+	assert.Equal(t, []int64{5}, db.FindTree("good").SaveTree())
+}
+
+func TestFileIO_BigIntTreeRoundTrip(t *testing.T) {
+	fileIO := NewFileIO()
+	fileIO.SetTreeEncoding(TREE_SHAPE)
+	db := NewDatabase()
+
+	tree := NewAVLTreeWithMode("big_tree", BIGINT_KEYS)
+	keys := []string{"-170141183460469231731687303715884105728", "0", "18446744073709551616"}
+	for _, key := range keys {
+		tree.InsertKey(key)
+	}
+	db.AddTree(tree)
+
+	filename := "test_big_tree.txt"
+	defer os.Remove(filename)
+	assert.NoError(t, fileIO.SaveDatabaseToFile(db, filename))
+
+	newDB := NewDatabase()
+	assert.NoError(t, fileIO.LoadDatabaseFromFile(newDB, filename))
+	loaded := newDB.FindTree("big_tree")
+	assert.NotNil(t, loaded)
+	assert.Equal(t, BIGINT_KEYS, loaded.GetMode())
+	assert.Equal(t, keys, loaded.SaveKeys())
+}
+
+func TestFileIO_LoadTreeBig_InvalidData(t *testing.T) {
+	fileIO := NewFileIO()
+	db := NewDatabase()
+
+	fileIO.loadTreeBig(db, []string{"TREE_BIG", "negative", "-1"})
+	fileIO.loadTreeBig(db, []string{"TREE_BIG", "short", "2", "1"})
+
+	assert.Nil(t, db.FindTree("negative"))
+	assert.Nil(t, db.FindTree("short"))
+}
+
+func TestFileIO_OrderedMapRoundTrip(t *testing.T) {
+	fileIO := NewFileIO()
+	db := NewDatabase()
+
+	tree := NewOrderedMap("dict", LEX_ORDER)
+	tree.Put("b", "2")
+	tree.Put("a", "1")
+	tree.Put("c", "3")
+	db.AddTree(tree)
+
+	filename := "test_ordered_map.txt"
+	defer os.Remove(filename)
+	assert.NoError(t, fileIO.SaveDatabaseToFile(db, filename))
+
+	newDB := NewDatabase()
+	assert.NoError(t, fileIO.LoadDatabaseFromFile(newDB, filename))
+	loaded := newDB.FindTree("dict")
+	assert.NotNil(t, loaded)
+	assert.Equal(t, STRING_KEYS, loaded.GetMode())
+	keys, values := loaded.SaveEntries()
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+	assert.Equal(t, []string{"1", "2", "3"}, values)
+
+	// Словарь с собственным компаратором загружается с порядком LEX
+	reversed := NewOrderedMapWithComparator("reversed", func(a, b string) int { return LexicographicOrder(b, a) })
+	reversed.Put("a", "1")
+	reversed.Put("b", "2")
+	db.AddTree(reversed)
+	assert.NoError(t, fileIO.SaveDatabaseToFile(db, filename))
+	assert.NoError(t, fileIO.LoadDatabaseFromFile(newDB, filename))
+	loaded = newDB.FindTree("reversed")
+	assert.NotNil(t, loaded)
+	assert.Equal(t, LEX_ORDER, loaded.GetOrdering())
+	keys, values = loaded.SaveEntries()
+	assert.Equal(t, []string{"a", "b"}, keys)
+	assert.Equal(t, []string{"1", "2"}, values)
+}
+
+func TestFileIO_LoadTreeMap_InvalidData(t *testing.T) {
+	fileIO := NewFileIO()
+	db := NewDatabase()
+
+	fileIO.loadTreeMap(db, []string{"TREE_MAP", "bad_order", "RANDOM", "0"})
+	fileIO.loadTreeMap(db, []string{"TREE_MAP", "short", "LEX", "2", "a", "1"})
+	fileIO.loadTreeMap(db, []string{"TREE_MAP", "unsorted", "NUM", "2", "5", "x", "1", "y"})
+	fileIO.loadTreeMap(db, []string{"TREE_MAP", "negative", "LEX", "-1"})
+
+	assert.Nil(t, db.FindTree("bad_order"))
+	assert.Nil(t, db.FindTree("short"))
+	assert.Nil(t, db.FindTree("negative"))
+	keys, _ := db.FindTree("unsorted").SaveEntries()
+	assert.Equal(t, []string{"1", "5"}, keys)
+}
+
+func TestFileIO_MultisetRoundTrip(t *testing.T) {
+	fileIO := NewFileIO()
+	fileIO.SetTreeEncoding(TREE_SHAPE)
+	db := NewDatabase()
+
+	tree := NewMultisetTree("measurements", INT_KEYS)
+	for _, v := range []int64{3, 1, 3, 3, 2} {
+		tree.Insert(v)
+	}
+	db.AddTree(tree)
+
+	filename := "test_multiset.txt"
+	defer os.Remove(filename)
+	assert.NoError(t, fileIO.SaveDatabaseToFile(db, filename))
+
+	newDB := NewDatabase()
+	assert.NoError(t, fileIO.LoadDatabaseFromFile(newDB, filename))
+	loaded := newDB.FindTree("measurements")
+	assert.NotNil(t, loaded)
+	assert.True(t, loaded.IsMultiset())
+	assert.Equal(t, []int64{1, 2, 3, 3, 3}, loaded.SaveTree())
+}
+
+func TestFileIO_LoadTreeMulti_InvalidData(t *testing.T) {
+	fileIO := NewFileIO()
+	db := NewDatabase()
+
+	fileIO.loadTreeMulti(db, []string{"TREE_MULTI", "bad_mode", "STRING", "0"})
+	fileIO.loadTreeMulti(db, []string{"TREE_MULTI", "bad_count", "INT", "1", "5", "x"})
+	fileIO.loadTreeMulti(db, []string{"TREE_MULTI", "unsorted", "INT", "2", "5", "2", "1", "1"})
+	fileIO.loadTreeMulti(db, []string{"TREE_MULTI", "negative", "INT", "-1"})
+
+	assert.Nil(t, db.FindTree("bad_mode"))
+	assert.Nil(t, db.FindTree("bad_count"))
+	assert.Nil(t, db.FindTree("negative"))
+	assert.Equal(t, []int64{1, 5, 5}, db.FindTree("unsorted").SaveTree())
+}
+
+func TestFileIO_TreeEnginesRoundTrip(t *testing.T) {
+	fileIO := NewFileIO()
+	db := NewDatabase()
+
+	for _, engine := range []TreeEngine{RBTREE_ENGINE, BTREE_ENGINE, SKIPLIST_ENGINE} {
+		set := NewOrderedSet("tree_"+engine.String(), engine)
+		for _, v := range []int64{5, -2, 40, 7} {
+			set.Insert(v)
+		}
+		db.AddOrderedSet(set)
+	}
+
+	filename := "test_engines.txt"
+	defer os.Remove(filename)
+	assert.NoError(t, fileIO.SaveDatabaseToFile(db, filename))
+
+	newDB := NewDatabase()
+	assert.NoError(t, fileIO.LoadDatabaseFromFile(newDB, filename))
+	for _, engine := range []TreeEngine{RBTREE_ENGINE, BTREE_ENGINE, SKIPLIST_ENGINE} {
+		loaded := newDB.FindOrderedSet("tree_" + engine.String())
+		assert.NotNil(t, loaded)
+		assert.Equal(t, engine, loaded.GetEngine())
+		assert.Equal(t, []int64{-2, 5, 7, 40}, loaded.SaveTree())
+	}
+}
+
+func TestFileIO_LoadTreeEngine_InvalidData(t *testing.T) {
+	fileIO := NewFileIO()
+	db := NewDatabase()
+
+	fileIO.loadTreeEngine(db, []string{"TREE_ENGINE", "bad_engine", "HEAP", "0"})
+	fileIO.loadTreeEngine(db, []string{"TREE_ENGINE", "bad_value", "BTREE", "1", "x"})
+	fileIO.loadTreeEngine(db, []string{"TREE_ENGINE", "short", "BTREE", "3", "1"})
+	fileIO.loadTreeEngine(db, []string{"TREE_ENGINE", "unsorted", "RBTREE", "3", "5", "1", "5"})
+
+	assert.Nil(t, db.FindOrderedSet("bad_engine"))
+	assert.Nil(t, db.FindOrderedSet("bad_value"))
+	assert.Nil(t, db.FindOrderedSet("short"))
+	assert.Equal(t, []int64{1, 5}, db.FindOrderedSet("unsorted").SaveTree())
+}
+
+func TestFileIO_DiskTreeRoundTrip(t *testing.T) {
+	fileIO := NewFileIO()
+	db := NewDatabase()
+
+	path := filepath.Join(t.TempDir(), "keys.db")
+	tree, err := OpenDiskBPlusTree("keys", path)
+	assert.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		tree.Insert(int64(i * 2))
+	}
+	db.AddOrderedSet(tree)
+
+	filename := "test_disk_tree.txt"
+	defer os.Remove(filename)
+	assert.NoError(t, fileIO.SaveDatabaseToFile(db, filename))
+
+	content, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, "TREE_DISK keys "+path+"\n", string(content))
+
+	// Загрузка закрывает открытое дерево и открывает файл заново
+	assert.NoError(t, fileIO.LoadDatabaseFromFile(db, filename))
+	loaded := db.FindOrderedSet("keys")
+	assert.NotNil(t, loaded)
+	assert.Equal(t, DISK_ENGINE, loaded.GetEngine())
+	assert.Equal(t, 1000, loaded.Size())
+	assert.True(t, loaded.Contains(1998))
+	db.Cleanup()
+
+	fileIO.loadTreeDisk(db, []string{"TREE_DISK", "missing"})
+	fileIO.loadTreeEngine(db, []string{"TREE_ENGINE", "disk", "DISK", "0"})
+	assert.Nil(t, db.FindOrderedSet("missing"))
+	assert.Nil(t, db.FindOrderedSet("disk"))
+}
+
+func BenchmarkFileIO_LoadLargeTree(b *testing.B) {
+	tree := NewAVLTree("big_tree")
+	values := make([]int64, 1000000)
+	for i := range values {
+		values[i] = int64(i)
+	}
+	tree.BuildFromSorted(values)
+
+	db := NewDatabase()
+	db.AddTree(tree)
+	fileIO := NewFileIO()
+	filename := "bench_large_tree.txt"
+	defer os.Remove(filename)
+	if err := fileIO.SaveDatabaseToFile(db, filename); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := fileIO.LoadDatabaseFromFile(NewDatabase(), filename); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestFileIO_LoadHashTable(t *testing.T) {
 	fileIO := NewFileIO()
 	db := NewDatabase()
@@ -277,6 +577,213 @@ func TestFileIO_LoadHashTable_InvalidData(t *testing.T) {
 	assert.Nil(t, hash) // Should not create hash table with invalid data
 }
 
+func TestFileIO_HashLayoutRoundTrip(t *testing.T) {
+	for _, ordered := range []bool{false, true} {
+		fileIO := NewFileIO()
+		fileIO.SetHashEncoding(HASH_LAYOUT)
+		assert.Equal(t, HASH_LAYOUT, fileIO.GetHashEncoding())
+
+		db := NewDatabase()
+		table := newTestHashTable(t, "test_hash", HashTableOptions{Capacity: 4, LoadFactor: 0.9, PreserveOrder: ordered})
+		for i := 0; i < 30; i++ {
+			table.Insert(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+		}
+		for i := 0; i < 30; i += 3 {
+			table.Remove(fmt.Sprintf("key%d", i))
+		}
+		db.AddHashTable(table)
+
+		first := "test_hash_layout_1.txt"
+		second := "test_hash_layout_2.txt"
+		assert.NoError(t, fileIO.SaveDatabaseToFile(db, first))
+
+		loadedDB := NewDatabase()
+		assert.NoError(t, fileIO.LoadDatabaseFromFile(loadedDB, first))
+		loaded := loadedDB.FindHashTable("test_hash")
+		assert.NotNil(t, loaded)
+		assert.Equal(t, table.GetCapacity(), loaded.GetCapacity())
+		assert.Equal(t, 0.9, loaded.GetLoadFactor())
+		assert.Equal(t, ordered, loaded.IsOrdered())
+		assert.NoError(t, fileIO.SaveDatabaseToFile(loadedDB, second))
+
+		firstData, err := os.ReadFile(first)
+		assert.NoError(t, err)
+		secondData, err := os.ReadFile(second)
+		assert.NoError(t, err)
+		assert.Equal(t, string(firstData), string(secondData))
+
+		os.Remove(first)
+		os.Remove(second)
+	}
+}
+
+func TestFileIO_HashLayoutForCustomTables(t *testing.T) {
+	fileIO := NewFileIO()
+
+	// Случайный ключ SipHash не восстановить из старой записи
+	assert.True(t, fileIO.needsHashLayout(NewHashTable("plain")))
+	fileIO.SetHashEncoding(HASH_ENTRIES)
+	assert.False(t, fileIO.needsHashLayout(NewHashTable("plain")))
+	assert.False(t, fileIO.needsHashLayout(newTestHashTable(t, "ordered", HashTableOptions{PreserveOrder: true})))
+	fileIO.SetHashEncoding(HASH_AUTO)
+	assert.True(t, fileIO.needsHashLayout(newTestHashTable(t, "ordered", HashTableOptions{PreserveOrder: true})))
+	assert.True(t, fileIO.needsHashLayout(newTestHashTable(t, "dense", HashTableOptions{LoadFactor: 2})))
+	assert.True(t, fileIO.needsHashLayout(newTestHashTable(t, "big", HashTableOptions{Capacity: 1000, Hash: FNV1A_HASH})))
+}
+
+func TestFileIO_DefaultHashRoundTripIsStable(t *testing.T) {
+	fileIO := NewFileIO()
+	db := NewDatabase()
+	plain := NewHashTable("plain")
+	for i := 0; i < 12; i++ {
+		plain.Insert(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	db.AddHashTable(plain)
+	big := newTestHashTable(t, "big", HashTableOptions{Capacity: 1000})
+	big.Insert("a", "1")
+	db.AddHashTable(big)
+
+	first := "test_hash_default_1.txt"
+	second := "test_hash_default_2.txt"
+	third := "test_hash_default_3.txt"
+	defer os.Remove(first)
+	defer os.Remove(second)
+	defer os.Remove(third)
+
+	assert.NoError(t, fileIO.SaveDatabaseToFile(db, first))
+	loadedDB := NewDatabase()
+	assert.NoError(t, fileIO.LoadDatabaseFromFile(loadedDB, first))
+	assert.Equal(t, 1000, loadedDB.FindHashTable("big").GetCapacity())
+	assert.Equal(t, plain.GetHashFunction().Seed(), loadedDB.FindHashTable("plain").GetHashFunction().Seed())
+	assert.NoError(t, fileIO.SaveDatabaseToFile(loadedDB, second))
+
+	reloadedDB := NewDatabase()
+	assert.NoError(t, fileIO.LoadDatabaseFromFile(reloadedDB, second))
+	assert.NoError(t, fileIO.SaveDatabaseToFile(reloadedDB, third))
+
+	firstData, err := os.ReadFile(first)
+	assert.NoError(t, err)
+	secondData, err := os.ReadFile(second)
+	assert.NoError(t, err)
+	thirdData, err := os.ReadFile(third)
+	assert.NoError(t, err)
+	assert.Equal(t, firstData, secondData)
+	assert.Equal(t, secondData, thirdData)
+}
+
+func TestFileIO_HashFunctionPersistence(t *testing.T) {
+	fileIO := NewFileIO()
+	db := NewDatabase()
+	db.AddHashTable(newTestHashTable(t, "fnv", HashTableOptions{Hash: FNV1A_HASH}))
+	seeded := newTestHashTable(t, "seeded", HashTableOptions{Seed: 777})
+	seeded.Insert("k", "v")
+	db.AddHashTable(seeded)
+	db.AddHashTable(NewHashTable("plain"))
+
+	filename := "test_hash_functions.txt"
+	defer os.Remove(filename)
+	assert.NoError(t, fileIO.SaveDatabaseToFile(db, filename))
+
+	loadedDB := NewDatabase()
+	assert.NoError(t, fileIO.LoadDatabaseFromFile(loadedDB, filename))
+	assert.Equal(t, FNV1A_HASH, loadedDB.FindHashTable("fnv").GetHashFunction().Kind())
+	// Таблица по умолчанию сохраняется со своим случайным ключом
+	assert.Equal(t, SIPHASH_HASH, loadedDB.FindHashTable("plain").GetHashFunction().Kind())
+	assert.Equal(t, db.FindHashTable("plain").GetHashFunction().Seed(), loadedDB.FindHashTable("plain").GetHashFunction().Seed())
+
+	fileIO.SetHashEncoding(HASH_LAYOUT)
+	assert.NoError(t, fileIO.SaveDatabaseToFile(db, filename))
+	assert.NoError(t, fileIO.LoadDatabaseFromFile(loadedDB, filename))
+	assert.Equal(t, uint64(777), loadedDB.FindHashTable("seeded").GetHashFunction().Seed())
+	value, found := loadedDB.FindHashTable("seeded").Search("k")
+	assert.True(t, found)
+	assert.Equal(t, "v", value)
+
+	// Файлы без хеш-функции в записи загружаются с прежней функцией djb2
+	fileIO.loadHashLayout(loadedDB, []string{"HASH_LAYOUT", "legacy", "10", "0.7", "0", "1", "a", "b"})
+	legacy := loadedDB.FindHashTable("legacy")
+	assert.Equal(t, DJB2_HASH, legacy.GetHashFunction().Kind())
+	value, _ = legacy.Search("a")
+	assert.Equal(t, "b", value)
+}
+
+func TestFileIO_HashShrinkPersistence(t *testing.T) {
+	fileIO := NewFileIO()
+	db := NewDatabase()
+	table := newTestHashTable(t, "shrinking", HashTableOptions{Capacity: 4, ShrinkFactor: 0.3})
+	for i := 0; i < 20; i++ {
+		table.Insert(fmt.Sprintf("key%d", i), "v")
+	}
+	db.AddHashTable(table)
+
+	filename := "test_hash_shrink.txt"
+	defer os.Remove(filename)
+	assert.NoError(t, fileIO.SaveDatabaseToFile(db, filename))
+
+	loadedDB := NewDatabase()
+	assert.NoError(t, fileIO.LoadDatabaseFromFile(loadedDB, filename))
+	loaded := loadedDB.FindHashTable("shrinking")
+	assert.Equal(t, 0.3, loaded.GetShrinkFactor())
+	assert.Equal(t, table.GetCapacity(), loaded.GetCapacity())
+	assert.Equal(t, 4, loaded.GetMinCapacity())
+	assert.Equal(t, 20, loaded.GetSize())
+}
+
+func TestFileIO_HashEngineRoundTrip(t *testing.T) {
+	fileIO := NewFileIO()
+	db := NewDatabase()
+	table := newTestHashTable(t, "open", HashTableOptions{Engine: ROBINHOOD_ENGINE, Seed: 5})
+	for i := 0; i < 50; i++ {
+		table.Insert(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	db.AddHashTable(table)
+
+	filename := "test_hash_engine.txt"
+	defer os.Remove(filename)
+	assert.NoError(t, fileIO.SaveDatabaseToFile(db, filename))
+
+	loadedDB := NewDatabase()
+	assert.NoError(t, fileIO.LoadDatabaseFromFile(loadedDB, filename))
+	loaded := loadedDB.FindHashTable("open")
+	assert.Equal(t, ROBINHOOD_ENGINE, loaded.GetEngine())
+	assert.Equal(t, table.GetCapacity(), loaded.GetCapacity())
+	assert.Equal(t, 50, loaded.GetSize())
+	for i := 0; i < 50; i++ {
+		value, found := loaded.Search(fmt.Sprintf("key%d", i))
+		assert.True(t, found)
+		assert.Equal(t, fmt.Sprintf("value%d", i), value)
+	}
+}
+
+func TestFileIO_LoadHashLayout_InvalidData(t *testing.T) {
+	fileIO := NewFileIO()
+	db := NewDatabase()
+
+	fileIO.loadHashLayout(db, []string{"HASH_LAYOUT", "bad_capacity", "0", "0.7", "0", "0"})
+	fileIO.loadHashLayout(db, []string{"HASH_LAYOUT", "bad_factor", "10", "x", "0", "0"})
+	fileIO.loadHashLayout(db, []string{"HASH_LAYOUT", "short", "10", "0.7", "0", "2", "key1", "value1"})
+	fileIO.loadHashLayout(db, []string{"HASH_LAYOUT", "bad_hash", "10", "0.7", "0", "MD5:1", "0"})
+	fileIO.loadHashLayout(db, []string{"HASH_LAYOUT", "bad_seed", "10", "0.7", "0", "SIPHASH:0", "0"})
+	fileIO.loadHashLayout(db, []string{"HASH_LAYOUT", "no_size", "10", "0.7", "0", "FNV1A:0"})
+	fileIO.loadHashLayout(db, []string{"HASH_LAYOUT", "bad_shrink", "10", "0.7", "0", "FNV1A:0", "SHRINK:0.5", "0"})
+	fileIO.loadHashLayout(db, []string{"HASH_LAYOUT", "bad_min", "10", "0.7", "0", "FNV1A:0", "MIN:20", "0"})
+	fileIO.loadHashLayout(db, []string{"HASH_LAYOUT", "bad_option", "10", "0.7", "0", "FNV1A:0", "GROW:2", "0"})
+	fileIO.loadHashLayout(db, []string{"HASH_LAYOUT", "bad_engine", "10", "0.7", "0", "FNV1A:0", "ENGINE:CUCKOO", "0"})
+	fileIO.loadHashLayout(db, []string{"HASH_LAYOUT", "ordered_open", "10", "0.7", "1", "FNV1A:0", "ENGINE:ROBINHOOD", "0"})
+
+	assert.Nil(t, db.FindHashTable("bad_capacity"))
+	assert.Nil(t, db.FindHashTable("bad_factor"))
+	assert.Nil(t, db.FindHashTable("short"))
+	assert.Nil(t, db.FindHashTable("bad_hash"))
+	assert.Nil(t, db.FindHashTable("bad_seed"))
+	assert.Nil(t, db.FindHashTable("no_size"))
+	assert.Nil(t, db.FindHashTable("bad_shrink"))
+	assert.Nil(t, db.FindHashTable("bad_min"))
+	assert.Nil(t, db.FindHashTable("bad_option"))
+	assert.Nil(t, db.FindHashTable("bad_engine"))
+	assert.Nil(t, db.FindHashTable("ordered_open"))
+}
+
 func TestFileIO_LoadFromFileWithMultipleStructures(t *testing.T) {
 	fileIO := NewFileIO()
 	
@@ -338,3 +845,44 @@ func TestNewFileIO(t *testing.T) {
 	assert.NotNil(t, fileIO)
 	assert.NotNil(t, fileIO.serializer)
 }
+
+func TestFileIO_HashExpiryRoundTrip(t *testing.T) {
+	fileIO := NewFileIO()
+	clock := newFakeClock()
+	db := NewDatabase()
+	db.SetClock(clock.Now)
+	table := NewHashTable("sessions")
+	db.AddHashTable(table)
+	table.Insert("short", "1")
+	table.Insert("long", "2")
+	table.Insert("forever", "3")
+	table.Expire("short", 10*time.Second)
+	table.Expire("long", time.Hour)
+
+	filename := "test_hash_expiry.txt"
+	defer os.Remove(filename)
+	assert.NoError(t, fileIO.SaveDatabaseToFile(db, filename))
+
+	// Срок считается от момента сохранения, а не от загрузки
+	clock.Advance(30 * time.Second)
+	loadedDB := NewDatabase()
+	loadedDB.SetClock(clock.Now)
+	assert.NoError(t, fileIO.LoadDatabaseFromFile(loadedDB, filename))
+	loaded := loadedDB.FindHashTable("sessions")
+	assert.Equal(t, 2, loaded.GetSize())
+	_, found := loaded.Search("short")
+	assert.False(t, found)
+	ttl, hasTTL, _ := loaded.TTL("long")
+	assert.True(t, hasTTL)
+	assert.Equal(t, time.Hour-30*time.Second, ttl)
+	_, hasTTL, found = loaded.TTL("forever")
+	assert.False(t, hasTTL)
+	assert.True(t, found)
+
+	// Некорректные записи сроков пропускаются
+	fileIO.loadHashExpiry(loadedDB, []string{"HASH_TTL", "missing", "1", "k", "1"})
+	fileIO.loadHashExpiry(loadedDB, []string{"HASH_TTL", "sessions", "2", "forever", "1"})
+	fileIO.loadHashExpiry(loadedDB, []string{"HASH_TTL", "sessions", "1", "forever", "soon"})
+	_, hasTTL, _ = loaded.TTL("forever")
+	assert.False(t, hasTTL)
+}
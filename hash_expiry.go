@@ -0,0 +1,166 @@
+package dbmsgo
+
+import (
+	"container/heap"
+	"time"
+)
+
+// Clock возвращает текущее время. Тесты подменяют его, чтобы срок жизни
+// ключей не зависел от реального времени.
+type Clock func() time.Time
+
+// Сколько ключей со сроком жизни проверяет один проход фоновой очистки
+// по таблице и как часто он запускается
+const (
+	expirySweepBatch    = 20
+	expirySweepInterval = 100 * time.Millisecond
+)
+
+func (h *HashTable) SetClock(clock Clock) {
+	if clock == nil {
+		clock = time.Now
+	}
+	h.clock = clock
+}
+
+func (h *HashTable) nowMilli() int64 {
+	return h.clock().UnixMilli()
+}
+
+// isExpired сообщает, что срок жизни ключа истёк, но запись ещё не удалена
+func (h *HashTable) isExpired(key string, now int64) bool {
+	deadline, ok := h.expires[key]
+	return ok && deadline <= now
+}
+
+// expireIfNeeded удаляет ключ с истёкшим сроком жизни. Вызывается при
+// каждом обращении к ключу.
+func (h *HashTable) expireIfNeeded(key string) bool {
+	if len(h.expires) == 0 || !h.isExpired(key, h.nowMilli()) {
+		return false
+	}
+	h.removeKey(key)
+	return true
+}
+
+// expiryItem - срок жизни ключа в очереди. Запись устаревает, если срок
+// ключа потом изменили или сняли; такие записи пропускаются.
+type expiryItem struct {
+	key      string
+	deadline int64
+}
+
+// expiryHeap - очередь сроков жизни с ближайшим сроком в вершине
+type expiryHeap []expiryItem
+
+func (q expiryHeap) Len() int           { return len(q) }
+func (q expiryHeap) Less(i, j int) bool { return q[i].deadline < q[j].deadline }
+func (q expiryHeap) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *expiryHeap) Push(x any) {
+	*q = append(*q, x.(expiryItem))
+}
+
+func (q *expiryHeap) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// dropDue удаляет не больше limit ключей с истёкшим сроком (limit < 0 -
+// все). Каждая запись очереди извлекается один раз, поэтому проверка
+// без истёкших ключей занимает O(1).
+func (h *HashTable) dropDue(limit int) int {
+	if len(h.expires) == 0 {
+		h.expiryQueue = nil
+		return 0
+	}
+	removed, now := 0, h.nowMilli()
+	for len(h.expiryQueue) > 0 && h.expiryQueue[0].deadline <= now && removed != limit {
+		item := heap.Pop(&h.expiryQueue).(expiryItem)
+		if deadline, ok := h.expires[item.key]; ok && deadline == item.deadline {
+			h.removeKey(item.key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// compactExpiryQueue убирает устаревшие записи, когда их становится больше,
+// чем действующих сроков
+func (h *HashTable) compactExpiryQueue() {
+	if len(h.expiryQueue) <= 2*len(h.expires)+expirySweepBatch {
+		return
+	}
+	queue := make(expiryHeap, 0, len(h.expires))
+	for key, deadline := range h.expires {
+		queue = append(queue, expiryItem{key, deadline})
+	}
+	heap.Init(&queue)
+	h.expiryQueue = queue
+}
+
+// Expire задаёт ключу срок жизни ttl. Неположительный ttl сразу удаляет
+// ключ. Возвращает false, если ключа нет.
+func (h *HashTable) Expire(key string, ttl time.Duration) bool {
+	return h.ExpireAt(key, h.clock().Add(ttl))
+}
+
+// ExpireAt задаёт ключу абсолютный момент удаления
+func (h *HashTable) ExpireAt(key string, deadline time.Time) bool {
+	if _, found := h.Search(key); !found {
+		return false
+	}
+	if deadline.UnixMilli() <= h.nowMilli() {
+		h.removeKey(key)
+		return true
+	}
+	if h.expires == nil {
+		h.expires = make(map[string]int64)
+	}
+	h.expires[key] = deadline.UnixMilli()
+	heap.Push(&h.expiryQueue, expiryItem{key, deadline.UnixMilli()})
+	h.compactExpiryQueue()
+	return true
+}
+
+// TTL возвращает оставшийся срок жизни ключа. hasTTL = false, если срок
+// не задан; found = false, если ключа нет.
+func (h *HashTable) TTL(key string) (ttl time.Duration, hasTTL bool, found bool) {
+	if _, found := h.Search(key); !found {
+		return 0, false, false
+	}
+	deadline, ok := h.expires[key]
+	if !ok {
+		return 0, false, true
+	}
+	return time.Duration(deadline-h.nowMilli()) * time.Millisecond, true, true
+}
+
+// GetDeadline возвращает момент удаления ключа, если он задан
+func (h *HashTable) GetDeadline(key string) (time.Time, bool) {
+	deadline, ok := h.expires[key]
+	if !ok || deadline <= h.nowMilli() {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(deadline), true
+}
+
+// Persist снимает с ключа срок жизни. Возвращает false, если срока не было.
+func (h *HashTable) Persist(key string) bool {
+	if _, found := h.Search(key); !found {
+		return false
+	}
+	if _, ok := h.expires[key]; !ok {
+		return false
+	}
+	delete(h.expires, key)
+	return true
+}
+
+// SweepExpired удаляет не больше limit ключей с истёкшим сроком, начиная
+// с самых старых
+func (h *HashTable) SweepExpired(limit int) int {
+	return h.dropDue(limit)
+}
@@ -0,0 +1,190 @@
+package dbmsgo
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock - часы, которые двигаются только вручную
+type fakeClock struct {
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestHashTable_Expiry(t *testing.T) {
+	engines := []HashEngine{CHAINING_ENGINE, ROBINHOOD_ENGINE}
+
+	t.Run("LazyExpiry", func(t *testing.T) {
+		for _, engine := range engines {
+			clock := newFakeClock()
+			table := newTestHashTable(t, "sessions", HashTableOptions{Engine: engine})
+			table.SetClock(clock.Now)
+			table.Insert("sid", "alice")
+			table.Insert("other", "bob")
+
+			assert.True(t, table.Expire("sid", 10*time.Second))
+			assert.False(t, table.Expire("missing", 10*time.Second))
+
+			clock.Advance(9 * time.Second)
+			value, found := table.Search("sid")
+			assert.True(t, found)
+			assert.Equal(t, "alice", value)
+
+			clock.Advance(time.Second)
+			assert.Equal(t, 1, table.GetSize())
+			assert.Len(t, table.Entries(), 1)
+			_, found = table.Search("sid")
+			assert.False(t, found)
+			assert.False(t, table.Remove("sid"))
+			assert.Equal(t, 1, table.GetSize())
+			assert.Empty(t, table.expires)
+		}
+	})
+
+	t.Run("TTLAndPersist", func(t *testing.T) {
+		clock := newFakeClock()
+		table := NewHashTable("sessions")
+		table.SetClock(clock.Now)
+		table.Insert("sid", "alice")
+
+		_, hasTTL, found := table.TTL("sid")
+		assert.False(t, hasTTL)
+		assert.True(t, found)
+		_, _, found = table.TTL("missing")
+		assert.False(t, found)
+
+		table.Expire("sid", time.Minute)
+		clock.Advance(15 * time.Second)
+		ttl, hasTTL, _ := table.TTL("sid")
+		assert.True(t, hasTTL)
+		assert.Equal(t, 45*time.Second, ttl)
+		deadline, ok := table.GetDeadline("sid")
+		assert.True(t, ok)
+		assert.Equal(t, clock.Now().Add(45*time.Second), deadline.UTC())
+
+		assert.True(t, table.Persist("sid"))
+		assert.False(t, table.Persist("sid"))
+		assert.False(t, table.Persist("missing"))
+		clock.Advance(time.Hour)
+		_, found = table.Search("sid")
+		assert.True(t, found)
+	})
+
+	t.Run("WritesAndTTL", func(t *testing.T) {
+		clock := newFakeClock()
+		table := NewHashTable("counters")
+		table.SetClock(clock.Now)
+
+		// Счётчик сохраняет срок жизни, обычная запись его снимает
+		table.IncrBy("hits", 1)
+		table.Expire("hits", time.Minute)
+		table.IncrBy("hits", 1)
+		assert.True(t, table.CompareAndSwap("hits", "2", "3"))
+		_, hasTTL, _ := table.TTL("hits")
+		assert.True(t, hasTTL)
+		table.Insert("hits", "0")
+		_, hasTTL, _ = table.TTL("hits")
+		assert.False(t, hasTTL)
+
+		// После истечения ключ считается отсутствующим
+		table.Expire("hits", time.Second)
+		clock.Advance(time.Second)
+		value, err := table.IncrBy("hits", 5)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), value)
+		_, hasTTL, _ = table.TTL("hits")
+		assert.False(t, hasTTL)
+
+		table.Insert("lock", "a")
+		table.Expire("lock", time.Second)
+		clock.Advance(2 * time.Second)
+		assert.True(t, table.SetNX("lock", "b"))
+
+		// Неположительный срок удаляет ключ сразу
+		assert.True(t, table.Expire("lock", 0))
+		_, found := table.Search("lock")
+		assert.False(t, found)
+	})
+
+	t.Run("Sweep", func(t *testing.T) {
+		clock := newFakeClock()
+		table := NewHashTable("sessions")
+		table.SetClock(clock.Now)
+		for i := 0; i < 100; i++ {
+			key := fmt.Sprintf("key%d", i)
+			table.Insert(key, "v")
+			if i%2 == 0 {
+				table.Expire(key, time.Second)
+			}
+		}
+		assert.Equal(t, 0, table.SweepExpired(100))
+
+		clock.Advance(time.Second)
+		assert.Equal(t, 100, table.size)
+		assert.Equal(t, 10, table.SweepExpired(10))
+		assert.Equal(t, 90, table.size)
+		// GetSize удаляет оставшиеся истёкшие ключи
+		assert.Equal(t, 50, table.GetSize())
+		assert.Equal(t, 50, table.size)
+		assert.Equal(t, 0, table.SweepExpired(expirySweepBatch))
+		assert.Empty(t, table.expires)
+	})
+
+	t.Run("StaleDeadlines", func(t *testing.T) {
+		clock := newFakeClock()
+		table := NewHashTable("sessions")
+		table.SetClock(clock.Now)
+		table.Insert("moved", "v")
+		table.Insert("persisted", "v")
+		table.Insert("reset", "v")
+		for _, key := range []string{"moved", "persisted", "reset"} {
+			table.Expire(key, time.Second)
+		}
+		table.Expire("moved", time.Minute)
+		table.Persist("persisted")
+		table.Insert("reset", "w")
+
+		clock.Advance(time.Second)
+		assert.Equal(t, 3, table.GetSize())
+		clock.Advance(time.Minute)
+		assert.Equal(t, 2, table.GetSize())
+		assert.Empty(t, table.expiryQueue)
+
+		// Устаревшие записи очереди не копятся без ограничения
+		for i := 0; i < 1000; i++ {
+			table.Expire("persisted", time.Hour)
+		}
+		assert.LessOrEqual(t, len(table.expiryQueue), 2+expirySweepBatch)
+	})
+
+	t.Run("ScanSkipsExpired", func(t *testing.T) {
+		clock := newFakeClock()
+		table := NewHashTable("sessions")
+		table.SetClock(clock.Now)
+		for i := 0; i < 20; i++ {
+			key := fmt.Sprintf("key%d", i)
+			table.Insert(key, "v")
+			if i < 5 {
+				table.Expire(key, time.Second)
+			}
+		}
+		clock.Advance(time.Second)
+		seen := scanAll(table, 100, "", nil)
+		assert.Len(t, seen, 15)
+		assert.NotContains(t, seen, "key0")
+	})
+}
@@ -0,0 +1,179 @@
+package dbmsgo
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"strings"
+	"time"
+)
+
+// HashKind задаёт хеш-функцию хеш-таблицы
+type HashKind int
+
+const (
+	SIPHASH_HASH HashKind = iota // SipHash-2-4 с секретным ключом, по умолчанию
+	FNV1A_HASH
+	DJB2_HASH // прежняя функция, бакеты совпадают с прежней таблицей
+)
+
+func (k HashKind) String() string {
+	switch k {
+	case FNV1A_HASH:
+		return "FNV1A"
+	case DJB2_HASH:
+		return "DJB2"
+	}
+	return "SIPHASH"
+}
+
+func ParseHashKind(text string) (HashKind, error) {
+	switch strings.ToUpper(text) {
+	case "SIPHASH":
+		return SIPHASH_HASH, nil
+	case "FNV1A":
+		return FNV1A_HASH, nil
+	case "DJB2":
+		return DJB2_HASH, nil
+	}
+	return SIPHASH_HASH, fmt.Errorf("unknown hash function '%s'", text)
+}
+
+// HashFunction отображает ключ в 64-битное значение. Результат не зависит
+// от платформы, поэтому раскладку таблицы можно сохранить и восстановить.
+type HashFunction interface {
+	Kind() HashKind
+	Seed() uint64
+	Sum64(key string) uint64
+}
+
+// NewHashFunction создаёт хеш-функцию. seed используется только ключевыми
+// функциями (SIPHASH), остальные его игнорируют.
+func NewHashFunction(kind HashKind, seed uint64) HashFunction {
+	switch kind {
+	case FNV1A_HASH:
+		return fnv1aHash{}
+	case DJB2_HASH:
+		return djb2Hash{}
+	}
+	return sipHash{seed: seed, k0: seed, k1: splitMix64(seed)}
+}
+
+// RandomHashSeed возвращает случайный ключ для SipHash. Без него набор
+// ключей с коллизиями можно подобрать заранее.
+func RandomHashSeed() uint64 {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return splitMix64(uint64(time.Now().UnixNano()))
+	}
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+// splitMix64 перемешивает биты числа, из seed получается второй ключ
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+type djb2Hash struct{}
+
+func (djb2Hash) Kind() HashKind { return DJB2_HASH }
+func (djb2Hash) Seed() uint64   { return 0 }
+
+// Sum64 возвращает модуль знакового хеша. Прежняя таблица брала остаток
+// от знакового числа и меняла знак у отрицательного, то есть получала
+// |hash| mod capacity, поэтому бакеты совпадают с прежними.
+func (djb2Hash) Sum64(key string) uint64 {
+	hash := uint64(5381)
+	for _, c := range key {
+		hash = hash<<5 + hash + uint64(c)
+	}
+	if int64(hash) < 0 {
+		return -hash
+	}
+	return hash
+}
+
+type fnv1aHash struct{}
+
+func (fnv1aHash) Kind() HashKind { return FNV1A_HASH }
+func (fnv1aHash) Seed() uint64   { return 0 }
+
+func (fnv1aHash) Sum64(key string) uint64 {
+	hash := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint64(key[i])
+		hash *= 1099511628211
+	}
+	return hash
+}
+
+type sipHash struct {
+	seed   uint64
+	k0, k1 uint64
+}
+
+func (s sipHash) Kind() HashKind { return SIPHASH_HASH }
+func (s sipHash) Seed() uint64   { return s.seed }
+
+func (s sipHash) Sum64(key string) uint64 {
+	return sipHash24(s.k0, s.k1, key)
+}
+
+// sipHash24 - SipHash-2-4 со 128-битным ключом (k0, k1)
+func sipHash24(k0, k1 uint64, data string) uint64 {
+	v0 := k0 ^ 0x736f6d6570736575
+	v1 := k1 ^ 0x646f72616e646f6d
+	v2 := k0 ^ 0x6c7967656e657261
+	v3 := k1 ^ 0x7465646279746573
+
+	round := func() {
+		v0 += v1
+		v1 = bits.RotateLeft64(v1, 13)
+		v1 ^= v0
+		v0 = bits.RotateLeft64(v0, 32)
+		v2 += v3
+		v3 = bits.RotateLeft64(v3, 16)
+		v3 ^= v2
+		v0 += v3
+		v3 = bits.RotateLeft64(v3, 21)
+		v3 ^= v0
+		v2 += v1
+		v1 = bits.RotateLeft64(v1, 17)
+		v1 ^= v2
+		v2 = bits.RotateLeft64(v2, 32)
+	}
+
+	length := len(data)
+	for len(data) >= 8 {
+		var m uint64
+		for i := 7; i >= 0; i-- {
+			m = m<<8 | uint64(data[i])
+		}
+		v3 ^= m
+		round()
+		round()
+		v0 ^= m
+		data = data[8:]
+	}
+
+	// Последний блок: оставшиеся байты и длина сообщения в старшем байте
+	last := uint64(length) << 56
+	for i := len(data) - 1; i >= 0; i-- {
+		last |= uint64(data[i]) << (8 * i)
+	}
+	v3 ^= last
+	round()
+	round()
+	v0 ^= last
+
+	v2 ^= 0xff
+	round()
+	round()
+	round()
+	round()
+	return v0 ^ v1 ^ v2 ^ v3
+}
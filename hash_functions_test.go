@@ -0,0 +1,83 @@
+package dbmsgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashFunctions(t *testing.T) {
+	t.Run("ParseHashKind", func(t *testing.T) {
+		for _, kind := range []HashKind{SIPHASH_HASH, FNV1A_HASH, DJB2_HASH} {
+			parsed, err := ParseHashKind(kind.String())
+			assert.NoError(t, err)
+			assert.Equal(t, kind, parsed)
+		}
+		_, err := ParseHashKind("MD5")
+		assert.Error(t, err)
+	})
+
+	t.Run("SipHashReferenceVectors", func(t *testing.T) {
+		// Векторы из статьи SipHash: ключ 00..0f, сообщение 00..len-1
+		k0 := uint64(0x0706050403020100)
+		k1 := uint64(0x0f0e0d0c0b0a0908)
+		message := make([]byte, 15)
+		for i := range message {
+			message[i] = byte(i)
+		}
+		assert.Equal(t, uint64(0x726fdb47dd0e0e31), sipHash24(k0, k1, ""))
+		assert.Equal(t, uint64(0xa129ca6149be45e5), sipHash24(k0, k1, string(message)))
+	})
+
+	t.Run("FNV1aReferenceVectors", func(t *testing.T) {
+		hash := NewHashFunction(FNV1A_HASH, 0)
+		assert.Equal(t, uint64(0xcbf29ce484222325), hash.Sum64(""))
+		assert.Equal(t, uint64(0xaf63dc4c8601ec8c), hash.Sum64("a"))
+		assert.Equal(t, uint64(0x85944171f73967e8), hash.Sum64("foobar"))
+	})
+
+	t.Run("DJB2", func(t *testing.T) {
+		hash := NewHashFunction(DJB2_HASH, 12345)
+		assert.Equal(t, uint64(5381), hash.Sum64(""))
+		assert.Equal(t, uint64(5381*33+'a'), hash.Sum64("a"))
+		assert.Equal(t, uint64(0), hash.Seed())
+		// Переполнение одинаково на всех платформах
+		assert.Equal(t, hash.Sum64("a very long key that overflows"), hash.Sum64("a very long key that overflows"))
+	})
+
+	t.Run("DJB2MatchesLegacyBuckets", func(t *testing.T) {
+		// Прежний расчёт: знаковый хеш, остаток, смена знака у отрицательного
+		legacyHash := func(key string) int {
+			hash := 5381
+			for _, c := range key {
+				hash = ((hash << 5) + hash) + int(c)
+			}
+			return hash
+		}
+
+		negative := 0
+		for _, key := range []string{"", "a", "key", "ключ", "a very long key that overflows", "another long key that overflows", "zzzzzzzzzzzzzzzz"} {
+			if legacyHash(key) < 0 {
+				negative++
+			}
+			for _, capacity := range []int{1, 7, 10, 16, 20, 1000} {
+				table := newTestHashTable(t, "legacy", HashTableOptions{Capacity: capacity, Hash: DJB2_HASH})
+				expected := legacyHash(key) % capacity
+				if expected < 0 {
+					expected = -expected
+				}
+				assert.Equal(t, expected, table.hashFunction(key), "%q %d", key, capacity)
+			}
+		}
+		assert.Greater(t, negative, 0)
+	})
+
+	t.Run("SeedChangesSipHash", func(t *testing.T) {
+		first := NewHashFunction(SIPHASH_HASH, 1)
+		second := NewHashFunction(SIPHASH_HASH, 2)
+		assert.Equal(t, uint64(1), first.Seed())
+		assert.Equal(t, first.Sum64("key"), NewHashFunction(SIPHASH_HASH, 1).Sum64("key"))
+		assert.NotEqual(t, first.Sum64("key"), second.Sum64("key"))
+		assert.NotEqual(t, RandomHashSeed(), RandomHashSeed())
+	})
+}
@@ -0,0 +1,179 @@
+package dbmsgo
+
+import "math/bits"
+
+// Ёмкость таблицы всегда равна minCapacity * 2^k, поэтому номер бакета
+// раскладывается в пару (r, q): r = index % minCapacity не меняется при
+// росте и сжатии, а q = index / minCapacity получает или теряет старший бит.
+// Курсор перебирает q в порядке обратных битов, как SCAN в Redis: при
+// изменении ёмкости между вызовами уже пройденные бакеты не посещаются
+// заново, а непройденные не пропускаются.
+
+// scanBits возвращает k для массива ёмкости capacity
+func (h *HashTable) scanBits(capacity int) uint {
+	return uint(bits.Len(uint(capacity/h.minCapacity)) - 1)
+}
+
+// nextScanQ увеличивает q на единицу в обратном порядке младших k бит
+func nextScanQ(q uint64, k uint) uint64 {
+	mask := uint64(1)<<k - 1
+	q |= ^mask
+	q = bits.Reverse64(q)
+	q++
+	return bits.Reverse64(q)
+}
+
+// chainBucket дописывает в result записи бакета index массива buckets
+func chainBucket(buckets []*HashEntry, index int, result []*HashEntry) []*HashEntry {
+	for current := buckets[index]; current != nil; current = current.Next {
+		result = append(result, current)
+	}
+	return result
+}
+
+// scanBucket собирает записи с остатком r и младшими битами q. Во время
+// перехэширования просматриваются оба массива.
+func (h *HashTable) scanBucket(r int, q uint64, result []*HashEntry) []*HashEntry {
+	base := h.minCapacity
+	if h.engine == ROBINHOOD_ENGINE {
+		k := h.scanBits(h.capacity)
+		return h.slotBucket(r+base*int(q&(1<<k-1)), result)
+	}
+	if !h.isRehashing() {
+		k := h.scanBits(h.capacity)
+		return chainBucket(h.buckets, r+base*int(q&(1<<k-1)), result)
+	}
+
+	small, large := h.oldBuckets, h.buckets
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+	k := h.scanBits(len(small))
+	low := int(q & (1<<k - 1))
+	result = chainBucket(small, r+base*low, result)
+	result = chainBucket(large, r+base*low, result)
+	return chainBucket(large, r+base*(low|1<<k), result)
+}
+
+// Scan возвращает записи нескольких бакетов начиная с cursor и курсор
+// для продолжения, 0 - обход закончен. Обход с курсора 0 до 0 возвращает
+// каждый ключ, который был в таблице всё это время, хотя бы один раз;
+// после сжатия таблицы ключ может встретиться повторно. Просматривается
+// не больше count*10 бакетов, даже если записей набралось меньше count.
+// Ключи с истёкшим сроком жизни пропускаются.
+func (h *HashTable) Scan(cursor uint64, count int, match string) (uint64, []*HashEntry) {
+	if count < 1 {
+		count = 1
+	}
+	base := uint64(h.minCapacity)
+	r, q := int(cursor%base), cursor/base
+
+	k := h.scanBits(h.capacity)
+	if h.isRehashing() && len(h.oldBuckets) < h.capacity {
+		k = h.scanBits(len(h.oldBuckets))
+	}
+
+	result := make([]*HashEntry, 0, count)
+	var bucket []*HashEntry
+	for visited := 0; visited < count*10 && len(result) < count; visited++ {
+		bucket = h.dropExpired(h.scanBucket(r, q, bucket[:0]))
+		for _, entry := range bucket {
+			if match == "" || matchGlob(match, entry.Key) {
+				result = append(result, entry)
+			}
+		}
+
+		r++
+		if r == h.minCapacity {
+			r = 0
+			q = nextScanQ(q, k)
+			if q == 0 {
+				return 0, result
+			}
+		}
+	}
+	return q*base + uint64(r), result
+}
+
+// matchGlob сопоставляет строку с шаблоном: * - любая последовательность,
+// ? - любой символ, [abc] и [a-z] - класс символов, \ экранирует символ.
+func matchGlob(pattern, text string) bool {
+	p, t := []rune(pattern), []rune(text)
+	// Позиции для возврата к последней звёздочке
+	star, mark := -1, 0
+	i, j := 0, 0
+	for j < len(t) {
+		if i < len(p) {
+			switch p[i] {
+			case '*':
+				star, mark = i, j
+				i++
+				continue
+			case '?':
+				i++
+				j++
+				continue
+			case '[':
+				if end, ok := matchClass(p, i, t[j]); ok {
+					i = end
+					j++
+					continue
+				}
+			case '\\':
+				if i+1 < len(p) && p[i+1] == t[j] {
+					i += 2
+					j++
+					continue
+				}
+			default:
+				if p[i] == t[j] {
+					i++
+					j++
+					continue
+				}
+			}
+		}
+		if star < 0 {
+			return false
+		}
+		i = star + 1
+		mark++
+		j = mark
+	}
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+	return i == len(p)
+}
+
+// matchClass проверяет символ c по классу, начинающемуся в p[start] == '[',
+// и возвращает позицию после класса. Незакрытая скобка считается обычным
+// символом.
+func matchClass(p []rune, start int, c rune) (int, bool) {
+	i := start + 1
+	negate := i < len(p) && p[i] == '^'
+	if negate {
+		i++
+	}
+	matched := false
+	for first := true; i < len(p) && (first || p[i] != ']'); first = false {
+		lo := p[i]
+		if lo == '\\' && i+1 < len(p) {
+			i++
+			lo = p[i]
+		}
+		hi := lo
+		if i+2 < len(p) && p[i+1] == '-' && p[i+2] != ']' {
+			hi = p[i+2]
+			i += 2
+		}
+		if lo <= c && c <= hi {
+			matched = true
+		}
+		i++
+	}
+	if i >= len(p) {
+		return start + 1, c == '['
+	}
+	return i + 1, matched != negate
+}
@@ -0,0 +1,142 @@
+package dbmsgo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// scanAll проходит таблицу курсором от 0 до 0, вызывая between между шагами
+func scanAll(table *HashTable, count int, match string, between func(step int)) map[string]int {
+	seen := make(map[string]int)
+	cursor := uint64(0)
+	for step := 0; ; step++ {
+		var entries []*HashEntry
+		cursor, entries = table.Scan(cursor, count, match)
+		for _, entry := range entries {
+			seen[entry.Key]++
+		}
+		if cursor == 0 {
+			return seen
+		}
+		if between != nil {
+			between(step)
+		}
+	}
+}
+
+func TestHashTable_Scan(t *testing.T) {
+	engines := []HashEngine{CHAINING_ENGINE, ROBINHOOD_ENGINE}
+
+	t.Run("FullScan", func(t *testing.T) {
+		for _, engine := range engines {
+			table := newTestHashTable(t, "scan", HashTableOptions{Capacity: 3, Engine: engine})
+			for i := 0; i < 500; i++ {
+				table.Insert(fmt.Sprintf("key%d", i), "v")
+			}
+			seen := scanAll(table, 7, "", nil)
+			assert.Len(t, seen, 500, engine.String())
+			for key, times := range seen {
+				assert.Equal(t, 1, times, key)
+			}
+		}
+	})
+
+	t.Run("EmptyTable", func(t *testing.T) {
+		for _, engine := range engines {
+			table := newTestHashTable(t, "scan", HashTableOptions{Engine: engine})
+			cursor, entries := table.Scan(0, 10, "")
+			assert.Equal(t, uint64(0), cursor)
+			assert.Empty(t, entries)
+		}
+	})
+
+	t.Run("GrowDuringScan", func(t *testing.T) {
+		for _, engine := range engines {
+			table := newTestHashTable(t, "scan", HashTableOptions{Capacity: 5, Engine: engine, Seed: 9})
+			for i := 0; i < 100; i++ {
+				table.Insert(fmt.Sprintf("stable%d", i), "v")
+			}
+			rehashing := false
+			seen := scanAll(table, 3, "", func(step int) {
+				for i := 0; i < 20; i++ {
+					table.Insert(fmt.Sprintf("new%d_%d", step, i), "v")
+				}
+				rehashing = rehashing || table.IsRehashing()
+			})
+			for i := 0; i < 100; i++ {
+				assert.Contains(t, seen, fmt.Sprintf("stable%d", i), engine.String())
+			}
+			if engine == CHAINING_ENGINE {
+				assert.True(t, rehashing)
+			}
+		}
+	})
+
+	t.Run("ShrinkDuringScan", func(t *testing.T) {
+		for _, engine := range engines {
+			table := newTestHashTable(t, "scan", HashTableOptions{Capacity: 4, Engine: engine, Seed: 9})
+			for i := 0; i < 2000; i++ {
+				table.Insert(fmt.Sprintf("key%d", i), "v")
+			}
+			grown := table.GetCapacity()
+			next := 100
+			seen := scanAll(table, 5, "", func(step int) {
+				for i := 0; i < 50 && next < 2000; i++ {
+					table.Remove(fmt.Sprintf("key%d", next))
+					next++
+				}
+			})
+			assert.Less(t, table.GetCapacity(), grown, engine.String())
+			for i := 0; i < 100; i++ {
+				assert.Contains(t, seen, fmt.Sprintf("key%d", i), engine.String())
+			}
+		}
+	})
+
+	t.Run("Match", func(t *testing.T) {
+		table := NewHashTable("scan")
+		for i := 0; i < 50; i++ {
+			table.Insert(fmt.Sprintf("user:%d", i), "v")
+			table.Insert(fmt.Sprintf("session:%d", i), "v")
+		}
+		seen := scanAll(table, 10, "user:*", nil)
+		assert.Len(t, seen, 50)
+		assert.Contains(t, seen, "user:7")
+		assert.NotContains(t, seen, "session:7")
+	})
+}
+
+func TestMatchGlob(t *testing.T) {
+	cases := []struct {
+		pattern string
+		text    string
+		match   bool
+	}{
+		{"", "", true},
+		{"", "a", false},
+		{"*", "", true},
+		{"*", "anything", true},
+		{"user:*", "user:42", true},
+		{"user:*", "session:42", false},
+		{"*:42", "user:42", true},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"key[0-9]", "key7", true},
+		{"key[0-9]", "keyx", false},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+		{`a\*`, "a*", true},
+		{`a\*`, "ab", false},
+		{"[abc", "[abc", true},
+		{"ключ*", "ключ1", true},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.match, matchGlob(c.pattern, c.text), "%q ~ %q", c.pattern, c.text)
+	}
+}
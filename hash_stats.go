@@ -0,0 +1,121 @@
+package dbmsgo
+
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// Приблизительный расход памяти на запись в map сроков жизни
+const expiryEntryOverhead = 48
+
+// HashStats - диагностика хеш-таблицы. Для цепочек Histogram[i] - число
+// бакетов с цепочкой длины i, Longest - самая длинная цепочка. Для
+// Robin Hood Histogram[i] - число записей, сдвинутых на i слотов от
+// домашнего, Longest - самое длинное пробирование (смещение плюс один).
+type HashStats struct {
+	Engine       HashEngine
+	Hash         HashKind
+	Size         int // вместе с просроченными, но ещё не удалёнными ключами
+	Capacity     int
+	Load         float64 // фактическое заполнение size / capacity
+	MaxLoad      float64 // порог роста
+	Longest      int
+	Histogram    []int
+	EmptyBuckets int
+	EmptyRatio   float64
+	Resizes      int
+	Expiring     int // ключей со сроком жизни
+	MemoryBytes  int // приблизительно: массив, записи, строки и сроки жизни
+}
+
+// Stats собирает диагностику таблицы. Перед подсчётом завершается
+// перехэширование, чтобы цифры относились к одному массиву.
+func (h *HashTable) Stats() HashStats {
+	h.finishRehash()
+	stats := HashStats{
+		Engine:   h.engine,
+		Hash:     h.hasher.Kind(),
+		Size:     h.size,
+		Capacity: h.capacity,
+		Load:     float64(h.size) / float64(h.capacity),
+		MaxLoad:  h.loadFactor,
+		Resizes:  h.resizes,
+		Expiring: len(h.expires),
+	}
+
+	textBytes := 0
+	if h.engine == ROBINHOOD_ENGINE {
+		for _, slot := range h.slots {
+			if slot.dist == 0 {
+				stats.EmptyBuckets++
+				continue
+			}
+			stats.Histogram = addToHistogram(stats.Histogram, int(slot.dist-1))
+			if int(slot.dist) > stats.Longest {
+				stats.Longest = int(slot.dist)
+			}
+			textBytes += len(slot.key) + len(slot.value)
+		}
+		stats.MemoryBytes = h.capacity * int(unsafe.Sizeof(robinHoodSlot{}))
+	} else {
+		for _, bucket := range h.buckets {
+			length := 0
+			for current := bucket; current != nil; current = current.Next {
+				length++
+				textBytes += len(current.Key) + len(current.Value)
+			}
+			if length == 0 {
+				stats.EmptyBuckets++
+			}
+			stats.Histogram = addToHistogram(stats.Histogram, length)
+			if length > stats.Longest {
+				stats.Longest = length
+			}
+		}
+		stats.MemoryBytes = h.capacity*int(unsafe.Sizeof(&HashEntry{})) +
+			h.size*int(unsafe.Sizeof(HashEntry{}))
+	}
+
+	stats.EmptyRatio = float64(stats.EmptyBuckets) / float64(h.capacity)
+	stats.MemoryBytes += textBytes + len(h.expires)*expiryEntryOverhead
+	return stats
+}
+
+func addToHistogram(histogram []int, value int) []int {
+	for len(histogram) <= value {
+		histogram = append(histogram, 0)
+	}
+	histogram[value]++
+	return histogram
+}
+
+// histogramString выводит гистограмму как "длина:количество" через пробел
+func (s HashStats) histogramString() string {
+	parts := make([]string, 0, len(s.Histogram))
+	for length, count := range s.Histogram {
+		if count > 0 {
+			parts = append(parts, fmt.Sprintf("%d:%d", length, count))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Print печатает диагностику в том же виде, что и HSTATS
+func (s HashStats) Print(name string) {
+	fmt.Printf("Хеш-таблица '%s':\n", name)
+	fmt.Printf("  Движок: %s, хеш-функция: %s\n", s.Engine, s.Hash)
+	fmt.Printf("  Записей: %d, ёмкость: %d\n", s.Size, s.Capacity)
+	fmt.Printf("  Заполнение: %.3f (порог %g)\n", s.Load, s.MaxLoad)
+	fmt.Printf("  Пустых бакетов: %d (%.1f%%)\n", s.EmptyBuckets, s.EmptyRatio*100)
+	if s.Engine == ROBINHOOD_ENGINE {
+		fmt.Printf("  Самое длинное пробирование: %d\n", s.Longest)
+		fmt.Printf("  Смещения: %s\n", s.histogramString())
+	} else {
+		fmt.Printf("  Самая длинная цепочка: %d\n", s.Longest)
+		fmt.Printf("  Цепочки: %s\n", s.histogramString())
+	}
+	fmt.Printf("  Изменений ёмкости: %d\n", s.Resizes)
+	fmt.Printf("  Ключей со сроком жизни: %d\n", s.Expiring)
+	fmt.Printf("  Память: ~%d байт\n", s.MemoryBytes)
+}
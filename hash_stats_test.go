@@ -0,0 +1,78 @@
+package dbmsgo
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashTable_Stats(t *testing.T) {
+	t.Run("Chaining", func(t *testing.T) {
+		table := newTestHashTable(t, "stats", HashTableOptions{Capacity: 4, Hash: FNV1A_HASH})
+		stats := table.Stats()
+		assert.Equal(t, 0, stats.Size)
+		assert.Equal(t, 4, stats.EmptyBuckets)
+		assert.Equal(t, 1.0, stats.EmptyRatio)
+		assert.Equal(t, []int{4}, stats.Histogram)
+		assert.Equal(t, 0, stats.Resizes)
+
+		for i := 0; i < 100; i++ {
+			table.Insert(fmt.Sprintf("key%d", i), "v")
+		}
+		stats = table.Stats()
+		assert.Equal(t, CHAINING_ENGINE, stats.Engine)
+		assert.Equal(t, FNV1A_HASH, stats.Hash)
+		assert.Equal(t, 100, stats.Size)
+		assert.Equal(t, 256, stats.Capacity)
+		assert.InDelta(t, 100.0/256, stats.Load, 1e-9)
+		assert.Equal(t, 0.7, stats.MaxLoad)
+		assert.Equal(t, 6, stats.Resizes)
+		assert.False(t, table.IsRehashing())
+
+		buckets, entries := 0, 0
+		for length, count := range stats.Histogram {
+			buckets += count
+			entries += length * count
+		}
+		assert.Equal(t, 256, buckets)
+		assert.Equal(t, 100, entries)
+		assert.Equal(t, len(stats.Histogram)-1, stats.Longest)
+		assert.Equal(t, stats.Histogram[0], stats.EmptyBuckets)
+		assert.Greater(t, stats.MemoryBytes, 100*len("key0v"))
+	})
+
+	t.Run("RobinHood", func(t *testing.T) {
+		table := newTestHashTable(t, "stats", HashTableOptions{Capacity: 8, Engine: ROBINHOOD_ENGINE})
+		for i := 0; i < 50; i++ {
+			table.Insert(fmt.Sprintf("key%d", i), "v")
+		}
+		stats := table.Stats()
+		assert.Equal(t, ROBINHOOD_ENGINE, stats.Engine)
+		total := 0
+		for _, count := range stats.Histogram {
+			total += count
+		}
+		assert.Equal(t, 50, total)
+		assert.Equal(t, len(stats.Histogram), stats.Longest)
+		assert.Equal(t, stats.Capacity-50, stats.EmptyBuckets)
+		assert.Equal(t, 3, stats.Resizes)
+	})
+
+	t.Run("ShrinkAndExpiry", func(t *testing.T) {
+		clock := newFakeClock()
+		table := newTestHashTable(t, "stats", HashTableOptions{Capacity: 4})
+		table.SetClock(clock.Now)
+		for i := 0; i < 100; i++ {
+			table.Insert(fmt.Sprintf("key%d", i), "v")
+		}
+		for i := 0; i < 98; i++ {
+			table.Remove(fmt.Sprintf("key%d", i))
+		}
+		table.Expire("key99", time.Minute)
+		stats := table.Stats()
+		assert.Greater(t, stats.Resizes, 6)
+		assert.Equal(t, 1, stats.Expiring)
+	})
+}
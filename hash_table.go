@@ -2,132 +2,488 @@ package dbmsgo
 
 import (
 	"fmt"
+	"math"
+	"strconv"
+	"time"
 )
 
 type HashEntry struct {
 	Key   string
 	Value string
 	Next  *HashEntry
+
+	// Соседи в порядке вставки, используются только при PreserveOrder
+	before *HashEntry
+	after  *HashEntry
+}
+
+const (
+	defaultHashCapacity     = 10
+	defaultHashLoadFactor   = 0.7
+	defaultHashShrinkFactor = 0.1
+
+	// За одну операцию переносится не больше rehashBucketsPerStep непустых
+	// бакетов и просматривается не больше rehashEmptyVisits пустых
+	rehashBucketsPerStep = 4
+	rehashEmptyVisits    = 40
+)
+
+// HashTableOptions - параметры хеш-таблицы, задаваемые при создании.
+// Таблица растёт, когда заполнение достигает LoadFactor, и сжимается вдвое,
+// когда оно падает ниже ShrinkFactor (но не меньше начальной ёмкости).
+// Seed используется ключевой хеш-функцией, 0 - выбрать случайно.
+// Нулевые Capacity, LoadFactor и ShrinkFactor заменяются значениями по
+// умолчанию. ShrinkFactor должен быть меньше LoadFactor/2.
+// Движок ROBINHOOD не сохраняет порядок вставки и требует LoadFactor < 1.
+type HashTableOptions struct {
+	Capacity      int
+	LoadFactor    float64
+	ShrinkFactor  float64
+	PreserveOrder bool
+	Hash          HashKind
+	Seed          uint64
+	Engine        HashEngine
+}
+
+func DefaultHashTableOptions() HashTableOptions {
+	return HashTableOptions{
+		Capacity:     defaultHashCapacity,
+		LoadFactor:   defaultHashLoadFactor,
+		ShrinkFactor: defaultHashShrinkFactor,
+	}
 }
 
 type HashTable struct {
-	name     string
-	buckets  []*HashEntry
-	capacity int
-	size     int
+	name         string
+	buckets      []*HashEntry
+	capacity     int
+	size         int
+	loadFactor   float64
+	shrinkFactor float64
+	minCapacity  int
+	ordered      bool
+	first        *HashEntry
+	last         *HashEntry
+	hasher       HashFunction
+	engine       HashEngine
+
+	// Записи движка ROBINHOOD, buckets при этом не используется
+	slots []robinHoodSlot
+
+	// Во время перехэширования записи из oldBuckets постепенно переносятся
+	// в buckets; бакеты старого массива до rehashIndex уже пусты
+	oldBuckets  []*HashEntry
+	rehashIndex int
+	resizes     int
+
+	// Сроки жизни ключей в миллисекундах Unix и очередь сроков по
+	// возрастанию, см. hash_expiry.go
+	expires     map[string]int64
+	expiryQueue expiryHeap
+	clock       Clock
 }
 
 func NewHashTable(name string) *HashTable {
-	capacity := 10
-	return &HashTable{
-		name:     name,
-		buckets:  make([]*HashEntry, capacity),
-		capacity: capacity,
-		size:     0,
+	// Параметры по умолчанию всегда корректны
+	table, _ := NewHashTableWithOptions(name, DefaultHashTableOptions())
+	return table
+}
+
+// NewHashTableWithOptions создаёт таблицу и возвращает ошибку, если
+// итоговые параметры несовместимы
+func NewHashTableWithOptions(name string, opts HashTableOptions) (*HashTable, error) {
+	if opts.Capacity < 0 || opts.LoadFactor < 0 || opts.ShrinkFactor < 0 {
+		return nil, fmt.Errorf("hash table options must not be negative")
+	}
+	capacity := opts.Capacity
+	if capacity == 0 {
+		capacity = defaultHashCapacity
+	}
+	loadFactor := opts.LoadFactor
+	if loadFactor == 0 {
+		loadFactor = defaultHashLoadFactor
+		if opts.Engine == ROBINHOOD_ENGINE {
+			loadFactor = robinHoodLoadFactor
+		}
+	}
+	if opts.Engine == ROBINHOOD_ENGINE {
+		if opts.PreserveOrder {
+			return nil, fmt.Errorf("robin hood engine does not preserve insertion order")
+		}
+		if loadFactor > robinHoodMaxLoadFactor {
+			return nil, fmt.Errorf("robin hood load factor must not exceed %g", robinHoodMaxLoadFactor)
+		}
+	}
+	// После сжатия вдвое таблица не должна сразу снова расти
+	shrinkFactor := opts.ShrinkFactor
+	if shrinkFactor == 0 {
+		shrinkFactor = defaultHashShrinkFactor
+		if shrinkFactor >= loadFactor/2 {
+			shrinkFactor = loadFactor / 4
+		}
+	}
+	if shrinkFactor >= loadFactor/2 {
+		return nil, fmt.Errorf("shrink factor %g must be below half of load factor %g", shrinkFactor, loadFactor)
+	}
+	seed := opts.Seed
+	if seed == 0 && opts.Hash == SIPHASH_HASH {
+		seed = RandomHashSeed()
 	}
+	table := &HashTable{
+		name:         name,
+		capacity:     capacity,
+		size:         0,
+		loadFactor:   loadFactor,
+		shrinkFactor: shrinkFactor,
+		minCapacity:  capacity,
+		ordered:      opts.PreserveOrder,
+		hasher:       NewHashFunction(opts.Hash, seed),
+		engine:       opts.Engine,
+		clock:        time.Now,
+	}
+	if table.engine == ROBINHOOD_ENGINE {
+		table.slots = make([]robinHoodSlot, capacity)
+	} else {
+		table.buckets = make([]*HashEntry, capacity)
+	}
+	return table, nil
 }
 
 func (h *HashTable) hashFunction(key string) int {
-	hash := 5381
-	for _, c := range key {
-		hash = ((hash << 5) + hash) + int(c)
+	return h.hashFunctionWithCapacity(key, h.capacity)
+}
+
+func (h *HashTable) hashFunctionWithCapacity(key string, cap int) int {
+	return int(h.hasher.Sum64(key) % uint64(cap))
+}
+
+// resize начинает перенос записей в новый массив бакетов. Записи
+// переносятся понемногу при следующих операциях, см. rehashStep.
+func (h *HashTable) resize(newCapacity int) {
+	h.finishRehash()
+	h.resizes++
+	h.oldBuckets = h.buckets
+	h.rehashIndex = 0
+	h.buckets = make([]*HashEntry, newCapacity)
+	h.capacity = newCapacity
+	h.rehashStep()
+}
+
+func (h *HashTable) isRehashing() bool {
+	return h.oldBuckets != nil
+}
+
+// IsRehashing сообщает, что записи ещё переносятся из старого массива
+func (h *HashTable) IsRehashing() bool {
+	return h.isRehashing()
+}
+
+// moveBucket переносит цепочку старого бакета в новый массив
+func (h *HashTable) moveBucket(index int) {
+	current := h.oldBuckets[index]
+	for current != nil {
+		next := current.Next
+		newIndex := h.hashFunction(current.Key)
+		current.Next = h.buckets[newIndex]
+		h.buckets[newIndex] = current
+		current = next
+	}
+	h.oldBuckets[index] = nil
+}
+
+// rehashStep переносит несколько бакетов старого массива
+func (h *HashTable) rehashStep() {
+	if !h.isRehashing() {
+		return
 	}
-	// Исправление: убедимся, что индекс неотрицательный
-	result := hash % h.capacity
-	if result < 0 {
-		return -result
+
+	moved, visited := 0, 0
+	for h.rehashIndex < len(h.oldBuckets) && moved < rehashBucketsPerStep && visited < rehashEmptyVisits {
+		if h.oldBuckets[h.rehashIndex] != nil {
+			h.moveBucket(h.rehashIndex)
+			moved++
+		} else {
+			visited++
+		}
+		h.rehashIndex++
+	}
+	if h.rehashIndex >= len(h.oldBuckets) {
+		h.oldBuckets = nil
+		h.rehashIndex = 0
 	}
-	return result
 }
 
-func (h *HashTable) hashFunctionWithCapacity(key string, cap int) int {
-	hash := 5381
-	for _, c := range key {
-		hash = ((hash << 5) + hash) + int(c)
+// finishRehash переносит все оставшиеся бакеты. Нужен там, где важна
+// раскладка записей: печать, обход и сохранение.
+func (h *HashTable) finishRehash() {
+	for h.isRehashing() {
+		h.moveBucket(h.rehashIndex)
+		h.rehashIndex++
+		if h.rehashIndex >= len(h.oldBuckets) {
+			h.oldBuckets = nil
+			h.rehashIndex = 0
+		}
 	}
-	result := hash % cap
-	if result < 0 {
-		return -result
+}
+
+// find ищет запись в обоих массивах и возвращает указатель на ссылку,
+// которая на неё указывает, чтобы запись можно было удалить
+func (h *HashTable) find(key string) (**HashEntry, *HashEntry) {
+	if h.isRehashing() {
+		index := h.hashFunctionWithCapacity(key, len(h.oldBuckets))
+		for link := &h.oldBuckets[index]; *link != nil; link = &(*link).Next {
+			if (*link).Key == key {
+				return link, *link
+			}
+		}
 	}
-	return result
+	for link := &h.buckets[h.hashFunction(key)]; *link != nil; link = &(*link).Next {
+		if (*link).Key == key {
+			return link, *link
+		}
+	}
+	return nil, nil
 }
 
-func (h *HashTable) resize(newCapacity int) {
-	newBuckets := make([]*HashEntry, newCapacity)
-	
-	for i := 0; i < h.capacity; i++ {
-		current := h.buckets[i]
-		for current != nil {
-			next := current.Next
-			newIndex := h.hashFunctionWithCapacity(current.Key, newCapacity)
-			current.Next = newBuckets[newIndex]
-			newBuckets[newIndex] = current
-			current = next
+// maybeShrink начинает сжатие вдвое, если таблица стала слишком разреженной
+func (h *HashTable) maybeShrink() {
+	if h.isRehashing() || h.capacity <= h.minCapacity {
+		return
+	}
+	if float64(h.size) < float64(h.capacity)*h.shrinkFactor {
+		newCapacity := h.capacity / 2
+		if newCapacity < h.minCapacity {
+			newCapacity = h.minCapacity
 		}
+		h.resize(newCapacity)
 	}
-	
-	h.buckets = newBuckets
-	h.capacity = newCapacity
 }
 
+func (h *HashTable) linkLast(entry *HashEntry) {
+	if !h.ordered {
+		return
+	}
+	entry.before = h.last
+	if h.last != nil {
+		h.last.after = entry
+	} else {
+		h.first = entry
+	}
+	h.last = entry
+}
+
+func (h *HashTable) unlink(entry *HashEntry) {
+	if !h.ordered {
+		return
+	}
+	if entry.before != nil {
+		entry.before.after = entry.after
+	} else {
+		h.first = entry.after
+	}
+	if entry.after != nil {
+		entry.after.before = entry.before
+	} else {
+		h.last = entry.before
+	}
+	entry.before = nil
+	entry.after = nil
+}
+
+// Insert записывает значение ключа. Срок жизни ключа при этом снимается,
+// как при SET в Redis.
 func (h *HashTable) Insert(key, value string) {
-	if h.size >= int(float64(h.capacity)*0.7) {
+	h.expireIfNeeded(key)
+	h.put(key, value)
+	delete(h.expires, key)
+}
+
+// put записывает значение, сохраняя срок жизни ключа
+func (h *HashTable) put(key, value string) {
+	if h.engine == ROBINHOOD_ENGINE {
+		h.slotInsert(key, value)
+		return
+	}
+	h.rehashStep()
+	if _, existing := h.find(key); existing != nil {
+		existing.Value = value
+		return
+	}
+
+	if !h.isRehashing() && h.size >= int(float64(h.capacity)*h.loadFactor) {
 		h.resize(h.capacity * 2)
 	}
-	
+
+	// Новые записи попадают только в новый массив
 	index := h.hashFunction(key)
-	current := h.buckets[index]
-	
-	for current != nil {
-		if current.Key == key {
-			current.Value = value
-			return
-		}
-		current = current.Next
-	}
-	
 	newEntry := &HashEntry{Key: key, Value: value, Next: h.buckets[index]}
 	h.buckets[index] = newEntry
+	h.linkLast(newEntry)
 	h.size++
 }
 
-func (h *HashTable) Search(key string) (string, bool) {
+// restoreEntry добавляет запись в конец цепочки без проверки заполненности.
+// Используется при загрузке, когда ёмкость уже восстановлена из файла и
+// порядок записей в цепочках должен совпасть с сохранённым.
+func (h *HashTable) restoreEntry(key, value string) {
+	if h.engine == ROBINHOOD_ENGINE {
+		h.slotInsert(key, value)
+		return
+	}
+	h.finishRehash()
 	index := h.hashFunction(key)
-	current := h.buckets[index]
-	
-	for current != nil {
-		if current.Key == key {
-			return current.Value, true
+	entry := &HashEntry{Key: key, Value: value}
+
+	if h.buckets[index] == nil {
+		h.buckets[index] = entry
+	} else {
+		current := h.buckets[index]
+		for {
+			if current.Key == key {
+				current.Value = value
+				return
+			}
+			if current.Next == nil {
+				break
+			}
+			current = current.Next
 		}
-		current = current.Next
+		current.Next = entry
+	}
+	h.linkLast(entry)
+	h.size++
+}
+
+func (h *HashTable) Search(key string) (string, bool) {
+	h.expireIfNeeded(key)
+	if h.engine == ROBINHOOD_ENGINE {
+		return h.slotSearch(key)
+	}
+	h.rehashStep()
+	if _, entry := h.find(key); entry != nil {
+		return entry.Value, true
 	}
-	
 	return "", false
 }
 
 func (h *HashTable) Remove(key string) bool {
-	index := h.hashFunction(key)
-	current := h.buckets[index]
-	var prev *HashEntry
-	
-	for current != nil {
-		if current.Key == key {
-			if prev != nil {
-				prev.Next = current.Next
-			} else {
-				h.buckets[index] = current.Next
-			}
-			h.size--
-			return true
+	if h.expireIfNeeded(key) {
+		return false
+	}
+	return h.removeKey(key)
+}
+
+// removeKey удаляет запись вместе со сроком жизни, не проверяя его
+func (h *HashTable) removeKey(key string) bool {
+	delete(h.expires, key)
+	if h.engine == ROBINHOOD_ENGINE {
+		return h.slotRemove(key)
+	}
+	h.rehashStep()
+	link, entry := h.find(key)
+	if entry == nil {
+		return false
+	}
+
+	*link = entry.Next
+	h.unlink(entry)
+	h.size--
+	h.maybeShrink()
+	return true
+}
+
+// SetNX добавляет запись, только если ключа ещё нет
+func (h *HashTable) SetNX(key, value string) bool {
+	if _, found := h.Search(key); found {
+		return false
+	}
+	h.Insert(key, value)
+	return true
+}
+
+// CompareAndSwap заменяет значение ключа на value, только если текущее
+// значение равно expected. Срок жизни ключа сохраняется.
+func (h *HashTable) CompareAndSwap(key, expected, value string) bool {
+	current, found := h.Search(key)
+	if !found || current != expected {
+		return false
+	}
+	h.put(key, value)
+	return true
+}
+
+// IncrBy прибавляет delta к целому значению ключа. Отсутствующий ключ
+// считается равным 0, срок жизни ключа сохраняется.
+func (h *HashTable) IncrBy(key string, delta int64) (int64, error) {
+	current := int64(0)
+	if text, found := h.Search(key); found {
+		var err error
+		if current, err = parseInteger(text); err != nil {
+			return 0, err
 		}
-		prev = current
-		current = current.Next
 	}
-	
-	return false
+	result, err := addInteger(current, delta)
+	if err != nil {
+		return 0, err
+	}
+	h.put(key, strconv.FormatInt(result, 10))
+	return result, nil
+}
+
+// IncrByFloat прибавляет delta к числовому значению ключа
+func (h *HashTable) IncrByFloat(key string, delta float64) (float64, error) {
+	current := 0.0
+	if text, found := h.Search(key); found {
+		var err error
+		if current, err = parseFloat(text); err != nil {
+			return 0, err
+		}
+	}
+	result := current + delta
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		return 0, fmt.Errorf("increment would produce NaN or infinity")
+	}
+	h.put(key, formatFloat(result))
+	return result, nil
+}
+
+// parseInteger разбирает хранимое значение как 64-битное целое
+func parseInteger(text string) (int64, error) {
+	value, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("value '%s' is not an integer or out of range", text)
+	}
+	return value, nil
+}
+
+func parseFloat(text string) (float64, error) {
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, fmt.Errorf("value '%s' is not a valid float", text)
+	}
+	return value, nil
+}
+
+// addInteger складывает числа с проверкой переполнения
+func addInteger(a, b int64) (int64, error) {
+	result := a + b
+	if (b > 0 && result < a) || (b < 0 && result > a) {
+		return 0, fmt.Errorf("increment or decrement would overflow")
+	}
+	return result, nil
+}
+
+// formatFloat записывает число без экспоненты и лишних нулей
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
 }
 
 func (h *HashTable) Print() {
+	if h.engine == ROBINHOOD_ENGINE {
+		h.slotPrint()
+		return
+	}
+	h.finishRehash()
 	fmt.Printf("Хеш-таблица '%s':\n", h.name)
 	for i := 0; i < h.capacity; i++ {
 		fmt.Printf("  [%d]: ", i)
@@ -147,12 +503,15 @@ func (h *HashTable) Print() {
 	}
 }
 
+// GetSize сначала удаляет ключи с истёкшим сроком, поэтому они не
+// учитываются
 func (h *HashTable) GetSize() int {
+	h.dropDue(-1)
 	return h.size
 }
 
 func (h *HashTable) IsEmpty() bool {
-	return h.size == 0
+	return h.GetSize() == 0
 }
 
 func (h *HashTable) GetName() string {
@@ -163,13 +522,89 @@ func (h *HashTable) GetCapacity() int {
 	return h.capacity
 }
 
+// GetBuckets возвращает массив бакетов, предварительно завершив перенос.
+// У таблицы с открытой адресацией бакетов нет, возвращается nil.
 func (h *HashTable) GetBuckets() []*HashEntry {
+	h.finishRehash()
 	return h.buckets
 }
 
+func (h *HashTable) GetLoadFactor() float64 {
+	return h.loadFactor
+}
+
+func (h *HashTable) GetShrinkFactor() float64 {
+	return h.shrinkFactor
+}
+
+// GetMinCapacity возвращает ёмкость, меньше которой таблица не сжимается
+func (h *HashTable) GetMinCapacity() int {
+	return h.minCapacity
+}
+
+func (h *HashTable) GetHashFunction() HashFunction {
+	return h.hasher
+}
+
+func (h *HashTable) IsOrdered() bool {
+	return h.ordered
+}
+
+func (h *HashTable) GetEngine() HashEngine {
+	return h.engine
+}
+
+// Entries возвращает записи в порядке вставки для упорядоченной таблицы
+// и в порядке бакетов и цепочек для обычной.
+// Ключи с истёкшим сроком жизни пропускаются.
+func (h *HashTable) Entries() []*HashEntry {
+	result := make([]*HashEntry, 0, h.size)
+	switch {
+	case h.ordered:
+		for current := h.first; current != nil; current = current.after {
+			result = append(result, current)
+		}
+	case h.engine == ROBINHOOD_ENGINE:
+		result = h.slotEntries()
+	default:
+		h.finishRehash()
+		for i := 0; i < h.capacity; i++ {
+			for current := h.buckets[i]; current != nil; current = current.Next {
+				result = append(result, current)
+			}
+		}
+	}
+	return h.dropExpired(result)
+}
+
+// dropExpired убирает из списка записи с истёкшим сроком жизни
+func (h *HashTable) dropExpired(entries []*HashEntry) []*HashEntry {
+	if len(h.expires) == 0 {
+		return entries
+	}
+	now := h.nowMilli()
+	live := entries[:0]
+	for _, entry := range entries {
+		if !h.isExpired(entry.Key, now) {
+			live = append(live, entry)
+		}
+	}
+	return live
+}
+
 func (h *HashTable) Cleanup() {
 	for i := 0; i < h.capacity; i++ {
-		h.buckets[i] = nil
+		if h.engine == ROBINHOOD_ENGINE {
+			h.slots[i] = robinHoodSlot{}
+		} else {
+			h.buckets[i] = nil
+		}
 	}
+	h.oldBuckets = nil
+	h.rehashIndex = 0
+	h.expires = nil
+	h.expiryQueue = nil
+	h.first = nil
+	h.last = nil
 	h.size = 0
 }
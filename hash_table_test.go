@@ -1,6 +1,8 @@
 package dbmsgo
 
 import (
+	"fmt"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -115,4 +117,394 @@ func TestHashTable(t *testing.T) {
 		assert.True(t, found)
 		assert.Equal(t, "new_value", value)
 	})
+
+	t.Run("Options", func(t *testing.T) {
+		table := newTestHashTable(t, "test_hash", HashTableOptions{Capacity: 64, LoadFactor: 0.5})
+		assert.Equal(t, 64, table.GetCapacity())
+		assert.Equal(t, 0.5, table.GetLoadFactor())
+		assert.False(t, table.IsOrdered())
+
+		for i := 0; i < 32; i++ {
+			table.Insert(fmt.Sprintf("key%d", i), "value")
+		}
+		assert.Equal(t, 64, table.GetCapacity())
+		table.Insert("key32", "value")
+		assert.Equal(t, 128, table.GetCapacity())
+
+		// Некорректные параметры заменяются значениями по умолчанию
+		table = newTestHashTable(t, "test_hash", HashTableOptions{})
+		assert.Equal(t, 10, table.GetCapacity())
+		assert.Equal(t, 0.7, table.GetLoadFactor())
+	})
+
+	t.Run("PreserveOrder", func(t *testing.T) {
+		table := newTestHashTable(t, "test_hash", HashTableOptions{PreserveOrder: true})
+		keys := []string{"zeta", "alpha", "mid", "beta", "omega", "gamma", "delta", "eps", "k1", "k2"}
+		for _, key := range keys {
+			table.Insert(key, key+"_value")
+		}
+		table.Insert("alpha", "updated")
+		table.Remove("mid")
+
+		expected := []string{"zeta", "alpha", "beta", "omega", "gamma", "delta", "eps", "k1", "k2"}
+		entries := table.Entries()
+		assert.Equal(t, len(expected), len(entries))
+		for i, entry := range entries {
+			assert.Equal(t, expected[i], entry.Key)
+		}
+		assert.Equal(t, "updated", entries[1].Value)
+
+		table.Cleanup()
+		assert.Empty(t, table.Entries())
+	})
+
+	t.Run("RestoreEntryKeepsChainOrder", func(t *testing.T) {
+		table := newTestHashTable(t, "test_hash", HashTableOptions{Capacity: 1})
+		table.restoreEntry("a", "1")
+		table.restoreEntry("b", "2")
+		table.restoreEntry("c", "3")
+		table.restoreEntry("b", "4")
+
+		assert.Equal(t, 1, table.GetCapacity())
+		assert.Equal(t, 3, table.GetSize())
+		entries := table.Entries()
+		assert.Equal(t, "a", entries[0].Key)
+		assert.Equal(t, "b", entries[1].Key)
+		assert.Equal(t, "4", entries[1].Value)
+		assert.Equal(t, "c", entries[2].Key)
+	})
+
+	t.Run("HashFunctions", func(t *testing.T) {
+		for _, kind := range []HashKind{SIPHASH_HASH, FNV1A_HASH, DJB2_HASH} {
+			table := newTestHashTable(t, "test_hash", HashTableOptions{Hash: kind, Capacity: 4})
+			assert.Equal(t, kind, table.GetHashFunction().Kind())
+			for i := 0; i < 100; i++ {
+				table.Insert(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+			}
+			for i := 0; i < 100; i++ {
+				value, found := table.Search(fmt.Sprintf("key%d", i))
+				assert.True(t, found)
+				assert.Equal(t, fmt.Sprintf("value%d", i), value)
+			}
+		}
+	})
+
+	t.Run("RandomSeedByDefault", func(t *testing.T) {
+		first := NewHashTable("first")
+		second := NewHashTable("second")
+		assert.Equal(t, SIPHASH_HASH, first.GetHashFunction().Kind())
+		assert.NotEqual(t, uint64(0), first.GetHashFunction().Seed())
+		assert.NotEqual(t, first.GetHashFunction().Seed(), second.GetHashFunction().Seed())
+
+		fixed := newTestHashTable(t, "fixed", HashTableOptions{Seed: 42})
+		assert.Equal(t, uint64(42), fixed.GetHashFunction().Seed())
+	})
+
+	t.Run("IncrementalRehash", func(t *testing.T) {
+		table := newTestHashTable(t, "test_hash", HashTableOptions{Capacity: 64, LoadFactor: 0.5, Hash: FNV1A_HASH})
+		for i := 0; i < 33; i++ {
+			table.Insert(fmt.Sprintf("key%d", i), "v")
+		}
+		// Рост начался, но старый массив ещё не перенесён целиком
+		assert.True(t, table.IsRehashing())
+		assert.Equal(t, 128, table.GetCapacity())
+
+		for i := 0; i < 33; i++ {
+			_, found := table.Search(fmt.Sprintf("key%d", i))
+			assert.True(t, found)
+		}
+		assert.False(t, table.IsRehashing())
+		assert.Equal(t, 33, table.GetSize())
+		assert.Len(t, table.Entries(), 33)
+	})
+
+	t.Run("LayoutFinishesRehash", func(t *testing.T) {
+		table := newTestHashTable(t, "test_hash", HashTableOptions{Capacity: 64, LoadFactor: 0.5})
+		for i := 0; i < 33; i++ {
+			table.Insert(fmt.Sprintf("key%d", i), "v")
+		}
+		assert.True(t, table.IsRehashing())
+
+		count := 0
+		for _, bucket := range table.GetBuckets() {
+			for entry := bucket; entry != nil; entry = entry.Next {
+				count++
+			}
+		}
+		assert.False(t, table.IsRehashing())
+		assert.Equal(t, 33, count)
+	})
+
+	t.Run("Shrink", func(t *testing.T) {
+		table := newTestHashTable(t, "test_hash", HashTableOptions{Capacity: 8, LoadFactor: 0.5, ShrinkFactor: 0.2})
+		assert.Equal(t, 0.2, table.GetShrinkFactor())
+		for i := 0; i < 200; i++ {
+			table.Insert(fmt.Sprintf("key%d", i), "v")
+		}
+		grown := table.GetCapacity()
+		assert.GreaterOrEqual(t, grown, 512)
+
+		for i := 0; i < 195; i++ {
+			assert.True(t, table.Remove(fmt.Sprintf("key%d", i)))
+		}
+		for i := 195; i < 200; i++ {
+			_, found := table.Search(fmt.Sprintf("key%d", i))
+			assert.True(t, found)
+		}
+		assert.Less(t, table.GetCapacity(), grown)
+		assert.GreaterOrEqual(t, table.GetCapacity(), 8)
+
+		// Ниже начальной ёмкости таблица не сжимается
+		for i := 195; i < 200; i++ {
+			table.Remove(fmt.Sprintf("key%d", i))
+		}
+		for i := 0; i < 100; i++ {
+			table.Search("missing")
+		}
+		assert.Equal(t, 8, table.GetCapacity())
+		assert.Equal(t, 8, table.GetMinCapacity())
+		assert.True(t, table.IsEmpty())
+	})
+
+	t.Run("InvalidShrinkFactor", func(t *testing.T) {
+		_, err := NewHashTableWithOptions("test_hash", HashTableOptions{LoadFactor: 0.5, ShrinkFactor: 0.4})
+		assert.Error(t, err)
+		_, err = NewHashTableWithOptions("test_hash", HashTableOptions{LoadFactor: 0.5, ShrinkFactor: -1})
+		assert.Error(t, err)
+		// Порог по умолчанию проверяется по итоговому коэффициенту заполнения
+		_, err = NewHashTableWithOptions("test_hash", HashTableOptions{LoadFactor: 0.15, ShrinkFactor: defaultHashShrinkFactor})
+		assert.Error(t, err)
+		table := newTestHashTable(t, "test_hash", HashTableOptions{LoadFactor: 0.1})
+		assert.Less(t, table.GetShrinkFactor(), 0.05)
+		table = newTestHashTable(t, "test_hash", HashTableOptions{Engine: ROBINHOOD_ENGINE, ShrinkFactor: 0.4})
+		assert.Equal(t, robinHoodLoadFactor, table.GetLoadFactor())
+		assert.Equal(t, 0.4, table.GetShrinkFactor())
+	})
+
+	t.Run("RandomOperationsDuringRehash", func(t *testing.T) {
+		table := newTestHashTable(t, "test_hash", HashTableOptions{Capacity: 2, LoadFactor: 0.75, ShrinkFactor: 0.25, Seed: 7})
+		reference := make(map[string]string)
+		state := uint64(1)
+		for step := 0; step < 20000; step++ {
+			state = splitMix64(state)
+			key := fmt.Sprintf("k%d", state%500)
+			switch state >> 62 {
+			case 0, 1:
+				table.Insert(key, fmt.Sprint(step))
+				reference[key] = fmt.Sprint(step)
+			case 2:
+				_, exists := reference[key]
+				assert.Equal(t, exists, table.Remove(key))
+				delete(reference, key)
+			default:
+				value, found := table.Search(key)
+				expected, exists := reference[key]
+				assert.Equal(t, exists, found)
+				assert.Equal(t, expected, value)
+			}
+			if t.Failed() {
+				return
+			}
+		}
+		assert.Equal(t, len(reference), table.GetSize())
+		assert.Len(t, table.Entries(), len(reference))
+	})
+
+	t.Run("RobinHoodEngine", func(t *testing.T) {
+		table := newTestHashTable(t, "test_hash", HashTableOptions{Capacity: 4, LoadFactor: 0.9, Engine: ROBINHOOD_ENGINE, Seed: 3})
+		assert.Equal(t, ROBINHOOD_ENGINE, table.GetEngine())
+		assert.Nil(t, table.GetBuckets())
+
+		reference := make(map[string]string)
+		state := uint64(11)
+		for step := 0; step < 20000; step++ {
+			state = splitMix64(state)
+			key := fmt.Sprintf("k%d", state%700)
+			switch state >> 62 {
+			case 0, 1:
+				table.Insert(key, fmt.Sprint(step))
+				reference[key] = fmt.Sprint(step)
+			case 2:
+				_, exists := reference[key]
+				assert.Equal(t, exists, table.Remove(key))
+				delete(reference, key)
+			default:
+				value, found := table.Search(key)
+				expected, exists := reference[key]
+				assert.Equal(t, exists, found)
+				assert.Equal(t, expected, value)
+			}
+			if step%1000 == 0 {
+				assertRobinHoodInvariant(t, table)
+			}
+			if t.Failed() {
+				return
+			}
+		}
+		assertRobinHoodInvariant(t, table)
+		assert.Equal(t, len(reference), table.GetSize())
+		for _, entry := range table.Entries() {
+			assert.Equal(t, reference[entry.Key], entry.Value)
+		}
+	})
+
+	t.Run("RobinHoodShrink", func(t *testing.T) {
+		table := newTestHashTable(t, "test_hash", HashTableOptions{Capacity: 8, Engine: ROBINHOOD_ENGINE})
+		for i := 0; i < 1000; i++ {
+			table.Insert(fmt.Sprintf("key%d", i), "v")
+		}
+		assert.GreaterOrEqual(t, table.GetCapacity(), 1024)
+		for i := 0; i < 1000; i++ {
+			table.Remove(fmt.Sprintf("key%d", i))
+		}
+		assert.Equal(t, 8, table.GetCapacity())
+		assert.True(t, table.IsEmpty())
+
+		// Порядок вставки и заполнение >= 1 движком не поддерживаются
+		_, err := NewHashTableWithOptions("test_hash", HashTableOptions{PreserveOrder: true, Engine: ROBINHOOD_ENGINE})
+		assert.Error(t, err)
+		_, err = NewHashTableWithOptions("test_hash", HashTableOptions{LoadFactor: 2, Engine: ROBINHOOD_ENGINE})
+		assert.Error(t, err)
+	})
+	t.Run("AtomicOperations", func(t *testing.T) {
+		for _, engine := range []HashEngine{CHAINING_ENGINE, ROBINHOOD_ENGINE} {
+			table := newTestHashTable(t, "test_hash", HashTableOptions{Engine: engine})
+
+			value, err := table.IncrBy("hits", 3)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(3), value)
+			value, err = table.IncrBy("hits", -1)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(2), value)
+
+			table.Insert("name", "alice")
+			_, err = table.IncrBy("name", 1)
+			assert.ErrorContains(t, err, "'alice' is not an integer")
+			stored, _ := table.Search("name")
+			assert.Equal(t, "alice", stored)
+
+			table.Insert("max", "9223372036854775807")
+			_, err = table.IncrBy("max", 1)
+			assert.ErrorContains(t, err, "overflow")
+
+			price, err := table.IncrByFloat("price", 10.5)
+			assert.NoError(t, err)
+			assert.Equal(t, 10.5, price)
+			price, err = table.IncrByFloat("price", 0.25)
+			assert.NoError(t, err)
+			assert.Equal(t, 10.75, price)
+			stored, _ = table.Search("price")
+			assert.Equal(t, "10.75", stored)
+			_, err = table.IncrByFloat("name", 1)
+			assert.ErrorContains(t, err, "not a valid float")
+			table.Insert("big", "1e308")
+			_, err = table.IncrByFloat("big", 1e308)
+			assert.Error(t, err)
+
+			assert.True(t, table.SetNX("fresh", "1"))
+			assert.False(t, table.SetNX("fresh", "2"))
+			stored, _ = table.Search("fresh")
+			assert.Equal(t, "1", stored)
+
+			assert.False(t, table.CompareAndSwap("fresh", "2", "3"))
+			assert.False(t, table.CompareAndSwap("missing", "", "3"))
+			assert.True(t, table.CompareAndSwap("fresh", "1", "3"))
+			stored, _ = table.Search("fresh")
+			assert.Equal(t, "3", stored)
+		}
+	})
+}
+
+// newTestHashTable создаёт таблицу с параметрами, которые должны быть
+// корректны
+func newTestHashTable(t testing.TB, name string, opts HashTableOptions) *HashTable {
+	table, err := NewHashTableWithOptions(name, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return table
+}
+
+// assertRobinHoodInvariant проверяет, что расстояние каждого слота до
+// дома записано верно и что между домом и записью нет пустых слотов
+func assertRobinHoodInvariant(t *testing.T, table *HashTable) {
+	count := 0
+	for i, slot := range table.slots {
+		if slot.dist == 0 {
+			continue
+		}
+		count++
+		home := int(slot.hash % uint64(table.capacity))
+		assert.Equal(t, (i-home+table.capacity)%table.capacity, int(slot.dist-1))
+		for j := home; j != i; j = (j + 1) % table.capacity {
+			assert.NotZero(t, table.slots[j].dist)
+		}
+	}
+	assert.Equal(t, table.GetSize(), count)
+}
+
+// BenchmarkHashEngines сравнивает цепочки и Robin Hood на миллионе ключей.
+// Метрика bytes/key - прирост кучи после заполнения таблицы.
+func BenchmarkHashEngines(b *testing.B) {
+	const n = 1000000
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+
+	for _, engine := range []HashEngine{CHAINING_ENGINE, ROBINHOOD_ENGINE} {
+		opts := HashTableOptions{Engine: engine, Seed: 1}
+
+		b.Run(fmt.Sprintf("%s/Insert", engine), func(b *testing.B) {
+			var bytesPerKey float64
+			for i := 0; i < b.N; i++ {
+				var before, after runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+				table := newTestHashTable(b, "bench", opts)
+				for _, key := range keys {
+					table.Insert(key, key)
+				}
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				bytesPerKey = float64(after.HeapAlloc-before.HeapAlloc) / n
+				runtime.KeepAlive(table)
+			}
+			b.ReportMetric(bytesPerKey, "bytes/key")
+		})
+
+		b.Run(fmt.Sprintf("%s/Search", engine), func(b *testing.B) {
+			table := newTestHashTable(b, "bench", opts)
+			for _, key := range keys {
+				table.Insert(key, key)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				table.Search(keys[i%n])
+			}
+		})
+
+		b.Run(fmt.Sprintf("%s/SearchMissing", engine), func(b *testing.B) {
+			table := newTestHashTable(b, "bench", opts)
+			for _, key := range keys {
+				table.Insert(key, key)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				table.Search("missing")
+			}
+		})
+
+		b.Run(fmt.Sprintf("%s/InsertRemove", engine), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				table := newTestHashTable(b, "bench", opts)
+				for _, key := range keys {
+					table.Insert(key, key)
+				}
+				for _, key := range keys {
+					table.Remove(key)
+				}
+			}
+		})
+	}
 }
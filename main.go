@@ -26,6 +26,9 @@ func (app *Application) RunInteractive() {
 	fmt.Println("=== Система управления базами данных ===")
 	fmt.Println("Введите HELP для списка команд")
 	
+	stopSweeper := app.parser.StartExpirySweeper(expirySweepInterval)
+	defer stopSweeper()
+
 	app.scanner = bufio.NewScanner(os.Stdin)
 	for {
 		fmt.Print("> ")
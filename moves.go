@@ -0,0 +1,150 @@
+package dbmsgo
+
+import "fmt"
+
+// ListEnd - конец списка, с которого снимается или куда кладётся элемент
+type ListEnd int
+
+const (
+	LIST_FRONT ListEnd = iota
+	LIST_BACK
+)
+
+func (e ListEnd) String() string {
+	if e == LIST_BACK {
+		return "BACK"
+	}
+	return "FRONT"
+}
+
+func ParseListEnd(text string) (ListEnd, error) {
+	switch text {
+	case "FRONT":
+		return LIST_FRONT, nil
+	case "BACK":
+		return LIST_BACK, nil
+	}
+	return LIST_FRONT, fmt.Errorf("unknown list end '%s'", text)
+}
+
+// Команды переноса сначала находят обе структуры и только потом снимают
+// элемент, поэтому при ошибке в имени или типе источник не меняется.
+
+// MoveQueue снимает элемент с начала очереди src и добавляет в конец dst
+func (d *Database) MoveQueue(src, dst string) (string, error) {
+	from := d.FindQueue(src)
+	if from == nil {
+		return "", fmt.Errorf("queue '%s' not found", src)
+	}
+	to := d.FindQueue(dst)
+	if to == nil {
+		return "", fmt.Errorf("queue '%s' not found", dst)
+	}
+
+	value, err := from.Pop()
+	if err != nil {
+		return "", err
+	}
+	to.Push(value)
+	return value, nil
+}
+
+// MoveQueueToStack снимает элемент с начала очереди и кладёт на вершину стека
+func (d *Database) MoveQueueToStack(src, dst string) (string, error) {
+	from := d.FindQueue(src)
+	if from == nil {
+		return "", fmt.Errorf("queue '%s' not found", src)
+	}
+	to := d.FindStack(dst)
+	if to == nil {
+		return "", fmt.Errorf("stack '%s' not found", dst)
+	}
+
+	value, err := from.Pop()
+	if err != nil {
+		return "", err
+	}
+	to.Push(value)
+	return value, nil
+}
+
+// MoveStackToQueue снимает вершину стека и добавляет в конец очереди
+func (d *Database) MoveStackToQueue(src, dst string) (string, error) {
+	from := d.FindStack(src)
+	if from == nil {
+		return "", fmt.Errorf("stack '%s' not found", src)
+	}
+	to := d.FindQueue(dst)
+	if to == nil {
+		return "", fmt.Errorf("queue '%s' not found", dst)
+	}
+
+	value, err := from.Pop()
+	if err != nil {
+		return "", err
+	}
+	to.Push(value)
+	return value, nil
+}
+
+// MoveDLL снимает элемент с конца fromEnd списка src и кладёт на конец
+// toEnd списка dst. src и dst могут совпадать - тогда список вращается.
+func (d *Database) MoveDLL(src, dst string, fromEnd, toEnd ListEnd) (string, error) {
+	from := d.FindDLL(src)
+	if from == nil {
+		return "", fmt.Errorf("doubly linked list '%s' not found", src)
+	}
+	to := d.FindDLL(dst)
+	if to == nil {
+		return "", fmt.Errorf("doubly linked list '%s' not found", dst)
+	}
+	if from.IsEmpty() {
+		return "", fmt.Errorf("list is empty")
+	}
+
+	index := 0
+	if fromEnd == LIST_BACK {
+		index = from.Length() - 1
+	}
+	value, err := from.RemoveAt(index)
+	if err != nil {
+		return "", err
+	}
+	if toEnd == LIST_BACK {
+		to.PushBack(value)
+	} else {
+		to.PushFront(value)
+	}
+	return value, nil
+}
+
+// MoveSLLToDLL снимает элемент с конца fromEnd односвязного списка src и
+// кладёт на конец toEnd двусвязного списка dst
+func (d *Database) MoveSLLToDLL(src, dst string, fromEnd, toEnd ListEnd) (string, error) {
+	from := d.FindSLL(src)
+	if from == nil {
+		return "", fmt.Errorf("singly linked list '%s' not found", src)
+	}
+	to := d.FindDLL(dst)
+	if to == nil {
+		return "", fmt.Errorf("doubly linked list '%s' not found", dst)
+	}
+	if from.IsEmpty() {
+		return "", fmt.Errorf("list is empty")
+	}
+
+	index := 0
+	if fromEnd == LIST_BACK {
+		index = from.Length() - 1
+	}
+	value, err := from.RemoveAt(index)
+	if err != nil {
+		return "", err
+	}
+	if toEnd == LIST_BACK {
+		to.PushBack(value)
+	} else {
+		to.PushFront(value)
+	}
+	return value, nil
+}
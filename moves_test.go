@@ -0,0 +1,97 @@
+package dbmsgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabase_Moves(t *testing.T) {
+	t.Run("QueuesAndStacks", func(t *testing.T) {
+		db := NewDatabase()
+		jobs, done := NewQueue("jobs"), NewQueue("done")
+		stack := NewStack("undo")
+		db.AddQueue(jobs)
+		db.AddQueue(done)
+		db.AddStack(stack)
+		jobs.Push("a")
+		jobs.Push("b")
+
+		value, err := db.MoveQueue("jobs", "done")
+		assert.NoError(t, err)
+		assert.Equal(t, "a", value)
+		assert.Equal(t, []string{"b"}, jobs.Range(0, -1))
+		assert.Equal(t, []string{"a"}, done.Range(0, -1))
+
+		value, err = db.MoveQueueToStack("jobs", "undo")
+		assert.NoError(t, err)
+		assert.Equal(t, "b", value)
+		assert.True(t, jobs.IsEmpty())
+
+		value, err = db.MoveStackToQueue("undo", "jobs")
+		assert.NoError(t, err)
+		assert.Equal(t, "b", value)
+		assert.True(t, stack.IsEmpty())
+
+		_, err = db.MoveStackToQueue("undo", "jobs")
+		assert.Error(t, err)
+
+		// Неверный тип приёмника не снимает элемент с источника
+		_, err = db.MoveQueue("jobs", "undo")
+		assert.Error(t, err)
+		assert.Equal(t, 1, jobs.GetSize())
+		_, err = db.MoveQueueToStack("missing", "undo")
+		assert.Error(t, err)
+	})
+
+	t.Run("Lists", func(t *testing.T) {
+		db := NewDatabase()
+		src, dst := NewDoublyLinkedList("src"), NewDoublyLinkedList("dst")
+		sll := NewSinglyLinkedList("sll")
+		db.AddDLL(src)
+		db.AddDLL(dst)
+		db.AddSLL(sll)
+		for _, value := range []string{"a", "b", "c"} {
+			src.PushBack(value)
+			sll.PushBack(value)
+		}
+
+		value, err := db.MoveDLL("src", "dst", LIST_BACK, LIST_FRONT)
+		assert.NoError(t, err)
+		assert.Equal(t, "c", value)
+		value, err = db.MoveDLL("src", "dst", LIST_FRONT, LIST_FRONT)
+		assert.NoError(t, err)
+		assert.Equal(t, "a", value)
+		assert.Equal(t, []string{"a", "c"}, dllValues(t, dst))
+		assert.Equal(t, []string{"b"}, dllValues(t, src))
+
+		// Перенос внутри одного списка вращает его
+		value, err = db.MoveDLL("dst", "dst", LIST_FRONT, LIST_BACK)
+		assert.NoError(t, err)
+		assert.Equal(t, "a", value)
+		assert.Equal(t, []string{"c", "a"}, dllValues(t, dst))
+
+		value, err = db.MoveSLLToDLL("sll", "dst", LIST_BACK, LIST_BACK)
+		assert.NoError(t, err)
+		assert.Equal(t, "c", value)
+		assert.Equal(t, "b", sll.GetTail().Data)
+		assert.Equal(t, []string{"c", "a", "c"}, dllValues(t, dst))
+
+		_, err = db.MoveSLLToDLL("sll", "sll", LIST_FRONT, LIST_BACK)
+		assert.Error(t, err)
+		assert.Equal(t, 2, sll.Length())
+
+		src.Cleanup()
+		_, err = db.MoveDLL("src", "dst", LIST_FRONT, LIST_BACK)
+		assert.Error(t, err)
+	})
+
+	t.Run("ParseListEnd", func(t *testing.T) {
+		end, err := ParseListEnd("BACK")
+		assert.NoError(t, err)
+		assert.Equal(t, LIST_BACK, end)
+		assert.Equal(t, "FRONT", LIST_FRONT.String())
+		_, err = ParseListEnd("MIDDLE")
+		assert.Error(t, err)
+	})
+}
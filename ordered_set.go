@@ -0,0 +1,96 @@
+package dbmsgo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TreeEngine задаёт структуру данных, на которой построено дерево
+type TreeEngine int
+
+const (
+	AVL_ENGINE TreeEngine = iota
+	RBTREE_ENGINE
+	BTREE_ENGINE
+	SKIPLIST_ENGINE
+	DISK_ENGINE
+)
+
+func (e TreeEngine) String() string {
+	switch e {
+	case RBTREE_ENGINE:
+		return "RBTREE"
+	case BTREE_ENGINE:
+		return "BTREE"
+	case SKIPLIST_ENGINE:
+		return "SKIPLIST"
+	case DISK_ENGINE:
+		return "DISK"
+	}
+	return "AVL"
+}
+
+func ParseTreeEngine(text string) (TreeEngine, error) {
+	switch strings.ToUpper(text) {
+	case "AVL":
+		return AVL_ENGINE, nil
+	case "RBTREE":
+		return RBTREE_ENGINE, nil
+	case "BTREE":
+		return BTREE_ENGINE, nil
+	case "SKIPLIST":
+		return SKIPLIST_ENGINE, nil
+	case "DISK":
+		return DISK_ENGINE, nil
+	}
+	return AVL_ENGINE, fmt.Errorf("unknown tree engine '%s'", text)
+}
+
+// OrderedSet - упорядоченное множество целых чисел. Его реализуют все
+// движки деревьев, поэтому команды и сериализация от движка не зависят.
+type OrderedSet interface {
+	GetName() string
+	GetEngine() TreeEngine
+	Insert(value int64)
+	Remove(value int64)
+	Contains(value int64) bool
+	Size() int
+	IsEmpty() bool
+	SaveTree() []int64
+	BuildFromSorted(values []int64) error
+	PrintInOrder()
+	Cleanup()
+}
+
+// NewOrderedSet создаёт пустое множество на выбранном движке в памяти.
+// Дисковое дерево привязано к файлу и открывается OpenDiskBPlusTree.
+func NewOrderedSet(name string, engine TreeEngine) OrderedSet {
+	switch engine {
+	case RBTREE_ENGINE:
+		return NewRBTree(name)
+	case BTREE_ENGINE:
+		return NewBTree(name)
+	case SKIPLIST_ENGINE:
+		return NewSkipList(name)
+	}
+	return NewAVLTree(name)
+}
+
+// printOrderedSet печатает множество в формате PrintInOrder дерева AVL
+func printOrderedSet(set OrderedSet) {
+	fmt.Printf("Дерево '%s' in-order: ", set.GetName())
+	for _, value := range set.SaveTree() {
+		fmt.Printf("%d ", value)
+	}
+	fmt.Println()
+}
+
+// checkSortedValues проверяет, что значения строго возрастают
+func checkSortedValues(values []int64) error {
+	for i := 1; i < len(values); i++ {
+		if values[i] <= values[i-1] {
+			return fmt.Errorf("values are not strictly ascending at position %d", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package dbmsgo
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var allTreeEngines = []TreeEngine{AVL_ENGINE, RBTREE_ENGINE, BTREE_ENGINE, SKIPLIST_ENGINE}
+
+func TestOrderedSet(t *testing.T) {
+	t.Run("ParseTreeEngine", func(t *testing.T) {
+		for _, engine := range allTreeEngines {
+			parsed, err := ParseTreeEngine(engine.String())
+			assert.NoError(t, err)
+			assert.Equal(t, engine, parsed)
+		}
+		_, err := ParseTreeEngine("HEAP")
+		assert.Error(t, err)
+	})
+
+	for _, engine := range allTreeEngines {
+		t.Run(engine.String(), func(t *testing.T) {
+			set := NewOrderedSet("set", engine)
+			assert.Equal(t, engine, set.GetEngine())
+			assert.Equal(t, "set", set.GetName())
+			assert.True(t, set.IsEmpty())
+
+			for _, v := range []int64{50, 30, 70, 30, 20} {
+				set.Insert(v)
+			}
+			assert.Equal(t, 4, set.Size())
+			assert.Equal(t, []int64{20, 30, 50, 70}, set.SaveTree())
+			assert.True(t, set.Contains(30))
+			assert.False(t, set.Contains(40))
+
+			set.Remove(30)
+			set.Remove(999)
+			assert.Equal(t, []int64{20, 50, 70}, set.SaveTree())
+
+			assert.NoError(t, set.BuildFromSorted([]int64{1, 2, 3}))
+			assert.Equal(t, []int64{1, 2, 3}, set.SaveTree())
+			assert.Equal(t, 3, set.Size())
+			assert.Error(t, set.BuildFromSorted([]int64{2, 1}))
+
+			set.Cleanup()
+			assert.True(t, set.IsEmpty())
+			assert.Empty(t, set.SaveTree())
+		})
+
+		t.Run(engine.String()+"RandomOperations", func(t *testing.T) {
+			set := NewOrderedSet("set", engine)
+			rng := rand.New(rand.NewSource(4))
+			present := make(map[int64]bool)
+
+			for i := 0; i < 20000; i++ {
+				value := rng.Int63n(2000)
+				if rng.Intn(3) == 0 {
+					set.Remove(value)
+					delete(present, value)
+				} else {
+					set.Insert(value)
+					present[value] = true
+				}
+			}
+
+			expected := make([]int64, 0, len(present))
+			for value := range present {
+				expected = append(expected, value)
+			}
+			sort.Slice(expected, func(i, j int) bool { return expected[i] < expected[j] })
+			assert.Equal(t, expected, set.SaveTree())
+			assert.Equal(t, len(expected), set.Size())
+		})
+	}
+}
+
+func BenchmarkOrderedSet(b *testing.B) {
+	const n = 100000
+	values := make([]int64, n)
+	for i, v := range rand.New(rand.NewSource(5)).Perm(n) {
+		values[i] = int64(v)
+	}
+
+	for _, engine := range allTreeEngines {
+		b.Run(fmt.Sprintf("%s/Insert", engine), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				set := NewOrderedSet("bench", engine)
+				for _, v := range values {
+					set.Insert(v)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("%s/InsertRemove", engine), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				set := NewOrderedSet("bench", engine)
+				for _, v := range values {
+					set.Insert(v)
+				}
+				for _, v := range values {
+					set.Remove(v)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("%s/Contains", engine), func(b *testing.B) {
+			set := NewOrderedSet("bench", engine)
+			for _, v := range values {
+				set.Insert(v)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				set.Contains(values[i%n])
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package dbmsgo
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	defaultPageSize = 4096
+	minPageSize     = 64
+	defaultPoolSize = 256 // страниц в буферном пуле
+
+	pagerMagic = "DBMSPG01"
+)
+
+// Заголовок файла занимает страницу 0:
+// magic[8] pageSize u32 pageCount u32 freeHead u32 root u32 keys u64
+const pagerHeaderSize = 32
+
+type pageFrame struct {
+	id    uint32
+	data  []byte
+	dirty bool
+}
+
+// Pager хранит страницы фиксированного размера в одном файле. В памяти
+// держится не больше capacity страниц, самая давно использованная
+// вытесняется первой. Освобождённые страницы связаны в список и
+// используются повторно.
+type Pager struct {
+	file      *os.File
+	pageSize  int
+	capacity  int
+	frames    map[uint32]*list.Element
+	lru       *list.List // в начале - последняя использованная страница
+	pageCount uint32
+	freeHead  uint32 // 0 - свободных страниц нет
+	root      uint32 // корень дерева, хранится в заголовке
+	keys      uint64 // число ключей дерева
+
+	reads     int
+	writes    int
+	evictions int
+}
+
+// OpenPager открывает файл страниц или создаёт новый. Размер страницы
+// существующего файла берётся из его заголовка.
+func OpenPager(path string, pageSize, poolSize int) (*Pager, error) {
+	if pageSize < minPageSize {
+		return nil, fmt.Errorf("page size %d is less than %d", pageSize, minPageSize)
+	}
+	if poolSize < 1 {
+		poolSize = defaultPoolSize
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Pager{
+		file:      file,
+		pageSize:  pageSize,
+		capacity:  poolSize,
+		frames:    make(map[uint32]*list.Element),
+		lru:       list.New(),
+		pageCount: 1,
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.Size() > 0 {
+		if err := p.readHeader(); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *Pager) readHeader() error {
+	header := make([]byte, pagerHeaderSize)
+	if _, err := p.file.ReadAt(header, 0); err != nil {
+		return fmt.Errorf("cannot read page file header: %v", err)
+	}
+	if !bytes.Equal(header[:8], []byte(pagerMagic)) {
+		return fmt.Errorf("not a page file")
+	}
+
+	pageSize := int(binary.LittleEndian.Uint32(header[8:]))
+	if pageSize < minPageSize {
+		return fmt.Errorf("invalid page size %d", pageSize)
+	}
+	p.pageSize = pageSize
+	p.pageCount = binary.LittleEndian.Uint32(header[12:])
+	p.freeHead = binary.LittleEndian.Uint32(header[16:])
+	p.root = binary.LittleEndian.Uint32(header[20:])
+	p.keys = binary.LittleEndian.Uint64(header[24:])
+	if p.pageCount == 0 || p.freeHead >= p.pageCount || p.root >= p.pageCount {
+		return fmt.Errorf("corrupted page file header")
+	}
+	return nil
+}
+
+func (p *Pager) writeHeader() error {
+	header := make([]byte, p.pageSize)
+	copy(header, pagerMagic)
+	binary.LittleEndian.PutUint32(header[8:], uint32(p.pageSize))
+	binary.LittleEndian.PutUint32(header[12:], p.pageCount)
+	binary.LittleEndian.PutUint32(header[16:], p.freeHead)
+	binary.LittleEndian.PutUint32(header[20:], p.root)
+	binary.LittleEndian.PutUint64(header[24:], p.keys)
+	_, err := p.file.WriteAt(header, 0)
+	return err
+}
+
+// page возвращает содержимое страницы из пула, при промахе читает её с диска
+func (p *Pager) page(id uint32) ([]byte, error) {
+	if id == 0 || id >= p.pageCount {
+		return nil, fmt.Errorf("page %d is out of range", id)
+	}
+	if element, ok := p.frames[id]; ok {
+		p.lru.MoveToFront(element)
+		return element.Value.(*pageFrame).data, nil
+	}
+
+	data := make([]byte, p.pageSize)
+	n, err := p.file.ReadAt(data, int64(id)*int64(p.pageSize))
+	if err != nil && (err != io.EOF || n != 0) {
+		return nil, err
+	}
+	// Страница за концом файла ещё не записывалась и состоит из нулей
+	p.reads++
+
+	frame := &pageFrame{id: id, data: data}
+	p.frames[id] = p.lru.PushFront(frame)
+	if err := p.evict(); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// markDirty отмечает, что страница изменена и должна быть записана
+func (p *Pager) markDirty(id uint32) {
+	if element, ok := p.frames[id]; ok {
+		element.Value.(*pageFrame).dirty = true
+	}
+}
+
+// evict вытесняет давно использованные страницы сверх ёмкости пула
+func (p *Pager) evict() error {
+	for p.lru.Len() > p.capacity {
+		element := p.lru.Back()
+		frame := element.Value.(*pageFrame)
+		if err := p.writeFrame(frame); err != nil {
+			return err
+		}
+		p.lru.Remove(element)
+		delete(p.frames, frame.id)
+		p.evictions++
+	}
+	return nil
+}
+
+func (p *Pager) writeFrame(frame *pageFrame) error {
+	if !frame.dirty {
+		return nil
+	}
+	if _, err := p.file.WriteAt(frame.data, int64(frame.id)*int64(p.pageSize)); err != nil {
+		return err
+	}
+	frame.dirty = false
+	p.writes++
+	return nil
+}
+
+// allocate выдаёт чистую страницу, сначала из списка свободных
+func (p *Pager) allocate() (uint32, error) {
+	if p.freeHead == 0 {
+		id := p.pageCount
+		p.pageCount++
+		data, err := p.page(id)
+		if err != nil {
+			return 0, err
+		}
+		clear(data)
+		p.markDirty(id)
+		return id, nil
+	}
+
+	id := p.freeHead
+	data, err := p.page(id)
+	if err != nil {
+		return 0, err
+	}
+	p.freeHead = binary.LittleEndian.Uint32(data[4:])
+	clear(data)
+	p.markDirty(id)
+	return id, nil
+}
+
+// free возвращает страницу в список свободных
+func (p *Pager) free(id uint32) error {
+	data, err := p.page(id)
+	if err != nil {
+		return err
+	}
+	clear(data)
+	binary.LittleEndian.PutUint32(data[4:], p.freeHead)
+	p.freeHead = id
+	p.markDirty(id)
+	return nil
+}
+
+// reset освобождает все страницы файла
+func (p *Pager) reset() error {
+	p.frames = make(map[uint32]*list.Element)
+	p.lru.Init()
+	p.pageCount = 1
+	p.freeHead = 0
+	p.root = 0
+	p.keys = 0
+	if err := p.file.Truncate(int64(p.pageSize)); err != nil {
+		return err
+	}
+	return p.writeHeader()
+}
+
+// Flush записывает изменённые страницы и заголовок на диск
+func (p *Pager) Flush() error {
+	for element := p.lru.Front(); element != nil; element = element.Next() {
+		if err := p.writeFrame(element.Value.(*pageFrame)); err != nil {
+			return err
+		}
+	}
+	if err := p.writeHeader(); err != nil {
+		return err
+	}
+	return p.file.Sync()
+}
+
+func (p *Pager) Close() error {
+	if err := p.Flush(); err != nil {
+		p.file.Close()
+		return err
+	}
+	return p.file.Close()
+}
+
+func (p *Pager) GetPageSize() int {
+	return p.pageSize
+}
+
+func (p *Pager) GetPageCount() int {
+	return int(p.pageCount)
+}
+
+// CachedPages возвращает число страниц, загруженных в пул
+func (p *Pager) CachedPages() int {
+	return p.lru.Len()
+}
+
+// FreePages возвращает длину списка свободных страниц
+func (p *Pager) FreePages() int {
+	count := 0
+	for id := p.freeHead; id != 0; count++ {
+		data, err := p.page(id)
+		if err != nil {
+			return count
+		}
+		id = binary.LittleEndian.Uint32(data[4:])
+	}
+	return count
+}
+
+// Stats возвращает число чтений и записей страниц и вытеснений из пула
+func (p *Pager) Stats() (reads, writes, evictions int) {
+	return p.reads, p.writes, p.evictions
+}
@@ -0,0 +1,98 @@
+package dbmsgo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPager(t *testing.T) {
+	t.Run("AllocateAndFreeList", func(t *testing.T) {
+		pager, err := OpenPager(filepath.Join(t.TempDir(), "pages.db"), minPageSize, 8)
+		assert.NoError(t, err)
+		defer pager.Close()
+
+		first, _ := pager.allocate()
+		second, _ := pager.allocate()
+		assert.Equal(t, uint32(1), first)
+		assert.Equal(t, uint32(2), second)
+		assert.Equal(t, 3, pager.GetPageCount())
+
+		assert.NoError(t, pager.free(first))
+		assert.Equal(t, 1, pager.FreePages())
+		reused, _ := pager.allocate()
+		assert.Equal(t, first, reused)
+		assert.Equal(t, 0, pager.FreePages())
+		assert.Equal(t, 3, pager.GetPageCount())
+	})
+
+	t.Run("LRUEviction", func(t *testing.T) {
+		pager, err := OpenPager(filepath.Join(t.TempDir(), "pages.db"), minPageSize, 2)
+		assert.NoError(t, err)
+		defer pager.Close()
+
+		for i := 0; i < 3; i++ {
+			id, _ := pager.allocate()
+			data, _ := pager.page(id)
+			data[8] = byte(10 + i)
+			pager.markDirty(id)
+		}
+		assert.Equal(t, 2, pager.CachedPages())
+		_, writes, evictions := pager.Stats()
+		assert.Equal(t, 1, evictions)
+		assert.Equal(t, 1, writes)
+
+		// Страница 2 использована недавно, поэтому вытесняется страница 3
+		pager.page(2)
+		pager.page(1)
+		_, hit := pager.frames[2]
+		assert.True(t, hit)
+		_, hit = pager.frames[3]
+		assert.False(t, hit)
+
+		data, _ := pager.page(1)
+		assert.Equal(t, byte(10), data[8])
+	})
+
+	t.Run("Reopen", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pages.db")
+		pager, err := OpenPager(path, 128, 4)
+		assert.NoError(t, err)
+		id, _ := pager.allocate()
+		data, _ := pager.page(id)
+		copy(data, "hello")
+		pager.markDirty(id)
+		pager.root = id
+		pager.keys = 42
+		assert.NoError(t, pager.Close())
+
+		reopened, err := OpenPager(path, defaultPageSize, 4)
+		assert.NoError(t, err)
+		defer reopened.Close()
+		assert.Equal(t, 128, reopened.GetPageSize())
+		assert.Equal(t, id, reopened.root)
+		assert.Equal(t, uint64(42), reopened.keys)
+		data, _ = reopened.page(id)
+		assert.Equal(t, "hello", string(data[:5]))
+	})
+
+	t.Run("InvalidFiles", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := OpenPager(filepath.Join(dir, "small.db"), 16, 4)
+		assert.Error(t, err)
+
+		path := filepath.Join(dir, "garbage.db")
+		assert.NoError(t, os.WriteFile(path, []byte("this is not a page file at all!!"), 0644))
+		_, err = OpenPager(path, defaultPageSize, 4)
+		assert.Error(t, err)
+
+		pager, _ := OpenPager(filepath.Join(dir, "empty.db"), minPageSize, 4)
+		defer pager.Close()
+		_, err = pager.page(0)
+		assert.Error(t, err)
+		_, err = pager.page(5)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,89 @@
+package dbmsgo
+
+// PersistentAVLTree - неизменяемая версия AVL-дерева. Insert и Remove не
+// меняют текущую версию, а возвращают новую: путь от корня до изменённого
+// ключа копируется, остальные узлы остаются общими.
+type PersistentAVLTree struct {
+	tree *AVLTree
+}
+
+func NewPersistentAVLTree(name string, mode TreeKeyMode) *PersistentAVLTree {
+	return &PersistentAVLTree{tree: NewAVLTreeWithMode(name, mode)}
+}
+
+// Persistent возвращает текущее содержимое дерева как неизменяемую версию
+func (a *AVLTree) Persistent() *PersistentAVLTree {
+	return &PersistentAVLTree{tree: a.fork(a.name)}
+}
+
+// next создаёт следующую версию и применяет к ней изменение
+func (p *PersistentAVLTree) next(change func(*AVLTree) error) (*PersistentAVLTree, error) {
+	version := p.tree.newEmptyLike(p.tree.name)
+	version.root = p.tree.root
+	if err := change(version); err != nil {
+		return nil, err
+	}
+	return &PersistentAVLTree{tree: version}, nil
+}
+
+func (p *PersistentAVLTree) Insert(value int64) *PersistentAVLTree {
+	version, _ := p.next(func(tree *AVLTree) error {
+		tree.Insert(value)
+		return nil
+	})
+	return version
+}
+
+func (p *PersistentAVLTree) Remove(value int64) *PersistentAVLTree {
+	version, _ := p.next(func(tree *AVLTree) error {
+		tree.Remove(value)
+		return nil
+	})
+	return version
+}
+
+func (p *PersistentAVLTree) InsertKey(text string) (*PersistentAVLTree, error) {
+	return p.next(func(tree *AVLTree) error {
+		return tree.InsertKey(text)
+	})
+}
+
+func (p *PersistentAVLTree) RemoveKey(text string) (*PersistentAVLTree, error) {
+	return p.next(func(tree *AVLTree) error {
+		return tree.RemoveKey(text)
+	})
+}
+
+func (p *PersistentAVLTree) Put(key, value string) (*PersistentAVLTree, error) {
+	return p.next(func(tree *AVLTree) error {
+		return tree.Put(key, value)
+	})
+}
+
+func (p *PersistentAVLTree) Root() *AVLNode {
+	return p.tree.root
+}
+
+func (p *PersistentAVLTree) Size() int {
+	return p.tree.Size()
+}
+
+func (p *PersistentAVLTree) Search(value int64) *AVLNode {
+	return p.tree.Search(value)
+}
+
+func (p *PersistentAVLTree) SearchKey(text string) (*AVLNode, error) {
+	return p.tree.SearchKey(text)
+}
+
+func (p *PersistentAVLTree) Get(key string) (string, bool) {
+	return p.tree.Get(key)
+}
+
+// Tree возвращает версию как обычное дерево для остальных запросов.
+// Изменения этого дерева не затрагивают версию.
+func (p *PersistentAVLTree) Tree() *AVLTree {
+	version := p.tree.newEmptyLike(p.tree.name)
+	version.root = p.tree.root
+	return version
+}
@@ -0,0 +1,66 @@
+package dbmsgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistentAVLTree(t *testing.T) {
+	t.Run("VersionsAreIndependent", func(t *testing.T) {
+		v0 := NewPersistentAVLTree("versions", INT_KEYS)
+		v1 := v0.Insert(10)
+		v2 := v1.Insert(20)
+		v3 := v2.Remove(10)
+
+		assert.Equal(t, 0, v0.Size())
+		assert.Equal(t, 1, v1.Size())
+		assert.Equal(t, 2, v2.Size())
+		assert.Equal(t, 1, v3.Size())
+		assert.NotNil(t, v2.Search(10))
+		assert.Nil(t, v3.Search(10))
+		assert.NotNil(t, v3.Search(20))
+	})
+
+	t.Run("SharesUnchangedNodes", func(t *testing.T) {
+		version := NewPersistentAVLTree("versions", INT_KEYS)
+		for i := int64(0); i < 1000; i++ {
+			version = version.Insert(i)
+		}
+
+		next := version.Insert(5000)
+		assert.NotSame(t, version.Root(), next.Root())
+		assert.Same(t, version.Root().Left, next.Root().Left)
+		checkAVLInvariants(t, next.Tree(), next.Root())
+		checkAVLInvariants(t, version.Tree(), version.Root())
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		tree := NewOrderedMap("dict", LEX_ORDER)
+		assert.NoError(t, tree.Put("a", "1"))
+
+		v1 := tree.Persistent()
+		v2, err := v1.Put("a", "2")
+		assert.NoError(t, err)
+		tree.Put("a", "3")
+
+		value, _ := v1.Get("a")
+		assert.Equal(t, "1", value)
+		value, _ = v2.Get("a")
+		assert.Equal(t, "2", value)
+		value, _ = tree.Get("a")
+		assert.Equal(t, "3", value)
+
+		_, err = NewPersistentAVLTree("ints", INT_KEYS).InsertKey("x")
+		assert.Error(t, err)
+	})
+
+	t.Run("TreeViewIsDetached", func(t *testing.T) {
+		version := NewPersistentAVLTree("versions", INT_KEYS).Insert(1).Insert(2)
+		view := version.Tree()
+		view.Insert(3)
+		view.Remove(1)
+
+		assert.Equal(t, []int64{1, 2}, version.Tree().SaveTree())
+	})
+}
@@ -58,6 +58,21 @@ func (q *Queue) Peek() (string, error) {
 	return q.front.Data, nil
 }
 
+// Range возвращает элементы с start по stop включительно, считая от
+// начала очереди; отрицательные индексы отсчитываются от конца
+func (q *Queue) Range(start, stop int) []string {
+	from, to := normalizeRange(start, stop, q.size)
+	result := make([]string, 0, to-from)
+	current := q.front
+	for i := 0; i < to; i++ {
+		if i >= from {
+			result = append(result, current.Data)
+		}
+		current = current.Next
+	}
+	return result
+}
+
 func (q *Queue) IsEmpty() bool {
 	return q.front == nil
 }
@@ -90,4 +90,17 @@ func TestQueue(t *testing.T) {
 		assert.True(t, queue.IsEmpty())
 		assert.Equal(t, 0, queue.GetSize())
 	})
+
+	t.Run("Range", func(t *testing.T) {
+		queue := NewQueue("test_queue")
+		for _, value := range []string{"a", "b", "c", "d"} {
+			queue.Push(value)
+		}
+
+		assert.Equal(t, []string{"a", "b", "c", "d"}, queue.Range(0, -1))
+		assert.Equal(t, []string{"b", "c"}, queue.Range(1, 2))
+		assert.Equal(t, []string{"c", "d"}, queue.Range(-2, 10))
+		assert.Empty(t, queue.Range(4, 5))
+		assert.Equal(t, 4, queue.GetSize())
+	})
 }
@@ -0,0 +1,346 @@
+package dbmsgo
+
+type rbColor bool
+
+const (
+	rbRed   rbColor = false
+	rbBlack rbColor = true
+)
+
+type RBNode struct {
+	Data   int64
+	Left   *RBNode
+	Right  *RBNode
+	parent *RBNode
+	color  rbColor
+}
+
+// RBTree - красно-чёрное дерево. Оно балансируется слабее AVL, зато
+// вставка и удаление выполняют не больше трёх поворотов.
+type RBTree struct {
+	name string
+	root *RBNode
+	leaf *RBNode // общий чёрный лист вместо nil
+	size int
+}
+
+func NewRBTree(name string) *RBTree {
+	leaf := &RBNode{color: rbBlack}
+	return &RBTree{
+		name: name,
+		root: leaf,
+		leaf: leaf,
+	}
+}
+
+func (t *RBTree) rotateLeft(x *RBNode) {
+	y := x.Right
+	x.Right = y.Left
+	if y.Left != t.leaf {
+		y.Left.parent = x
+	}
+	y.parent = x.parent
+	if x.parent == t.leaf {
+		t.root = y
+	} else if x == x.parent.Left {
+		x.parent.Left = y
+	} else {
+		x.parent.Right = y
+	}
+	y.Left = x
+	x.parent = y
+}
+
+func (t *RBTree) rotateRight(x *RBNode) {
+	y := x.Left
+	x.Left = y.Right
+	if y.Right != t.leaf {
+		y.Right.parent = x
+	}
+	y.parent = x.parent
+	if x.parent == t.leaf {
+		t.root = y
+	} else if x == x.parent.Right {
+		x.parent.Right = y
+	} else {
+		x.parent.Left = y
+	}
+	y.Right = x
+	x.parent = y
+}
+
+func (t *RBTree) Insert(value int64) {
+	parent := t.leaf
+	current := t.root
+	for current != t.leaf {
+		parent = current
+		if value < current.Data {
+			current = current.Left
+		} else if value > current.Data {
+			current = current.Right
+		} else {
+			// Дубликаты не разрешены
+			return
+		}
+	}
+
+	node := &RBNode{Data: value, Left: t.leaf, Right: t.leaf, parent: parent, color: rbRed}
+	if parent == t.leaf {
+		t.root = node
+	} else if value < parent.Data {
+		parent.Left = node
+	} else {
+		parent.Right = node
+	}
+	t.size++
+	t.insertFixup(node)
+}
+
+func (t *RBTree) insertFixup(z *RBNode) {
+	for z.parent.color == rbRed {
+		if z.parent == z.parent.parent.Left {
+			uncle := z.parent.parent.Right
+			if uncle.color == rbRed {
+				z.parent.color = rbBlack
+				uncle.color = rbBlack
+				z.parent.parent.color = rbRed
+				z = z.parent.parent
+				continue
+			}
+			if z == z.parent.Right {
+				z = z.parent
+				t.rotateLeft(z)
+			}
+			z.parent.color = rbBlack
+			z.parent.parent.color = rbRed
+			t.rotateRight(z.parent.parent)
+		} else {
+			uncle := z.parent.parent.Left
+			if uncle.color == rbRed {
+				z.parent.color = rbBlack
+				uncle.color = rbBlack
+				z.parent.parent.color = rbRed
+				z = z.parent.parent
+				continue
+			}
+			if z == z.parent.Left {
+				z = z.parent
+				t.rotateRight(z)
+			}
+			z.parent.color = rbBlack
+			z.parent.parent.color = rbRed
+			t.rotateLeft(z.parent.parent)
+		}
+	}
+	t.root.color = rbBlack
+}
+
+func (t *RBTree) search(value int64) *RBNode {
+	current := t.root
+	for current != t.leaf && current.Data != value {
+		if value < current.Data {
+			current = current.Left
+		} else {
+			current = current.Right
+		}
+	}
+	return current
+}
+
+// transplant ставит поддерево v на место поддерева u
+func (t *RBTree) transplant(u, v *RBNode) {
+	if u.parent == t.leaf {
+		t.root = v
+	} else if u == u.parent.Left {
+		u.parent.Left = v
+	} else {
+		u.parent.Right = v
+	}
+	v.parent = u.parent
+}
+
+func (t *RBTree) Remove(value int64) {
+	z := t.search(value)
+	if z == t.leaf {
+		return
+	}
+
+	y := z
+	originalColor := y.color
+	var x *RBNode
+	if z.Left == t.leaf {
+		x = z.Right
+		t.transplant(z, z.Right)
+	} else if z.Right == t.leaf {
+		x = z.Left
+		t.transplant(z, z.Left)
+	} else {
+		y = z.Right
+		for y.Left != t.leaf {
+			y = y.Left
+		}
+		originalColor = y.color
+		x = y.Right
+		if y.parent == z {
+			x.parent = y
+		} else {
+			t.transplant(y, y.Right)
+			y.Right = z.Right
+			y.Right.parent = y
+		}
+		t.transplant(z, y)
+		y.Left = z.Left
+		y.Left.parent = y
+		y.color = z.color
+	}
+
+	t.size--
+	if originalColor == rbBlack {
+		t.deleteFixup(x)
+	}
+}
+
+func (t *RBTree) deleteFixup(x *RBNode) {
+	for x != t.root && x.color == rbBlack {
+		if x == x.parent.Left {
+			w := x.parent.Right
+			if w.color == rbRed {
+				w.color = rbBlack
+				x.parent.color = rbRed
+				t.rotateLeft(x.parent)
+				w = x.parent.Right
+			}
+			if w.Left.color == rbBlack && w.Right.color == rbBlack {
+				w.color = rbRed
+				x = x.parent
+				continue
+			}
+			if w.Right.color == rbBlack {
+				w.Left.color = rbBlack
+				w.color = rbRed
+				t.rotateRight(w)
+				w = x.parent.Right
+			}
+			w.color = x.parent.color
+			x.parent.color = rbBlack
+			w.Right.color = rbBlack
+			t.rotateLeft(x.parent)
+			x = t.root
+		} else {
+			w := x.parent.Left
+			if w.color == rbRed {
+				w.color = rbBlack
+				x.parent.color = rbRed
+				t.rotateRight(x.parent)
+				w = x.parent.Left
+			}
+			if w.Right.color == rbBlack && w.Left.color == rbBlack {
+				w.color = rbRed
+				x = x.parent
+				continue
+			}
+			if w.Left.color == rbBlack {
+				w.Right.color = rbBlack
+				w.color = rbRed
+				t.rotateLeft(w)
+				w = x.parent.Left
+			}
+			w.color = x.parent.color
+			x.parent.color = rbBlack
+			w.Left.color = rbBlack
+			t.rotateRight(x.parent)
+			x = t.root
+		}
+	}
+	x.color = rbBlack
+}
+
+func (t *RBTree) Contains(value int64) bool {
+	return t.search(value) != t.leaf
+}
+
+func (t *RBTree) saveTreeHelper(node *RBNode, result *[]int64) {
+	if node != t.leaf {
+		t.saveTreeHelper(node.Left, result)
+		*result = append(*result, node.Data)
+		t.saveTreeHelper(node.Right, result)
+	}
+}
+
+func (t *RBTree) SaveTree() []int64 {
+	result := make([]int64, 0, t.size)
+	t.saveTreeHelper(t.root, &result)
+	return result
+}
+
+func (t *RBTree) buildSortedHelper(values []int64, lo, hi, depth, redDepth int) *RBNode {
+	if lo > hi {
+		return t.leaf
+	}
+
+	mid := lo + (hi-lo)/2
+	node := &RBNode{Data: values[mid], color: rbBlack}
+	// Узлы самого нижнего неполного уровня красные, тогда чёрная высота
+	// всех путей одинакова
+	if depth == redDepth {
+		node.color = rbRed
+	}
+	node.Left = t.buildSortedHelper(values, lo, mid-1, depth+1, redDepth)
+	node.Right = t.buildSortedHelper(values, mid+1, hi, depth+1, redDepth)
+	if node.Left != t.leaf {
+		node.Left.parent = node
+	}
+	if node.Right != t.leaf {
+		node.Right.parent = node
+	}
+	return node
+}
+
+// BuildFromSorted строит дерево из строго возрастающей последовательности за O(n)
+func (t *RBTree) BuildFromSorted(values []int64) error {
+	if err := checkSortedValues(values); err != nil {
+		return err
+	}
+
+	// Уровень, который заполнен не полностью (если такой есть)
+	redDepth := 0
+	for full := 1; full <= len(values); full = full*2 + 1 {
+		redDepth++
+	}
+	if len(values) == (1<<redDepth)-1 {
+		redDepth = -1
+	}
+
+	t.root = t.buildSortedHelper(values, 0, len(values)-1, 0, redDepth)
+	t.root.parent = t.leaf
+	if t.root != t.leaf {
+		t.root.color = rbBlack
+	}
+	t.size = len(values)
+	return nil
+}
+
+func (t *RBTree) GetName() string {
+	return t.name
+}
+
+func (t *RBTree) GetEngine() TreeEngine {
+	return RBTREE_ENGINE
+}
+
+func (t *RBTree) Size() int {
+	return t.size
+}
+
+func (t *RBTree) IsEmpty() bool {
+	return t.size == 0
+}
+
+func (t *RBTree) PrintInOrder() {
+	printOrderedSet(t)
+}
+
+func (t *RBTree) Cleanup() {
+	t.root = t.leaf
+	t.size = 0
+}
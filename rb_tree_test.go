@@ -0,0 +1,83 @@
+package dbmsgo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRBTree(t *testing.T) {
+	t.Run("InvariantsUnderRandomOperations", func(t *testing.T) {
+		tree := NewRBTree("rb")
+		rng := rand.New(rand.NewSource(6))
+
+		for i := 0; i < 5000; i++ {
+			value := rng.Int63n(500)
+			if rng.Intn(3) == 0 {
+				tree.Remove(value)
+			} else {
+				tree.Insert(value)
+			}
+			if i%500 == 0 {
+				checkRBInvariants(t, tree)
+			}
+		}
+		checkRBInvariants(t, tree)
+	})
+
+	t.Run("BuildFromSorted", func(t *testing.T) {
+		for n := 0; n <= 70; n++ {
+			values := make([]int64, n)
+			for i := range values {
+				values[i] = int64(i * 2)
+			}
+			tree := NewRBTree("rb")
+			assert.NoError(t, tree.BuildFromSorted(values))
+			checkRBInvariants(t, tree)
+
+			// Дерево после построения должно поддерживать обычные операции
+			tree.Insert(1)
+			tree.Remove(0)
+			checkRBInvariants(t, tree)
+		}
+	})
+}
+
+// checkRBInvariants проверяет порядок ключей, цвета и чёрную высоту
+func checkRBInvariants(t *testing.T, tree *RBTree) {
+	assert.Equal(t, rbBlack, tree.root.color)
+	assert.Equal(t, rbBlack, tree.leaf.color)
+
+	var walk func(node *RBNode, lo, hi *int64) int
+	walk = func(node *RBNode, lo, hi *int64) int {
+		if node == tree.leaf {
+			return 1
+		}
+		if lo != nil {
+			assert.Greater(t, node.Data, *lo)
+		}
+		if hi != nil {
+			assert.Less(t, node.Data, *hi)
+		}
+		if node.color == rbRed {
+			assert.Equal(t, rbBlack, node.Left.color)
+			assert.Equal(t, rbBlack, node.Right.color)
+		}
+		if node.Left != tree.leaf {
+			assert.Same(t, node, node.Left.parent)
+		}
+		if node.Right != tree.leaf {
+			assert.Same(t, node, node.Right.parent)
+		}
+		left := walk(node.Left, lo, &node.Data)
+		right := walk(node.Right, &node.Data, hi)
+		assert.Equal(t, left, right)
+		if node.color == rbBlack {
+			return left + 1
+		}
+		return left
+	}
+	walk(tree.root, nil, nil)
+	assert.Equal(t, tree.Size(), len(tree.SaveTree()))
+}
@@ -0,0 +1,199 @@
+package dbmsgo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HashEngine задаёт способ хранения записей хеш-таблицы
+type HashEngine int
+
+const (
+	CHAINING_ENGINE  HashEngine = iota // цепочки HashEntry, по умолчанию
+	ROBINHOOD_ENGINE                   // открытая адресация, Robin Hood
+)
+
+// Коэффициент заполнения открытой адресации должен быть меньше 1,
+// иначе в массиве не останется пустых слотов. Короткие цепочки
+// пробирования Robin Hood позволяют заполнять массив плотнее цепочек.
+const (
+	robinHoodLoadFactor    = 0.9
+	robinHoodMaxLoadFactor = 0.95
+)
+
+func (e HashEngine) String() string {
+	if e == ROBINHOOD_ENGINE {
+		return "ROBINHOOD"
+	}
+	return "CHAINING"
+}
+
+func ParseHashEngine(text string) (HashEngine, error) {
+	switch strings.ToUpper(text) {
+	case "CHAINING":
+		return CHAINING_ENGINE, nil
+	case "ROBINHOOD":
+		return ROBINHOOD_ENGINE, nil
+	}
+	return CHAINING_ENGINE, fmt.Errorf("unknown hash engine '%s'", text)
+}
+
+// robinHoodSlot хранит запись прямо в массиве. dist - расстояние от
+// домашнего слота плюс один, 0 означает пустой слот.
+type robinHoodSlot struct {
+	key   string
+	value string
+	hash  uint64
+	dist  uint32
+}
+
+// slotFind возвращает индекс слота с ключом или -1. Поиск останавливается
+// на слоте, который ближе к своему дому, чем искомый ключ был бы к своему.
+func (h *HashTable) slotFind(key string) int {
+	hash := h.hasher.Sum64(key)
+	index := int(hash % uint64(h.capacity))
+	for dist := uint32(1); ; dist++ {
+		slot := &h.slots[index]
+		if slot.dist == 0 || slot.dist < dist {
+			return -1
+		}
+		if slot.hash == hash && slot.key == key {
+			return index
+		}
+		index++
+		if index == h.capacity {
+			index = 0
+		}
+	}
+}
+
+// slotPlace вставляет запись, которой заведомо нет в таблице. Запись
+// забирает слот у той, что ближе к своему дому, и дальше двигается вытесненная.
+func (h *HashTable) slotPlace(entry robinHoodSlot) {
+	index := int(entry.hash % uint64(h.capacity))
+	entry.dist = 1
+	for {
+		slot := &h.slots[index]
+		if slot.dist == 0 {
+			*slot = entry
+			return
+		}
+		if slot.dist < entry.dist {
+			*slot, entry = entry, *slot
+		}
+		entry.dist++
+		index++
+		if index == h.capacity {
+			index = 0
+		}
+	}
+}
+
+// slotResize переносит все записи в массив новой ёмкости за один проход
+func (h *HashTable) slotResize(newCapacity int) {
+	old := h.slots
+	h.resizes++
+	h.slots = make([]robinHoodSlot, newCapacity)
+	h.capacity = newCapacity
+	for _, slot := range old {
+		if slot.dist != 0 {
+			h.slotPlace(slot)
+		}
+	}
+}
+
+func (h *HashTable) slotInsert(key, value string) {
+	if index := h.slotFind(key); index >= 0 {
+		h.slots[index].value = value
+		return
+	}
+
+	if float64(h.size+1) > float64(h.capacity)*h.loadFactor {
+		h.slotResize(h.capacity * 2)
+	}
+	h.slotPlace(robinHoodSlot{key: key, value: value, hash: h.hasher.Sum64(key)})
+	h.size++
+}
+
+func (h *HashTable) slotSearch(key string) (string, bool) {
+	if index := h.slotFind(key); index >= 0 {
+		return h.slots[index].value, true
+	}
+	return "", false
+}
+
+// slotRemove удаляет запись обратным сдвигом: следующие записи цепочки
+// пробирования сдвигаются на слот назад, поэтому надгробия не нужны.
+func (h *HashTable) slotRemove(key string) bool {
+	index := h.slotFind(key)
+	if index < 0 {
+		return false
+	}
+
+	for {
+		next := index + 1
+		if next == h.capacity {
+			next = 0
+		}
+		if h.slots[next].dist <= 1 {
+			break
+		}
+		h.slots[index] = h.slots[next]
+		h.slots[index].dist--
+		index = next
+	}
+	h.slots[index] = robinHoodSlot{}
+	h.size--
+
+	if h.capacity > h.minCapacity && float64(h.size) < float64(h.capacity)*h.shrinkFactor {
+		newCapacity := h.capacity / 2
+		if newCapacity < h.minCapacity {
+			newCapacity = h.minCapacity
+		}
+		h.slotResize(newCapacity)
+	}
+	return true
+}
+
+// slotBucket собирает записи с домашним слотом home. В Robin Hood записи
+// серии упорядочены по домашнему слоту, поэтому они идут подряд: сначала
+// пропускаются сдвинутые с более ранних слотов, затем идут записи home.
+func (h *HashTable) slotBucket(home int, result []*HashEntry) []*HashEntry {
+	index := home
+	for offset := uint32(0); offset < uint32(h.capacity); offset++ {
+		slot := &h.slots[index]
+		if slot.dist == 0 || slot.dist-1 < offset {
+			break
+		}
+		if slot.dist-1 == offset {
+			result = append(result, &HashEntry{Key: slot.key, Value: slot.value})
+		}
+		index++
+		if index == h.capacity {
+			index = 0
+		}
+	}
+	return result
+}
+
+// slotEntries возвращает копии записей в порядке слотов
+func (h *HashTable) slotEntries() []*HashEntry {
+	result := make([]*HashEntry, 0, h.size)
+	for _, slot := range h.slots {
+		if slot.dist != 0 {
+			result = append(result, &HashEntry{Key: slot.key, Value: slot.value})
+		}
+	}
+	return result
+}
+
+func (h *HashTable) slotPrint() {
+	fmt.Printf("Хеш-таблица '%s' (Robin Hood):\n", h.name)
+	for i, slot := range h.slots {
+		if slot.dist == 0 {
+			fmt.Printf("  [%d]: NULL\n", i)
+		} else {
+			fmt.Printf("  [%d]: {%s: %s} +%d\n", i, slot.key, slot.value, slot.dist-1)
+		}
+	}
+}
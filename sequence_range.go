@@ -0,0 +1,25 @@
+package dbmsgo
+
+// normalizeRange переводит границы start и stop (включительно) в
+// полуинтервал [from, to) для последовательности длины length.
+// Отрицательные индексы отсчитываются от конца, как в Redis: -1 -
+// последний элемент. Границы за пределами обрезаются; пустой диапазон
+// даёт from == to.
+func normalizeRange(start, stop, length int) (int, int) {
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+	if start > stop {
+		return 0, 0
+	}
+	return start, stop + 1
+}
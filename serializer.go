@@ -4,8 +4,9 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 	"strconv"
-	// Удаляем "strings" - он не используется
+	"strings"
 )
 
 type SerializationFormat int
@@ -15,6 +16,54 @@ const (
 	BINARY
 )
 
+// TreeEncoding задаёт способ записи AVL-деревьев.
+type TreeEncoding int
+
+const (
+	// TREE_SORTED - отсортированные значения (in-order), при загрузке
+	// дерево строится заново как идеально сбалансированное.
+	TREE_SORTED TreeEncoding = iota
+	// TREE_SHAPE - прямой обход с высотами, форма дерева сохраняется точно.
+	TREE_SHAPE
+)
+
+// HashEncoding задаёт способ записи хеш-таблиц.
+type HashEncoding int
+
+const (
+	// HASH_AUTO - раскладка записывается только для таблиц, которые иначе
+	// потеряют параметры, порядок записей или ключ хеш-функции.
+	HASH_AUTO HashEncoding = iota
+	// HASH_ENTRIES - только пары ключ-значение, при загрузке таблица
+	// создаётся с параметрами по умолчанию и новым ключом SipHash.
+	HASH_ENTRIES
+	// HASH_LAYOUT - дополнительно ёмкость, коэффициент заполнения и порядок
+	// записей, так что сохранение после загрузки даёт тот же результат.
+	HASH_LAYOUT
+)
+
+func (e HashEncoding) String() string {
+	switch e {
+	case HASH_ENTRIES:
+		return "ENTRIES"
+	case HASH_LAYOUT:
+		return "LAYOUT"
+	}
+	return "AUTO"
+}
+
+func ParseHashEncoding(text string) (HashEncoding, error) {
+	switch text {
+	case "AUTO":
+		return HASH_AUTO, nil
+	case "ENTRIES":
+		return HASH_ENTRIES, nil
+	case "LAYOUT":
+		return HASH_LAYOUT, nil
+	}
+	return HASH_AUTO, fmt.Errorf("unknown hash encoding '%s'", text)
+}
+
 type Serializer struct{}
 
 func NewSerializer() *Serializer {
@@ -44,7 +93,12 @@ func (s *Serializer) readStringBinary(r io.Reader) (string, error) {
 	return string(data), nil
 }
 
+// writeIntBinary пишет счётчики и длины (int32). Значения, не влезающие
+// в int32, не обрезаются молча, а возвращают ошибку.
 func (s *Serializer) writeIntBinary(value int, w io.Writer) error {
+	if value < math.MinInt32 || value > math.MaxInt32 {
+		return fmt.Errorf("value %d does not fit into int32", value)
+	}
 	return binary.Write(w, binary.LittleEndian, int32(value))
 }
 
@@ -56,6 +110,15 @@ func (s *Serializer) readIntBinary(r io.Reader) (int, error) {
 	return int(value), nil
 }
 
+// writeInt64Binary пишет значения данных (ключи деревьев) без потери разрядов
+func (s *Serializer) writeInt64Binary(value int64, w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, value)
+}
+
+func (s *Serializer) writeFloatBinary(value float64, w io.Writer) error {
+	return binary.Write(w, binary.LittleEndian, value)
+}
+
 func (s *Serializer) SerializeArray(arr *Array, w io.Writer, format SerializationFormat) error {
 	if arr == nil {
 		return fmt.Errorf("array is nil")
@@ -259,15 +322,26 @@ func (s *Serializer) SerializeTree(tree *AVLTree, w io.Writer, format Serializat
 		return fmt.Errorf("tree is nil")
 	}
 	
+	if tree.IsMultiset() {
+		return s.serializeTreeCounts(tree, w, format)
+	}
+	switch tree.GetMode() {
+	case BIGINT_KEYS:
+		return s.serializeTreeKeys(tree, "TREE_BIG", w, format)
+	case STRING_KEYS:
+		return s.serializeTreeMap(tree, w, format)
+	}
+	
 	values := tree.SaveTree()
 	
 	if format == TEXT {
-		line := fmt.Sprintf("TREE %s %d", tree.GetName(), len(values))
+		var line strings.Builder
+		fmt.Fprintf(&line, "TREE %s %d", tree.GetName(), len(values))
 		for _, value := range values {
-			line += " " + strconv.Itoa(value)
+			line.WriteString(" " + strconv.FormatInt(value, 10))
 		}
-		line += "\n"
-		_, err := w.Write([]byte(line))
+		line.WriteString("\n")
+		_, err := io.WriteString(w, line.String())
 		return err
 	} else {
 		if err := s.writeStringBinary("TREE", w); err != nil {
@@ -281,7 +355,230 @@ func (s *Serializer) SerializeTree(tree *AVLTree, w io.Writer, format Serializat
 		}
 		
 		for _, value := range values {
-			if err := s.writeIntBinary(value, w); err != nil {
+			if err := s.writeInt64Binary(value, w); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SerializeOrderedSet сохраняет дерево на любом движке. Дерево AVL пишется
+// как обычно, остальные движки - записью TREE_ENGINE с именем движка.
+func (s *Serializer) SerializeOrderedSet(set OrderedSet, w io.Writer, format SerializationFormat) error {
+	if set == nil {
+		return fmt.Errorf("tree is nil")
+	}
+	if tree, ok := set.(*AVLTree); ok {
+		return s.SerializeTree(tree, w, format)
+	}
+	if tree, ok := set.(*DiskBPlusTree); ok {
+		return s.serializeDiskTree(tree, w, format)
+	}
+
+	values := set.SaveTree()
+
+	if format == TEXT {
+		var line strings.Builder
+		fmt.Fprintf(&line, "TREE_ENGINE %s %s %d", set.GetName(), set.GetEngine(), len(values))
+		for _, value := range values {
+			line.WriteString(" " + strconv.FormatInt(value, 10))
+		}
+		line.WriteString("\n")
+		_, err := io.WriteString(w, line.String())
+		return err
+	} else {
+		if err := s.writeStringBinary("TREE_ENGINE", w); err != nil {
+			return err
+		}
+		if err := s.writeStringBinary(set.GetName(), w); err != nil {
+			return err
+		}
+		if err := s.writeStringBinary(set.GetEngine().String(), w); err != nil {
+			return err
+		}
+		if err := s.writeIntBinary(len(values), w); err != nil {
+			return err
+		}
+
+		for _, value := range values {
+			if err := s.writeInt64Binary(value, w); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// serializeDiskTree сбрасывает страницы дискового дерева и сохраняет
+// только ссылку на его файл: TREE_DISK name path
+func (s *Serializer) serializeDiskTree(tree *DiskBPlusTree, w io.Writer, format SerializationFormat) error {
+	if err := tree.Flush(); err != nil {
+		return err
+	}
+	if strings.ContainsAny(tree.GetPath(), " \t\n") {
+		return fmt.Errorf("tree file path '%s' contains whitespace", tree.GetPath())
+	}
+
+	if format == TEXT {
+		_, err := fmt.Fprintf(w, "TREE_DISK %s %s\n", tree.GetName(), tree.GetPath())
+		return err
+	}
+	if err := s.writeStringBinary("TREE_DISK", w); err != nil {
+		return err
+	}
+	if err := s.writeStringBinary(tree.GetName(), w); err != nil {
+		return err
+	}
+	return s.writeStringBinary(tree.GetPath(), w)
+}
+
+// serializeTreeKeys пишет ключи дерева в десятичной записи. Так хранятся
+// ключи произвольной длины, в бинарном формате - как строки.
+func (s *Serializer) serializeTreeKeys(tree *AVLTree, tag string, w io.Writer, format SerializationFormat) error {
+	keys := tree.SaveKeys()
+	
+	if format == TEXT {
+		var line strings.Builder
+		fmt.Fprintf(&line, "%s %s %d", tag, tree.GetName(), len(keys))
+		for _, key := range keys {
+			line.WriteString(" " + key)
+		}
+		line.WriteString("\n")
+		_, err := io.WriteString(w, line.String())
+		return err
+	} else {
+		if err := s.writeStringBinary(tag, w); err != nil {
+			return err
+		}
+		if err := s.writeStringBinary(tree.GetName(), w); err != nil {
+			return err
+		}
+		if err := s.writeIntBinary(len(keys), w); err != nil {
+			return err
+		}
+		
+		for _, key := range keys {
+			if err := s.writeStringBinary(key, w); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// serializeTreeCounts пишет мультимножество: различные ключи и их кратности
+func (s *Serializer) serializeTreeCounts(tree *AVLTree, w io.Writer, format SerializationFormat) error {
+	keys, counts := tree.SaveCounts()
+	
+	if format == TEXT {
+		var line strings.Builder
+		fmt.Fprintf(&line, "TREE_MULTI %s %s %d", tree.GetName(), tree.GetMode(), len(keys))
+		for i, key := range keys {
+			line.WriteString(" " + key + " " + strconv.Itoa(counts[i]))
+		}
+		line.WriteString("\n")
+		_, err := io.WriteString(w, line.String())
+		return err
+	} else {
+		if err := s.writeStringBinary("TREE_MULTI", w); err != nil {
+			return err
+		}
+		if err := s.writeStringBinary(tree.GetName(), w); err != nil {
+			return err
+		}
+		if err := s.writeStringBinary(tree.GetMode().String(), w); err != nil {
+			return err
+		}
+		if err := s.writeIntBinary(len(keys), w); err != nil {
+			return err
+		}
+		
+		for i, key := range keys {
+			if err := s.writeStringBinary(key, w); err != nil {
+				return err
+			}
+			if err := s.writeInt64Binary(int64(counts[i]), w); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Serializer) serializeTreeMap(tree *AVLTree, w io.Writer, format SerializationFormat) error {
+	keys, values := tree.SaveEntries()
+	
+	if format == TEXT {
+		var line strings.Builder
+		fmt.Fprintf(&line, "TREE_MAP %s %s %d", tree.GetName(), tree.GetOrdering(), len(keys))
+		for i, key := range keys {
+			line.WriteString(" " + key + " " + values[i])
+		}
+		line.WriteString("\n")
+		_, err := io.WriteString(w, line.String())
+		return err
+	} else {
+		if err := s.writeStringBinary("TREE_MAP", w); err != nil {
+			return err
+		}
+		if err := s.writeStringBinary(tree.GetName(), w); err != nil {
+			return err
+		}
+		if err := s.writeStringBinary(tree.GetOrdering().String(), w); err != nil {
+			return err
+		}
+		if err := s.writeIntBinary(len(keys), w); err != nil {
+			return err
+		}
+		
+		for i, key := range keys {
+			if err := s.writeStringBinary(key, w); err != nil {
+				return err
+			}
+			if err := s.writeStringBinary(values[i], w); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Serializer) SerializeTreeShape(tree *AVLTree, w io.Writer, format SerializationFormat) error {
+	if tree == nil {
+		return fmt.Errorf("tree is nil")
+	}
+	if tree.GetMode() != INT_KEYS || tree.IsMultiset() {
+		return fmt.Errorf("shape encoding supports only integer sets")
+	}
+	
+	values, heights := tree.SaveTreeShape()
+	
+	if format == TEXT {
+		var line strings.Builder
+		fmt.Fprintf(&line, "TREE_SHAPE %s %d", tree.GetName(), len(values))
+		for i, value := range values {
+			line.WriteString(" " + strconv.FormatInt(value, 10) + " " + strconv.Itoa(heights[i]))
+		}
+		line.WriteString("\n")
+		_, err := io.WriteString(w, line.String())
+		return err
+	} else {
+		if err := s.writeStringBinary("TREE_SHAPE", w); err != nil {
+			return err
+		}
+		if err := s.writeStringBinary(tree.GetName(), w); err != nil {
+			return err
+		}
+		if err := s.writeIntBinary(len(values), w); err != nil {
+			return err
+		}
+		
+		for i, value := range values {
+			if err := s.writeInt64Binary(value, w); err != nil {
+				return err
+			}
+			if err := s.writeIntBinary(heights[i], w); err != nil {
 				return err
 			}
 		}
@@ -295,17 +592,13 @@ func (s *Serializer) SerializeHashTable(table *HashTable, w io.Writer, format Se
 	}
 	
 	if format == TEXT {
-		line := fmt.Sprintf("HASH %s %d", table.GetName(), table.GetSize())
-		
-		for i := 0; i < table.GetCapacity(); i++ {
-			current := table.GetBuckets()[i]
-			for current != nil {
-				line += " " + current.Key + " " + current.Value
-				current = current.Next
-			}
+		var line strings.Builder
+		fmt.Fprintf(&line, "HASH %s %d", table.GetName(), table.GetSize())
+		for _, entry := range table.Entries() {
+			line.WriteString(" " + entry.Key + " " + entry.Value)
 		}
-		line += "\n"
-		_, err := w.Write([]byte(line))
+		line.WriteString("\n")
+		_, err := io.WriteString(w, line.String())
 		return err
 	} else {
 		if err := s.writeStringBinary("HASH", w); err != nil {
@@ -318,22 +611,135 @@ func (s *Serializer) SerializeHashTable(table *HashTable, w io.Writer, format Se
 			return err
 		}
 		
-		for i := 0; i < table.GetCapacity(); i++ {
-			current := table.GetBuckets()[i]
-			for current != nil {
-				if err := s.writeStringBinary(current.Key, w); err != nil {
-					return err
-				}
-				if err := s.writeStringBinary(current.Value, w); err != nil {
-					return err
-				}
-				current = current.Next
+		for _, entry := range table.Entries() {
+			if err := s.writeStringBinary(entry.Key, w); err != nil {
+				return err
+			}
+			if err := s.writeStringBinary(entry.Value, w); err != nil {
+				return err
 			}
 		}
 	}
 	return nil
 }
 
+func (s *Serializer) SerializeHashLayout(table *HashTable, w io.Writer, format SerializationFormat) error {
+	if table == nil {
+		return fmt.Errorf("hash table is nil")
+	}
+	
+	ordered := 0
+	if table.IsOrdered() {
+		ordered = 1
+	}
+	entries := table.Entries()
+	hasher := table.GetHashFunction()
+	
+	if format == TEXT {
+		var line strings.Builder
+		fmt.Fprintf(&line, "HASH_LAYOUT %s %d %s %d %s:%d", table.GetName(), table.GetCapacity(),
+			strconv.FormatFloat(table.GetLoadFactor(), 'g', -1, 64), ordered,
+			hasher.Kind(), hasher.Seed())
+		// Параметры сжатия пишутся, только если отличаются от умолчаний
+		if table.GetShrinkFactor() != defaultHashShrinkFactor {
+			fmt.Fprintf(&line, " SHRINK:%s", strconv.FormatFloat(table.GetShrinkFactor(), 'g', -1, 64))
+		}
+		if table.GetMinCapacity() != table.GetCapacity() {
+			fmt.Fprintf(&line, " MIN:%d", table.GetMinCapacity())
+		}
+		if table.GetEngine() != CHAINING_ENGINE {
+			fmt.Fprintf(&line, " ENGINE:%s", table.GetEngine())
+		}
+		fmt.Fprintf(&line, " %d", len(entries))
+		for _, entry := range entries {
+			line.WriteString(" " + entry.Key + " " + entry.Value)
+		}
+		line.WriteString("\n")
+		_, err := io.WriteString(w, line.String())
+		return err
+	} else {
+		if err := s.writeStringBinary("HASH_LAYOUT", w); err != nil {
+			return err
+		}
+		if err := s.writeStringBinary(table.GetName(), w); err != nil {
+			return err
+		}
+		if err := s.writeIntBinary(table.GetCapacity(), w); err != nil {
+			return err
+		}
+		if err := s.writeFloatBinary(table.GetLoadFactor(), w); err != nil {
+			return err
+		}
+		if err := s.writeIntBinary(ordered, w); err != nil {
+			return err
+		}
+		if err := s.writeStringBinary(hasher.Kind().String(), w); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, hasher.Seed()); err != nil {
+			return err
+		}
+		if err := s.writeFloatBinary(table.GetShrinkFactor(), w); err != nil {
+			return err
+		}
+		if err := s.writeIntBinary(table.GetMinCapacity(), w); err != nil {
+			return err
+		}
+		if err := s.writeStringBinary(table.GetEngine().String(), w); err != nil {
+			return err
+		}
+		if err := s.writeIntBinary(len(entries), w); err != nil {
+			return err
+		}
+		
+		for _, entry := range entries {
+			if err := s.writeStringBinary(entry.Key, w); err != nil {
+				return err
+			}
+			if err := s.writeStringBinary(entry.Value, w); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SerializeHashExpiry записывает сроки жизни ключей таблицы отдельной
+// записью HASH_TTL: имя, число ключей и пары ключ - момент удаления в
+// миллисекундах Unix. Моменты абсолютные, поэтому после загрузки ключи
+// истекают тогда же, когда истекли бы без сохранения. Таблица без сроков
+// жизни ничего не записывает. Сроки жизни сохраняются только в текстовом
+// формате: бинарная база данных пока не сохраняется и не загружается.
+func (s *Serializer) SerializeHashExpiry(table *HashTable, w io.Writer, format SerializationFormat) error {
+	if table == nil {
+		return fmt.Errorf("hash table is nil")
+	}
+	if format != TEXT {
+		return fmt.Errorf("hash expiry supports only text format")
+	}
+
+	var keys []string
+	var deadlines []int64
+	for _, entry := range table.Entries() {
+		if deadline, ok := table.GetDeadline(entry.Key); ok {
+			keys = append(keys, entry.Key)
+			deadlines = append(deadlines, deadline.UnixMilli())
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	var line strings.Builder
+	fmt.Fprintf(&line, "HASH_TTL %s %d", table.GetName(), len(keys))
+	for i, key := range keys {
+		fmt.Fprintf(&line, " %s %d", key, deadlines[i])
+	}
+	line.WriteString("\n")
+	_, err := io.WriteString(w, line.String())
+	return err
+}
+
 func (s *Serializer) SerializeDatabase(db *Database, filename string, format SerializationFormat) error {
 	var fileType string
 	if format == BINARY {
@@ -380,6 +786,12 @@ func (s *Serializer) SerializeDatabase(db *Database, filename string, format Ser
 		}
 	}
 	
+	for _, set := range db.OrderedSets {
+		if set != nil {
+			fmt.Printf("  - Дерево (%s): %s\n", set.GetEngine(), set.GetName())
+		}
+	}
+	
 	for _, table := range db.HashTables {
 		if table != nil {
 			fmt.Printf("  - Хеш-таблица: %s\n", table.GetName())
@@ -2,8 +2,12 @@ package dbmsgo
 
 import (
 	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -124,6 +128,27 @@ func TestSerializer_TreeTextFormat(t *testing.T) {
 	assert.Contains(t, output, "15")
 }
 
+func TestSerializer_TreeShapeFormat(t *testing.T) {
+	serializer := NewSerializer()
+	tree := NewAVLTree("test_tree")
+	tree.Insert(10)
+	tree.Insert(5)
+	tree.Insert(15)
+
+	var buf bytes.Buffer
+	err := serializer.SerializeTreeShape(tree, &buf, TEXT)
+	assert.NoError(t, err)
+	assert.Equal(t, "TREE_SHAPE test_tree 3 10 2 5 1 15 1\n", buf.String())
+
+	buf.Reset()
+	err = serializer.SerializeTreeShape(tree, &buf, BINARY)
+	assert.NoError(t, err)
+	assert.Greater(t, buf.Len(), 0)
+
+	err = serializer.SerializeTreeShape(nil, &buf, TEXT)
+	assert.Error(t, err)
+}
+
 func TestSerializer_HashTableTextFormat(t *testing.T) {
 	serializer := NewSerializer()
 	hash := NewHashTable("test_hash")
@@ -281,3 +306,146 @@ func TestSerializer_WriteReadIntBinary(t *testing.T) {
 		assert.Equal(t, testInt, result)
 	}
 }
+
+func TestSerializer_WriteInt64Binary(t *testing.T) {
+	serializer := NewSerializer()
+
+	for _, testInt := range []int64{0, -1, math.MaxInt32 + 1, math.MinInt64, math.MaxInt64} {
+		var buf bytes.Buffer
+
+		err := serializer.writeInt64Binary(testInt, &buf)
+		assert.NoError(t, err)
+		assert.Equal(t, 8, buf.Len())
+
+		var result int64
+		assert.NoError(t, binary.Read(&buf, binary.LittleEndian, &result))
+		assert.Equal(t, testInt, result)
+	}
+
+	// Счётчики больше int32 не обрезаются молча
+	var buf bytes.Buffer
+	assert.Error(t, serializer.writeIntBinary(math.MaxInt32+1, &buf))
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestSerializer_TreeLargeValues(t *testing.T) {
+	serializer := NewSerializer()
+	tree := NewAVLTree("test_tree")
+	tree.Insert(math.MaxInt64)
+	tree.Insert(-1)
+
+	var buf bytes.Buffer
+	assert.NoError(t, serializer.SerializeTree(tree, &buf, BINARY))
+
+	// Тег, имя, количество и два значения по 8 байт
+	_, err := serializer.readStringBinary(&buf)
+	assert.NoError(t, err)
+	_, err = serializer.readStringBinary(&buf)
+	assert.NoError(t, err)
+	count, err := serializer.readIntBinary(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	values := make([]int64, 2)
+	assert.NoError(t, binary.Read(&buf, binary.LittleEndian, values))
+	assert.Equal(t, []int64{-1, math.MaxInt64}, values)
+}
+
+func TestSerializer_BigIntTree(t *testing.T) {
+	serializer := NewSerializer()
+	tree := NewAVLTreeWithMode("big_tree", BIGINT_KEYS)
+	tree.InsertKey("100000000000000000000")
+	tree.InsertKey("-3")
+
+	var buf bytes.Buffer
+	assert.NoError(t, serializer.SerializeTree(tree, &buf, TEXT))
+	assert.Equal(t, "TREE_BIG big_tree 2 -3 100000000000000000000\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, serializer.SerializeTree(tree, &buf, BINARY))
+	assert.Greater(t, buf.Len(), 0)
+
+	assert.Error(t, serializer.SerializeTreeShape(tree, &buf, TEXT))
+}
+
+func TestSerializer_OrderedMapTree(t *testing.T) {
+	serializer := NewSerializer()
+	tree := NewOrderedMap("dict", NUMERIC_ORDER)
+	tree.Put("10", "ten")
+	tree.Put("2", "two")
+
+	var buf bytes.Buffer
+	assert.NoError(t, serializer.SerializeTree(tree, &buf, TEXT))
+	assert.Equal(t, "TREE_MAP dict NUM 2 2 two 10 ten\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, serializer.SerializeTree(tree, &buf, BINARY))
+	tag, _ := serializer.readStringBinary(&buf)
+	assert.Equal(t, "TREE_MAP", tag)
+}
+
+func TestSerializer_MultisetTree(t *testing.T) {
+	serializer := NewSerializer()
+	tree := NewMultisetTree("measurements", INT_KEYS)
+	tree.Insert(4)
+	tree.Insert(2)
+	tree.Insert(4)
+
+	var buf bytes.Buffer
+	assert.NoError(t, serializer.SerializeTree(tree, &buf, TEXT))
+	assert.Equal(t, "TREE_MULTI measurements INT 2 2 1 4 2\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, serializer.SerializeTree(tree, &buf, BINARY))
+	assert.Greater(t, buf.Len(), 0)
+	assert.Error(t, serializer.SerializeTreeShape(tree, &buf, TEXT))
+}
+
+
+func TestSerializer_OrderedSetEngines(t *testing.T) {
+	serializer := NewSerializer()
+	set := NewOrderedSet("events", SKIPLIST_ENGINE)
+	set.Insert(9)
+	set.Insert(3)
+
+	var buf bytes.Buffer
+	assert.NoError(t, serializer.SerializeOrderedSet(set, &buf, TEXT))
+	assert.Equal(t, "TREE_ENGINE events SKIPLIST 2 3 9\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, serializer.SerializeOrderedSet(set, &buf, BINARY))
+	assert.Greater(t, buf.Len(), 0)
+
+	// Дерево AVL сохраняется прежней записью TREE
+	buf.Reset()
+	assert.NoError(t, serializer.SerializeOrderedSet(NewOrderedSet("avl", AVL_ENGINE), &buf, TEXT))
+	assert.Equal(t, "TREE avl 0\n", buf.String())
+	assert.Error(t, serializer.SerializeOrderedSet(nil, &buf, TEXT))
+}
+
+func TestSerializer_HashExpiry(t *testing.T) {
+	serializer := NewSerializer()
+	clock := newFakeClock()
+	table := newTestHashTable(t, "sessions", HashTableOptions{PreserveOrder: true})
+	table.SetClock(clock.Now)
+	table.Insert("a", "1")
+	table.Insert("b", "2")
+	table.Insert("c", "3")
+
+	// Без сроков жизни запись не нужна
+	var buf bytes.Buffer
+	assert.NoError(t, serializer.SerializeHashExpiry(table, &buf, TEXT))
+	assert.Empty(t, buf.String())
+
+	table.Expire("a", time.Second)
+	table.Expire("c", time.Minute)
+	deadline := clock.Now().Add(time.Minute).UnixMilli()
+	clock.Advance(time.Second)
+
+	assert.NoError(t, serializer.SerializeHashExpiry(table, &buf, TEXT))
+	assert.Equal(t, fmt.Sprintf("HASH_TTL sessions 1 c %d\n", deadline), buf.String())
+
+	buf.Reset()
+	assert.Error(t, serializer.SerializeHashExpiry(table, &buf, BINARY))
+	assert.Zero(t, buf.Len())
+	assert.Error(t, serializer.SerializeHashExpiry(nil, &buf, TEXT))
+}
@@ -11,6 +11,7 @@ type SinglyLinkedList struct {
 	name string
 	head *SLLNode
 	tail *SLLNode
+	size int
 }
 
 func NewSinglyLinkedList(name string) *SinglyLinkedList {
@@ -27,6 +28,7 @@ func (s *SinglyLinkedList) PushFront(value string) {
 	if s.tail == nil {
 		s.tail = newNode
 	}
+	s.size++
 }
 
 func (s *SinglyLinkedList) PushBack(value string) {
@@ -38,6 +40,7 @@ func (s *SinglyLinkedList) PushBack(value string) {
 		s.tail.Next = newNode
 		s.tail = newNode
 	}
+	s.size++
 }
 
 func (s *SinglyLinkedList) InsertBefore(target, value string) {
@@ -58,6 +61,7 @@ func (s *SinglyLinkedList) InsertBefore(target, value string) {
 	if current.Next != nil {
 		newNode := &SLLNode{Data: value, Next: current.Next}
 		current.Next = newNode
+		s.size++
 	}
 }
 
@@ -73,6 +77,7 @@ func (s *SinglyLinkedList) InsertAfter(target, value string) {
 		if current == s.tail {
 			s.tail = newNode
 		}
+		s.size++
 	}
 }
 
@@ -85,6 +90,7 @@ func (s *SinglyLinkedList) DeleteFront() {
 	if s.head == nil {
 		s.tail = nil
 	}
+	s.size--
 }
 
 func (s *SinglyLinkedList) DeleteBack() {
@@ -95,6 +101,7 @@ func (s *SinglyLinkedList) DeleteBack() {
 	if s.head.Next == nil {
 		s.head = nil
 		s.tail = nil
+		s.size = 0
 		return
 	}
 	
@@ -105,29 +112,57 @@ func (s *SinglyLinkedList) DeleteBack() {
 	
 	current.Next = nil
 	s.tail = current
+	s.size--
 }
 
 func (s *SinglyLinkedList) DeleteByValue(value string) {
-	// Удаляем все вхождения с начала
-	for s.head != nil && s.head.Data == value {
-		s.DeleteFront()
-	}
-	
-	if s.head == nil {
-		return
+	s.RemoveValue(value, 0)
+}
+
+// RemoveValue удаляет элементы, равные value, как LREM в Redis: count > 0 -
+// первые count совпадений с начала, count < 0 - последние |count|,
+// count == 0 - все. Возвращает число удалённых элементов.
+func (s *SinglyLinkedList) RemoveValue(value string, count int) int {
+	// Список односвязный, поэтому с конца удаляем, пропустив лишние
+	// совпадения с начала
+	skip := 0
+	if count < 0 {
+		skip = s.countValue(value) + count
+		count = 0
 	}
-	
-	current := s.head
-	for current.Next != nil {
-		if current.Next.Data == value {
-			current.Next = current.Next.Next
-			if current.Next == nil {
-				s.tail = current
+
+	removed := 0
+	var prev *SLLNode
+	for current := s.head; current != nil && (count == 0 || removed < count); current = current.Next {
+		if current.Data != value || skip > 0 {
+			if current.Data == value {
+				skip--
 			}
+			prev = current
+			continue
+		}
+		if prev == nil {
+			s.head = current.Next
 		} else {
-			current = current.Next
+			prev.Next = current.Next
 		}
+		if current == s.tail {
+			s.tail = prev
+		}
+		s.size--
+		removed++
 	}
+	return removed
+}
+
+func (s *SinglyLinkedList) countValue(value string) int {
+	count := 0
+	for current := s.head; current != nil; current = current.Next {
+		if current.Data == value {
+			count++
+		}
+	}
+	return count
 }
 
 func (s *SinglyLinkedList) FindByValue(value string) *SLLNode {
@@ -141,6 +176,273 @@ func (s *SinglyLinkedList) FindByValue(value string) *SLLNode {
 	return nil
 }
 
+// IndexOf возвращает номер первого элемента, равного value, или -1
+func (s *SinglyLinkedList) IndexOf(value string) int {
+	index := 0
+	for current := s.head; current != nil; current = current.Next {
+		if current.Data == value {
+			return index
+		}
+		index++
+	}
+	return -1
+}
+
+// LastIndexOf возвращает номер последнего элемента, равного value, или -1
+func (s *SinglyLinkedList) LastIndexOf(value string) int {
+	found, index := -1, 0
+	for current := s.head; current != nil; current = current.Next {
+		if current.Data == value {
+			found = index
+		}
+		index++
+	}
+	return found
+}
+
+// nodeAt возвращает узел с номером index или nil
+func (s *SinglyLinkedList) nodeAt(index int) *SLLNode {
+	if index < 0 || index >= s.size {
+		return nil
+	}
+	if index == s.size-1 {
+		return s.tail
+	}
+	current := s.head
+	for i := 0; i < index; i++ {
+		current = current.Next
+	}
+	return current
+}
+
+func (s *SinglyLinkedList) Length() int {
+	return s.size
+}
+
+func (s *SinglyLinkedList) Get(index int) (string, error) {
+	node := s.nodeAt(index)
+	if node == nil {
+		return "", fmt.Errorf("index out of range")
+	}
+	return node.Data, nil
+}
+
+func (s *SinglyLinkedList) Set(index int, value string) error {
+	node := s.nodeAt(index)
+	if node == nil {
+		return fmt.Errorf("index out of range")
+	}
+	node.Data = value
+	return nil
+}
+
+// InsertAt вставляет значение так, чтобы оно получило номер index;
+// index == Length() добавляет в конец
+func (s *SinglyLinkedList) InsertAt(index int, value string) error {
+	if index < 0 || index > s.size {
+		return fmt.Errorf("index out of range")
+	}
+	if index == 0 {
+		s.PushFront(value)
+		return nil
+	}
+	if index == s.size {
+		s.PushBack(value)
+		return nil
+	}
+
+	prev := s.nodeAt(index - 1)
+	prev.Next = &SLLNode{Data: value, Next: prev.Next}
+	s.size++
+	return nil
+}
+
+// RemoveAt удаляет элемент с номером index и возвращает его значение
+func (s *SinglyLinkedList) RemoveAt(index int) (string, error) {
+	if index < 0 || index >= s.size {
+		return "", fmt.Errorf("index out of range")
+	}
+	if index == 0 {
+		value := s.head.Data
+		s.DeleteFront()
+		return value, nil
+	}
+
+	prev := s.nodeAt(index - 1)
+	removed := prev.Next
+	prev.Next = removed.Next
+	if removed == s.tail {
+		s.tail = prev
+	}
+	s.size--
+	return removed.Data, nil
+}
+
+// Range возвращает элементы с start по stop включительно; отрицательные
+// индексы отсчитываются от конца
+func (s *SinglyLinkedList) Range(start, stop int) []string {
+	from, to := normalizeRange(start, stop, s.size)
+	result := make([]string, 0, to-from)
+	if from == to {
+		return result
+	}
+	for current := s.nodeAt(from); len(result) < to-from; current = current.Next {
+		result = append(result, current.Data)
+	}
+	return result
+}
+
+// mergeSLLNodes сливает два отсортированных списка узлов. При равенстве
+// первым идёт узел из left, поэтому сортировка устойчива.
+func mergeSLLNodes(left, right *SLLNode, compare Comparator) *SLLNode {
+	dummy := &SLLNode{}
+	tail := dummy
+	for left != nil && right != nil {
+		if compare(left.Data, right.Data) <= 0 {
+			tail.Next = left
+			left = left.Next
+		} else {
+			tail.Next = right
+			right = right.Next
+		}
+		tail = tail.Next
+	}
+	if left != nil {
+		tail.Next = left
+	} else {
+		tail.Next = right
+	}
+	return dummy.Next
+}
+
+// mergeSortSLLNodes сортирует length узлов начиная с head
+func mergeSortSLLNodes(head *SLLNode, length int, compare Comparator) *SLLNode {
+	if length < 2 {
+		return head
+	}
+	middle := head
+	for i := 1; i < length/2; i++ {
+		middle = middle.Next
+	}
+	right := middle.Next
+	middle.Next = nil
+	return mergeSLLNodes(
+		mergeSortSLLNodes(head, length/2, compare),
+		mergeSortSLLNodes(right, length-length/2, compare),
+		compare,
+	)
+}
+
+// Sort устойчиво сортирует список слиянием без выделения новых узлов
+func (s *SinglyLinkedList) Sort(compare Comparator) {
+	s.head = mergeSortSLLNodes(s.head, s.size, compare)
+	s.tail = s.head
+	for s.tail != nil && s.tail.Next != nil {
+		s.tail = s.tail.Next
+	}
+}
+
+func (s *SinglyLinkedList) Reverse() {
+	var prev *SLLNode
+	current := s.head
+	s.tail = s.head
+	for current != nil {
+		next := current.Next
+		current.Next = prev
+		prev = current
+		current = next
+	}
+	s.head = prev
+}
+
+// Unique удаляет повторы: подряд идущие или, если all, все кроме первого
+// вхождения. Возвращает число удалённых элементов.
+func (s *SinglyLinkedList) Unique(all bool) int {
+	if s.head == nil {
+		return 0
+	}
+	var seen map[string]bool
+	if all {
+		seen = map[string]bool{s.head.Data: true}
+	}
+	removed := 0
+	prev := s.head
+	for current := s.head.Next; current != nil; current = current.Next {
+		duplicate := current.Data == prev.Data
+		if all {
+			duplicate = seen[current.Data]
+			seen[current.Data] = true
+		}
+		if duplicate {
+			prev.Next = current.Next
+			removed++
+		} else {
+			prev = current
+		}
+	}
+	s.tail = prev
+	s.size -= removed
+	return removed
+}
+
+// Splice переносит элементы с start по stop включительно в список dst так,
+// чтобы первый из них получил номер index. Отрицательные start и stop
+// отсчитываются от конца. Если dst - тот же список, index считается после
+// изъятия диапазона. Возвращает число перенесённых элементов.
+func (s *SinglyLinkedList) Splice(start, stop int, dst *SinglyLinkedList, index int) (int, error) {
+	from, to := normalizeRange(start, stop, s.size)
+	count := to - from
+	limit := dst.size
+	if dst == s {
+		limit -= count
+	}
+	if index < 0 || index > limit {
+		return 0, fmt.Errorf("index out of range")
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	// Вырезаем узлы [from, to)
+	var prev *SLLNode
+	if from > 0 {
+		prev = s.nodeAt(from - 1)
+	}
+	first := s.head
+	if prev != nil {
+		first = prev.Next
+	}
+	last := first
+	for i := 1; i < count; i++ {
+		last = last.Next
+	}
+	if prev != nil {
+		prev.Next = last.Next
+	} else {
+		s.head = last.Next
+	}
+	if last == s.tail {
+		s.tail = prev
+	}
+	last.Next = nil
+	s.size -= count
+
+	// Вставляем перед узлом с номером index
+	if index == 0 {
+		last.Next = dst.head
+		dst.head = first
+	} else {
+		before := dst.nodeAt(index - 1)
+		last.Next = before.Next
+		before.Next = first
+	}
+	if last.Next == nil {
+		dst.tail = last
+	}
+	dst.size += count
+	return count, nil
+}
+
 func (s *SinglyLinkedList) Print() {
 	fmt.Printf("Односвязный список '%s': ", s.name)
 	current := s.head
@@ -173,4 +475,5 @@ func (s *SinglyLinkedList) GetTail() *SLLNode {
 func (s *SinglyLinkedList) Cleanup() {
 	s.head = nil
 	s.tail = nil
+	s.size = 0
 }
@@ -108,4 +108,203 @@ func TestSinglyLinkedList(t *testing.T) {
 		assert.Nil(t, sll.GetHead())
 		assert.Nil(t, sll.GetTail())
 	})
+
+	t.Run("Length", func(t *testing.T) {
+		sll := NewSinglyLinkedList("test_sll")
+		assert.Equal(t, 0, sll.Length())
+
+		sll.PushBack("a")
+		sll.PushFront("b")
+		sll.PushBack("a")
+		sll.InsertAfter("b", "c")
+		sll.InsertBefore("missing", "x")
+		assert.Equal(t, 4, sll.Length())
+
+		sll.DeleteByValue("a")
+		assert.Equal(t, 2, sll.Length())
+		sll.DeleteBack()
+		sll.DeleteFront()
+		sll.DeleteFront()
+		assert.Equal(t, 0, sll.Length())
+
+		sll.PushBack("a")
+		sll.Cleanup()
+		assert.Equal(t, 0, sll.Length())
+	})
+
+	t.Run("IndexOperations", func(t *testing.T) {
+		sll := NewSinglyLinkedList("test_sll")
+		assert.NoError(t, sll.InsertAt(0, "b"))
+		assert.NoError(t, sll.InsertAt(0, "a"))
+		assert.NoError(t, sll.InsertAt(2, "d"))
+		assert.NoError(t, sll.InsertAt(2, "c"))
+		assert.Error(t, sll.InsertAt(5, "x"))
+		assert.Error(t, sll.InsertAt(-1, "x"))
+
+		for i, expected := range []string{"a", "b", "c", "d"} {
+			value, err := sll.Get(i)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, value)
+		}
+		_, err := sll.Get(4)
+		assert.Error(t, err)
+
+		assert.NoError(t, sll.Set(1, "B"))
+		assert.Error(t, sll.Set(4, "x"))
+
+		value, err := sll.RemoveAt(3)
+		assert.NoError(t, err)
+		assert.Equal(t, "d", value)
+		assert.Equal(t, "c", sll.GetTail().Data)
+
+		value, err = sll.RemoveAt(0)
+		assert.NoError(t, err)
+		assert.Equal(t, "a", value)
+		assert.Equal(t, "B", sll.GetHead().Data)
+
+		_, err = sll.RemoveAt(2)
+		assert.Error(t, err)
+		assert.Equal(t, 2, sll.Length())
+
+		// После удаления хвоста вставка в конец идёт за новым хвостом
+		assert.NoError(t, sll.InsertAt(2, "e"))
+		assert.Equal(t, "e", sll.GetTail().Data)
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		sll := NewSinglyLinkedList("test_sll")
+		for _, value := range []string{"a", "b", "c", "d"} {
+			sll.PushBack(value)
+		}
+
+		assert.Equal(t, []string{"a", "b", "c", "d"}, sll.Range(0, -1))
+		assert.Equal(t, []string{"b", "c"}, sll.Range(1, -2))
+		assert.Equal(t, []string{"d"}, sll.Range(-1, 5))
+		assert.Empty(t, sll.Range(-1, 0))
+		assert.Empty(t, NewSinglyLinkedList("empty").Range(0, -1))
+	})
+
+	t.Run("Sort", func(t *testing.T) {
+		sll := NewSinglyLinkedList("test_sll")
+		for _, value := range []string{"10", "9", "1.0", "x", "2", "1"} {
+			sll.PushBack(value)
+		}
+
+		sll.Sort(NumericOrder)
+		// 1.0 и 1 равны как числа и сохраняют исходный порядок
+		assert.Equal(t, []string{"1.0", "1", "2", "9", "10", "x"}, sll.Range(0, -1))
+		assert.Equal(t, "x", sll.GetTail().Data)
+
+		sll.Sort(LexicographicOrder)
+		assert.Equal(t, []string{"1", "1.0", "10", "2", "9", "x"}, sll.Range(0, -1))
+		assert.Equal(t, 6, sll.Length())
+
+		empty := NewSinglyLinkedList("empty")
+		empty.Sort(LexicographicOrder)
+		assert.True(t, empty.IsEmpty())
+	})
+
+	t.Run("Reverse", func(t *testing.T) {
+		sll := NewSinglyLinkedList("test_sll")
+		for _, value := range []string{"a", "b", "c"} {
+			sll.PushBack(value)
+		}
+
+		sll.Reverse()
+		assert.Equal(t, []string{"c", "b", "a"}, sll.Range(0, -1))
+		assert.Equal(t, "a", sll.GetTail().Data)
+		sll.PushBack("z")
+		assert.Equal(t, []string{"c", "b", "a", "z"}, sll.Range(0, -1))
+	})
+
+	t.Run("Unique", func(t *testing.T) {
+		sll := NewSinglyLinkedList("test_sll")
+		for _, value := range []string{"a", "a", "b", "a", "b", "b"} {
+			sll.PushBack(value)
+		}
+
+		assert.Equal(t, 2, sll.Unique(false))
+		assert.Equal(t, []string{"a", "b", "a", "b"}, sll.Range(0, -1))
+		assert.Equal(t, 2, sll.Unique(true))
+		assert.Equal(t, []string{"a", "b"}, sll.Range(0, -1))
+		assert.Equal(t, "b", sll.GetTail().Data)
+		assert.Equal(t, 2, sll.Length())
+		assert.Equal(t, 0, NewSinglyLinkedList("empty").Unique(true))
+	})
+
+	t.Run("Splice", func(t *testing.T) {
+		src := NewSinglyLinkedList("src")
+		dst := NewSinglyLinkedList("dst")
+		for _, value := range []string{"a", "b", "c", "d"} {
+			src.PushBack(value)
+		}
+		dst.PushBack("x")
+		dst.PushBack("y")
+
+		moved, err := src.Splice(2, -1, dst, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, moved)
+		assert.Equal(t, []string{"a", "b"}, src.Range(0, -1))
+		assert.Equal(t, "b", src.GetTail().Data)
+		assert.Equal(t, []string{"x", "c", "d", "y"}, dst.Range(0, -1))
+
+		moved, err = src.Splice(0, 0, dst, 4)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, moved)
+		assert.Equal(t, "a", dst.GetTail().Data)
+		assert.Equal(t, 5, dst.Length())
+		assert.Equal(t, 1, src.Length())
+
+		_, err = src.Splice(0, 0, dst, 6)
+		assert.Error(t, err)
+		assert.Equal(t, 1, src.Length())
+
+		// Перенос внутри одного списка
+		moved, err = dst.Splice(0, 1, dst, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, moved)
+		assert.Equal(t, []string{"d", "y", "a", "x", "c"}, dst.Range(0, -1))
+		assert.Equal(t, "c", dst.GetTail().Data)
+	})
+	t.Run("RemoveValue", func(t *testing.T) {
+		fill := func() *SinglyLinkedList {
+			sll := NewSinglyLinkedList("test_sll")
+			for _, value := range []string{"a", "b", "a", "c", "a"} {
+				sll.PushBack(value)
+			}
+			return sll
+		}
+
+		sll := fill()
+		assert.Equal(t, 2, sll.RemoveValue("a", 2))
+		assert.Equal(t, []string{"b", "c", "a"}, sll.Range(0, -1))
+
+		sll = fill()
+		assert.Equal(t, 2, sll.RemoveValue("a", -2))
+		assert.Equal(t, []string{"a", "b", "c"}, sll.Range(0, -1))
+		assert.Equal(t, "c", sll.GetTail().Data)
+
+		sll = fill()
+		assert.Equal(t, 3, sll.RemoveValue("a", -10))
+		assert.Equal(t, []string{"b", "c"}, sll.Range(0, -1))
+		assert.Equal(t, 0, sll.RemoveValue("missing", 0))
+		assert.Equal(t, 2, sll.Length())
+
+		sll = fill()
+		assert.Equal(t, 3, sll.RemoveValue("a", 0))
+		assert.Equal(t, 2, sll.Length())
+	})
+
+	t.Run("IndexOf", func(t *testing.T) {
+		sll := NewSinglyLinkedList("test_sll")
+		for _, value := range []string{"a", "b", "a"} {
+			sll.PushBack(value)
+		}
+
+		assert.Equal(t, 0, sll.IndexOf("a"))
+		assert.Equal(t, 2, sll.LastIndexOf("a"))
+		assert.Equal(t, 1, sll.LastIndexOf("b"))
+		assert.Equal(t, -1, sll.IndexOf("z"))
+		assert.Equal(t, -1, sll.LastIndexOf("z"))
+	})
 }
@@ -0,0 +1,165 @@
+package dbmsgo
+
+import "math/rand"
+
+const (
+	skipListMaxLevel = 32
+	skipListP        = 0.25 // вероятность подняться на следующий уровень
+)
+
+type SkipListNode struct {
+	Data int64
+	next []*SkipListNode
+}
+
+// SkipList - список с пропусками: вероятностная структура, в которой
+// вставка и удаление не требуют перебалансировки.
+type SkipList struct {
+	name  string
+	head  *SkipListNode
+	level int
+	size  int
+	rng   *rand.Rand
+}
+
+func NewSkipList(name string) *SkipList {
+	return NewSkipListWithSeed(name, rand.Int63())
+}
+
+// NewSkipListWithSeed создаёт список с детерминированным выбором уровней
+func NewSkipListWithSeed(name string, seed int64) *SkipList {
+	return &SkipList{
+		name:  name,
+		head:  &SkipListNode{next: make([]*SkipListNode, skipListMaxLevel)},
+		level: 1,
+		rng:   rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (s *SkipList) randomLevel() int {
+	level := 1
+	for level < skipListMaxLevel && s.rng.Float64() < skipListP {
+		level++
+	}
+	return level
+}
+
+// findPredecessors заполняет update последними узлами каждого уровня с
+// ключом меньше value и возвращает следующий за ними узел нижнего уровня
+func (s *SkipList) findPredecessors(value int64, update []*SkipListNode) *SkipListNode {
+	current := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for current.next[i] != nil && current.next[i].Data < value {
+			current = current.next[i]
+		}
+		if update != nil {
+			update[i] = current
+		}
+	}
+	return current.next[0]
+}
+
+func (s *SkipList) Insert(value int64) {
+	update := make([]*SkipListNode, skipListMaxLevel)
+	next := s.findPredecessors(value, update)
+	if next != nil && next.Data == value {
+		return
+	}
+
+	level := s.randomLevel()
+	for i := s.level; i < level; i++ {
+		update[i] = s.head
+	}
+	if level > s.level {
+		s.level = level
+	}
+
+	node := &SkipListNode{Data: value, next: make([]*SkipListNode, level)}
+	for i := 0; i < level; i++ {
+		node.next[i] = update[i].next[i]
+		update[i].next[i] = node
+	}
+	s.size++
+}
+
+func (s *SkipList) Remove(value int64) {
+	update := make([]*SkipListNode, skipListMaxLevel)
+	node := s.findPredecessors(value, update)
+	if node == nil || node.Data != value {
+		return
+	}
+
+	for i := 0; i < len(node.next); i++ {
+		update[i].next[i] = node.next[i]
+	}
+	for s.level > 1 && s.head.next[s.level-1] == nil {
+		s.level--
+	}
+	s.size--
+}
+
+func (s *SkipList) Contains(value int64) bool {
+	node := s.findPredecessors(value, nil)
+	return node != nil && node.Data == value
+}
+
+func (s *SkipList) SaveTree() []int64 {
+	result := make([]int64, 0, s.size)
+	for node := s.head.next[0]; node != nil; node = node.next[0] {
+		result = append(result, node.Data)
+	}
+	return result
+}
+
+// BuildFromSorted заменяет содержимое списка значениями из строго
+// возрастающей последовательности за O(n)
+func (s *SkipList) BuildFromSorted(values []int64) error {
+	if err := checkSortedValues(values); err != nil {
+		return err
+	}
+
+	s.Cleanup()
+	tails := make([]*SkipListNode, skipListMaxLevel)
+	for i := range tails {
+		tails[i] = s.head
+	}
+	for _, value := range values {
+		level := s.randomLevel()
+		node := &SkipListNode{Data: value, next: make([]*SkipListNode, level)}
+		for i := 0; i < level; i++ {
+			tails[i].next[i] = node
+			tails[i] = node
+		}
+		if level > s.level {
+			s.level = level
+		}
+	}
+	s.size = len(values)
+	return nil
+}
+
+func (s *SkipList) GetName() string {
+	return s.name
+}
+
+func (s *SkipList) GetEngine() TreeEngine {
+	return SKIPLIST_ENGINE
+}
+
+func (s *SkipList) Size() int {
+	return s.size
+}
+
+func (s *SkipList) IsEmpty() bool {
+	return s.size == 0
+}
+
+func (s *SkipList) PrintInOrder() {
+	printOrderedSet(s)
+}
+
+func (s *SkipList) Cleanup() {
+	s.head = &SkipListNode{next: make([]*SkipListNode, skipListMaxLevel)}
+	s.level = 1
+	s.size = 0
+}
@@ -0,0 +1,75 @@
+package dbmsgo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipList(t *testing.T) {
+	t.Run("LevelsAreSorted", func(t *testing.T) {
+		list := NewSkipListWithSeed("skip", 7)
+		rng := rand.New(rand.NewSource(7))
+		for i := 0; i < 5000; i++ {
+			value := rng.Int63n(1000)
+			if rng.Intn(3) == 0 {
+				list.Remove(value)
+			} else {
+				list.Insert(value)
+			}
+		}
+		checkSkipListInvariants(t, list)
+	})
+
+	t.Run("BuildFromSorted", func(t *testing.T) {
+		list := NewSkipListWithSeed("skip", 8)
+		values := make([]int64, 500)
+		for i := range values {
+			values[i] = int64(i * 3)
+		}
+		assert.NoError(t, list.BuildFromSorted(values))
+		checkSkipListInvariants(t, list)
+		assert.True(t, list.Contains(300))
+		assert.False(t, list.Contains(301))
+	})
+
+	t.Run("LevelShrinksAfterRemoval", func(t *testing.T) {
+		list := NewSkipListWithSeed("skip", 9)
+		for i := int64(0); i < 1000; i++ {
+			list.Insert(i)
+		}
+		assert.Greater(t, list.level, 1)
+		for i := int64(0); i < 1000; i++ {
+			list.Remove(i)
+		}
+		assert.Equal(t, 1, list.level)
+		assert.True(t, list.IsEmpty())
+	})
+}
+
+// checkSkipListInvariants проверяет, что каждый уровень упорядочен и
+// является подпоследовательностью нижнего уровня
+func checkSkipListInvariants(t *testing.T, list *SkipList) {
+	count := 0
+	for node := list.head.next[0]; node != nil; node = node.next[0] {
+		count++
+	}
+	assert.Equal(t, list.Size(), count)
+
+	for level := 1; level < list.level; level++ {
+		lower := list.head.next[level-1]
+		for node := list.head.next[level]; node != nil; node = node.next[level] {
+			for lower != nil && lower != node {
+				lower = lower.next[level-1]
+			}
+			assert.NotNil(t, lower)
+			if node.next[level] != nil {
+				assert.Less(t, node.Data, node.next[level].Data)
+			}
+		}
+	}
+	for level := list.level; level < skipListMaxLevel; level++ {
+		assert.Nil(t, list.head.next[level])
+	}
+}
@@ -47,6 +47,21 @@ func (s *Stack) Peek() (string, error) {
 	return s.top.Data, nil
 }
 
+// Range возвращает элементы с start по stop включительно, считая от
+// вершины; отрицательные индексы отсчитываются от дна
+func (s *Stack) Range(start, stop int) []string {
+	from, to := normalizeRange(start, stop, s.size)
+	result := make([]string, 0, to-from)
+	current := s.top
+	for i := 0; i < to; i++ {
+		if i >= from {
+			result = append(result, current.Data)
+		}
+		current = current.Next
+	}
+	return result
+}
+
 func (s *Stack) IsEmpty() bool {
 	return s.top == nil
 }
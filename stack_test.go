@@ -90,4 +90,17 @@ func TestStack(t *testing.T) {
 		assert.True(t, stack.IsEmpty())
 		assert.Equal(t, 0, stack.GetSize())
 	})
+
+	t.Run("Range", func(t *testing.T) {
+		stack := NewStack("test_stack")
+		for _, value := range []string{"a", "b", "c", "d"} {
+			stack.Push(value)
+		}
+
+		assert.Equal(t, []string{"d", "c", "b", "a"}, stack.Range(0, -1))
+		assert.Equal(t, []string{"c", "b"}, stack.Range(1, 2))
+		assert.Equal(t, []string{"a"}, stack.Range(-1, -1))
+		assert.Empty(t, stack.Range(2, 1))
+		assert.Equal(t, 4, stack.GetSize())
+	})
 }